@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Iridaceae/iridaceae/pkg/deprecatedrunner"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/docs"
+)
+
+// runDocs writes docs of the bot commands, e.g. `iridaceae-server docs -format json -out commands.json`.
+// Since the bot doesn't dispatch them through rosetta yet, they're registered to a router of
+// their own. Neither discord nor our database is connected.
+func runDocs(args []string) error {
+	fs := flag.NewFlagSet("docs", flag.ContinueOnError)
+	format := fs.String("format", "markdown", "output format, either markdown or json")
+	out := fs.String("out", "", "file to write docs to, stdout if empty")
+	hidden := fs.Bool("hidden", false, "include hidden commands")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var write func(w io.Writer, cmds []docs.Command) error
+	switch *format {
+	case "markdown", "md":
+		write = docs.WriteMarkdown
+	case "json":
+		write = docs.WriteJSON
+	default:
+		return fmt.Errorf("unknown format %q, use markdown or json", *format)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	cmds, err := deprecatedrunner.Commands()
+	if err != nil {
+		return err
+	}
+	cfg := rosetta.NewDefaultConfig()
+	cfg.UseDefaultHelpCommand = false
+	r := rosetta.NewRouter(cfg)
+	for _, c := range cmds {
+		r.Register(c)
+	}
+	return write(w, docs.Generate(r, *hidden))
+}
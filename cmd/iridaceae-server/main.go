@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/Iridaceae/iridaceae/pkg"
 	"github.com/Iridaceae/iridaceae/pkg/deprecatedrunner"
 	"github.com/Iridaceae/iridaceae/pkg/log"
@@ -8,6 +11,14 @@ import (
 
 // depart all deprecatedrunner run into internal.
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "docs" {
+		if err := runDocs(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	log.Mapper().SetAbsent("name", "iridaceae")
 	defer log.Info().Msg("--shutdown--")
 	// we will handle all flags here
@@ -19,8 +30,7 @@ func main() {
 	if err := pkg.LoadConfig(pkg.ConcertinaClientID, pkg.ConcertinaClientSecrets, pkg.ConcertinaBotToken); err != nil {
 		log.Error(err).Msg("couldn't load required envars.")
 	}
-	// setup metrics here.
-	// ....
+	// metrics are served by the bot if enabled through IRIS_METRICS_ENABLED.
 
 	log.Info().Msg("Running. Press CTRL-C to exit.")
 	// Start bot finally.
@@ -7,7 +7,7 @@ go 1.16
 require (
 	github.com/bwmarrin/discordgo v0.23.2
 	github.com/globalsign/mgo v0.0.0-20181015135952-eeefdecb41b8
-	github.com/gorilla/websocket v1.4.2 // indirect
+	github.com/gorilla/websocket v1.4.2
 	github.com/joho/godotenv v1.3.0
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/rs/zerolog v1.21.0
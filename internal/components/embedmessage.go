@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
 )
 
 // EmbedMessage wraps discordgo.Message with some more features.
@@ -48,8 +50,12 @@ func (e *EmbedMessage) Edit(content, title string, color int) *EmbedMessage {
 }
 
 // EditRaw updates current embed message with given raw embed then replace
-// internal message and error of this embed instance.
+// internal message and error of this embed instance. Embeds exceeding the limits
+// of Discord can't be split when editing and set a rosetta.LimitError.
 func (e *EmbedMessage) EditRaw(embed *discordgo.MessageEmbed) *EmbedMessage {
+	if e.err = rosetta.ValidateEmbed(embed); e.err != nil {
+		return e
+	}
 	e.Message, e.err = e.s.ChannelMessageEditEmbed(e.ChannelID, e.ID, embed)
 	return e
 }
@@ -72,9 +78,10 @@ func SendEmbed(s *discordgo.Session, channelID, content, title string, color int
 }
 
 // SendEmbedRaw passed embed to a channel and set occurred error to internal errors.
+// Embeds exceeding the limits of Discord are split by rosetta.SplitEmbed into multiple
+// messages, the returned EmbedMessage wraps the first one.
 func SendEmbedRaw(s *discordgo.Session, channelID string, embed *discordgo.MessageEmbed) *EmbedMessage {
-	msg, err := s.ChannelMessageSendEmbed(channelID, embed)
-	return &EmbedMessage{msg, s, err}
+	return sendSplit(s, channelID, &discordgo.MessageSend{Embed: embed})
 }
 
 // SendEmbedError will send given error to user about a specific command errors.
@@ -104,8 +111,27 @@ func (e *EmbedMessage) SendEmbedComplex(s *discordgo.Session, channelID, content
 }
 
 // SendEmbedComplexRaw takes given mentions and embed then streamline to given channel, returns correspondingly
-// EmbedMessage instance with internal error.
+// EmbedMessage instance with internal error. Messages exceeding the limits of Discord are split like by SendEmbedRaw.
 func SendEmbedComplexRaw(s *discordgo.Session, embed *discordgo.MessageEmbed, channelID, mention string) *EmbedMessage {
-	msg, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Content: mention, Embed: embed})
-	return &EmbedMessage{msg, s, err}
+	return sendSplit(s, channelID, &discordgo.MessageSend{Content: mention, Embed: embed})
+}
+
+// sendSplit sends given message split by rosetta.SplitMessage and wraps the first sent message.
+func sendSplit(s *discordgo.Session, channelID string, data *discordgo.MessageSend) *EmbedMessage {
+	parts, err := rosetta.SplitMessage(data)
+	if err != nil {
+		return &EmbedMessage{nil, s, err}
+	}
+	e := &EmbedMessage{s: s}
+	for _, p := range parts {
+		msg, err := s.ChannelMessageSendComplex(channelID, p)
+		if err != nil {
+			e.err = err
+			break
+		}
+		if e.Message == nil {
+			e.Message = msg
+		}
+	}
+	return e
 }
@@ -19,9 +19,11 @@ var (
 
 	// ----------------------- statistic/metrics.
 
-	StatsStartupTime      = time.Now()
-	StatsCommandsExecuted = 0
-	StatsMessageAnalyzed  = 0
+	// StatsCommandsExecuted and StatsMessageAnalyzed are updated atomically by metrics.Registry.
+
+	StatsStartupTime            = time.Now()
+	StatsCommandsExecuted int64 = 0
+	StatsMessageAnalyzed  int64 = 0
 
 	// ----------------------- errors definition.
 
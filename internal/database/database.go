@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/Iridaceae/iridaceae/pkg/log"
 
@@ -16,19 +17,25 @@ import (
 	"github.com/globalsign/mgo/bson"
 )
 
-func init() {
-	err := godotenv.Load(strings.Join([]string{pkg.GetRootDir(), "defaults.env"}, "/"))
-	if err != nil {
-		log.Error(err).Msg("Error loading env file")
-	}
+var connectOnce sync.Once
+
+// connect establishes our mongo session on first use, thus importing this package doesn't
+// require a database, e.g. to generate command docs.
+func connect() {
+	connectOnce.Do(func() {
+		err := godotenv.Load(strings.Join([]string{pkg.GetRootDir(), "defaults.env"}, "/"))
+		if err != nil {
+			log.Error(err).Msg("Error loading env file")
+		}
 
-	mUser := os.Getenv("IRIS_MONGO_USER")
-	mPass := os.Getenv("IRIS_MONGO_PASS")
-	mDBName := os.Getenv("IRIS_MONGO_DBNAME")
-	mIP := os.Getenv("IRIS_MONGO_ADDR")
-	mAddr := fmt.Sprintf(uriFmt, mUser, mPass, mIP)
+		mUser := os.Getenv("IRIS_MONGO_USER")
+		mPass := os.Getenv("IRIS_MONGO_PASS")
+		mDBName := os.Getenv("IRIS_MONGO_DBNAME")
+		mIP := os.Getenv("IRIS_MONGO_ADDR")
+		mAddr := fmt.Sprintf(uriFmt, mUser, mPass, mIP)
 
-	initMgoSessions(mDBName, mAddr)
+		initMgoSessions(mDBName, mAddr)
+	})
 }
 
 // NewUser returns a hex representation of the inputs ObjectID and insert errors into new database.
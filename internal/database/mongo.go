@@ -2,6 +2,7 @@ package database
 
 import (
 	"crypto/tls"
+	"errors"
 	"net"
 	"time"
 
@@ -13,6 +14,9 @@ import (
 )
 
 var (
+	// ErrNotConnected is returned when no connection with mongo could be established.
+	ErrNotConnected = errors.New("not connected to mongo")
+
 	// Session represents a mgo connection.
 	Session *mgo.Session
 	users   *mgo.Collection
@@ -38,7 +42,11 @@ func initMgoSessions(dbname, addr string) {
 	tlsConfig := &tls.Config{
 		MinVersion: tls.VersionTLS13,
 	}
-	dialInfo, _ := mgo.ParseURL(addr)
+	dialInfo, err := mgo.ParseURL(addr)
+	if err != nil {
+		log.Error(err).Msg("invalid mongo address")
+		return
+	}
 	dialInfo.Timeout = 5 * time.Second
 	dialInfo.DialServer = func(addr *mgo.ServerAddr) (net.Conn, error) {
 		conn, er := tls.Dial("tcp", addr.String(), tlsConfig)
@@ -48,28 +56,50 @@ func initMgoSessions(dbname, addr string) {
 	Session, err = mgo.DialWithInfo(dialInfo)
 	if err != nil {
 		log.Error(err).Msg("error while establishing connection with mongo")
+		return
 	}
 
 	users = Session.DB(dbname).C("users")
 }
 
+// getUsers returns our users collection, connecting to mongo on first use.
+func getUsers() (*mgo.Collection, error) {
+	connect()
+	if users == nil {
+		return nil, ErrNotConnected
+	}
+	return users, nil
+}
+
 func insert(user User) error {
-	return users.Insert(user)
+	c, err := getUsers()
+	if err != nil {
+		return err
+	}
+	return c.Insert(user)
 }
 
 func fetch(discordID string) (User, error) {
 	var u User
 
+	c, err := getUsers()
+	if err != nil {
+		return u, err
+	}
 	log.Debug().Msgf("fetching %s from db", discordID)
-	err := users.Find(bson.M{"discordid": discordID}).One(&u)
+	err = c.Find(bson.M{"discordid": discordID}).One(&u)
 	return u, err
 }
 
 func update(discordID, guildID, channelID string, mins int) error {
+	c, err := getUsers()
+	if err != nil {
+		return err
+	}
 	u, _ := fetch(discordID)
 
 	newMin := u.MinutesStudied + mins
 
-	err := users.Update(bson.M{"discordid": u.DiscordID}, bson.M{"$set": bson.M{"guildid": guildID, "channelid": channelID, "minutesstudied": newMin}})
+	err = c.Update(bson.M{"discordid": u.DiscordID}, bson.M{"$set": bson.M{"guildid": guildID, "channelid": channelID, "minutesstudied": newMin}})
 	return err
 }
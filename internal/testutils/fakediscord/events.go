@@ -0,0 +1,106 @@
+package fakediscord
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/rosettatest"
+)
+
+// AddGuild stores given guild with its channels and members and sends it to connected sessions.
+func (s *Server) AddGuild(g *discordgo.Guild) error {
+	if err := s.State.GuildAdd(g); err != nil {
+		return err
+	}
+	s.Dispatch("GUILD_CREATE", g)
+	return nil
+}
+
+// AddChannel stores given channel and sends it to connected sessions.
+func (s *Server) AddChannel(c *discordgo.Channel) error {
+	if err := s.State.ChannelAdd(c); err != nil {
+		return err
+	}
+	s.Dispatch("CHANNEL_CREATE", c)
+	return nil
+}
+
+// AddMember stores given member and sends it to connected sessions.
+func (s *Server) AddMember(m *discordgo.Member) error {
+	if err := s.State.MemberAdd(m); err != nil {
+		return err
+	}
+	s.Dispatch("GUILD_MEMBER_ADD", m)
+	return nil
+}
+
+// SendMessage sends a message with given content by given user in given channel to
+// connected sessions.
+func (s *Server) SendMessage(author *discordgo.User, channelID, content string) *discordgo.Message {
+	msg := &discordgo.Message{
+		ID:        s.REST.NewID(),
+		ChannelID: channelID,
+		Content:   content,
+		Author:    author,
+		Timestamp: discordgo.Timestamp(time.Now().Format(time.RFC3339)),
+	}
+	if c, err := s.State.Channel(channelID); err == nil && c.GuildID != "" {
+		msg.GuildID = c.GuildID
+		if m, err := s.State.Member(c.GuildID, author.ID); err == nil {
+			msg.Member = m
+		}
+	}
+	s.Dispatch("MESSAGE_CREATE", msg)
+	return msg
+}
+
+// AddReaction sends a reaction of given user to connected sessions.
+func (s *Server) AddReaction(userID, channelID, messageID, emoji string) {
+	s.Dispatch("MESSAGE_REACTION_ADD", s.reaction(userID, channelID, messageID, emoji))
+}
+
+func (s *Server) reaction(userID, channelID, messageID, emoji string) *discordgo.MessageReaction {
+	r := &discordgo.MessageReaction{
+		UserID:    userID,
+		ChannelID: channelID,
+		MessageID: messageID,
+		Emoji:     discordgo.Emoji{Name: emoji},
+	}
+	if c, err := s.State.Channel(channelID); err == nil {
+		r.GuildID = c.GuildID
+	}
+	return r
+}
+
+// echo sends the gateway event Discord would send for given request of a session.
+func (s *Server) echo(a rosettatest.Action) {
+	switch a.Kind {
+	case rosettatest.ActionSend:
+		if msg, ok := s.REST.Message(a.MessageID); ok {
+			s.Dispatch("MESSAGE_CREATE", msg)
+		}
+	case rosettatest.ActionEdit:
+		if msg, ok := s.REST.Message(a.MessageID); ok {
+			s.Dispatch("MESSAGE_UPDATE", msg)
+		}
+	case rosettatest.ActionDelete:
+		s.Dispatch("MESSAGE_DELETE", &discordgo.Message{ID: a.MessageID, ChannelID: a.ChannelID})
+	case rosettatest.ActionReact:
+		s.Dispatch("MESSAGE_REACTION_ADD", s.reaction(s.userID(a.UserID), a.ChannelID, a.MessageID, a.Emoji))
+	case rosettatest.ActionUnreact:
+		if a.Emoji == "" {
+			s.Dispatch("MESSAGE_REACTION_REMOVE_ALL", s.reaction("", a.ChannelID, a.MessageID, ""))
+			return
+		}
+		s.Dispatch("MESSAGE_REACTION_REMOVE", s.reaction(s.userID(a.UserID), a.ChannelID, a.MessageID, a.Emoji))
+	}
+}
+
+// userID resolves `@me` to the ID of our bot user.
+func (s *Server) userID(id string) string {
+	if id == "@me" {
+		return s.State.User.ID
+	}
+	return id
+}
@@ -0,0 +1,153 @@
+package fakediscord
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+)
+
+// gateway opcodes used by our server.
+const (
+	opDispatch     = 0
+	opHeartbeat    = 1
+	opIdentify     = 2
+	opResume       = 6
+	opHello        = 10
+	opHeartbeatAck = 11
+)
+
+type payload struct {
+	Op       int         `json:"op"`
+	Sequence int64       `json:"s,omitempty"`
+	Type     string      `json:"t,omitempty"`
+	Data     interface{} `json:"d"`
+}
+
+// conn is a gateway connection of a session.
+type conn struct {
+	ws  *websocket.Conn
+	mu  sync.Mutex
+	seq int64
+}
+
+func (c *conn) send(op int, t string, data interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := payload{Op: op, Type: t, Data: data}
+	if op == opDispatch {
+		c.seq++
+		p.Sequence = c.seq
+	}
+	return c.ws.WriteJSON(p)
+}
+
+func (s *Server) serveGateway(w http.ResponseWriter, req *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	c := &conn{ws: ws}
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		_ = ws.Close()
+	}()
+
+	if err = c.send(opHello, "", map[string]interface{}{"heartbeat_interval": HeartbeatInterval.Milliseconds()}); err != nil {
+		return
+	}
+
+	for {
+		var p struct {
+			Op   int             `json:"op"`
+			Data json.RawMessage `json:"d"`
+		}
+		if err = ws.ReadJSON(&p); err != nil {
+			return
+		}
+
+		switch p.Op {
+		case opHeartbeat:
+			err = c.send(opHeartbeatAck, "", nil)
+		case opIdentify:
+			err = s.ready(c)
+		case opResume:
+			s.mu.Lock()
+			s.conns[c] = struct{}{}
+			s.mu.Unlock()
+			err = c.send(opDispatch, "RESUMED", map[string]interface{}{})
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// ready sends READY followed by a GUILD_CREATE of each guild to given connection.
+func (s *Server) ready(c *conn) error {
+	s.State.RLock()
+	guilds := make([]*discordgo.Guild, 0, len(s.State.Guilds))
+	unavailable := make([]*discordgo.Guild, 0, len(s.State.Guilds))
+	for _, g := range s.State.Guilds {
+		guilds = append(guilds, g)
+		unavailable = append(unavailable, &discordgo.Guild{ID: g.ID, Unavailable: true})
+	}
+	ready := &discordgo.Ready{
+		Version:         8,
+		SessionID:       s.State.SessionID,
+		User:            s.State.User,
+		Guilds:          unavailable,
+		PrivateChannels: s.State.PrivateChannels,
+	}
+	data, err := json.Marshal(ready)
+	s.State.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+
+	if err = c.send(opDispatch, "READY", json.RawMessage(data)); err != nil {
+		return err
+	}
+	for _, g := range guilds {
+		s.State.RLock()
+		data, err = json.Marshal(g)
+		s.State.RUnlock()
+		if err != nil {
+			return err
+		}
+		if err = c.send(opDispatch, "GUILD_CREATE", json.RawMessage(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dispatch sends an event of given type, e.g. `MESSAGE_CREATE`, to all connected sessions.
+func (s *Server) Dispatch(t string, data interface{}) {
+	s.mu.Lock()
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		_ = c.send(opDispatch, t, data)
+	}
+}
+
+// Connected returns the amount of sessions which are connected and identified.
+func (s *Server) Connected() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns)
+}
@@ -0,0 +1,133 @@
+// Package fakediscord provides a local fake of the Discord REST API and gateway, so the
+// bot can be tested end-to-end with discordgo without a token or network access.
+package fakediscord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/rosettatest"
+)
+
+const (
+	// BotUserID is the ID of the user sessions of our server are logged in as.
+	BotUserID = "100000000000000000"
+
+	// Token is accepted by our server. Any other token is accepted as well.
+	Token = "Bot fake-token"
+
+	// HeartbeatInterval is sent to connecting sessions with the hello packet.
+	HeartbeatInterval = 45 * time.Second
+)
+
+// Server is a fake Discord serving REST requests and gateway websocket connections.
+// Guilds, channels and members are stored in State and sent to sessions when they connect.
+// Requests of sessions are recorded by REST and echoed as gateway events like Discord does.
+type Server struct {
+	URL   string
+	State *discordgo.State
+	REST  *rosettatest.REST
+
+	http     *httptest.Server
+	upgrader websocket.Upgrader
+
+	// restMu serializes REST requests, so recorded actions can be mapped to their request.
+	restMu sync.Mutex
+
+	mu    sync.Mutex
+	conns map[*conn]struct{}
+}
+
+// New starts a server on a local port. It has to be closed by Close.
+func New() *Server {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: BotUserID, Username: "iridaceae", Bot: true}
+	state.Ready = discordgo.Ready{User: state.User, SessionID: "fake-session"}
+
+	s := &Server{State: state, REST: rosettatest.NewREST(state), conns: make(map[*conn]struct{})}
+	s.http = httptest.NewServer(s)
+	s.URL = s.http.URL
+	return s
+}
+
+// Close disconnects all sessions and stops the server.
+func (s *Server) Close() {
+	s.mu.Lock()
+	for c := range s.conns {
+		_ = c.ws.Close()
+	}
+	s.mu.Unlock()
+	s.http.Close()
+}
+
+// Session returns a discordgo session which sends all requests to our server.
+// It is connected by calling Open.
+func (s *Server) Session() *discordgo.Session {
+	dg, _ := discordgo.New(Token)
+	dg.Client = &http.Client{Transport: redirect(s.URL), Timeout: 10 * time.Second}
+	return dg
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch {
+	case websocket.IsWebSocketUpgrade(req):
+		s.serveGateway(w, req)
+	case strings.HasSuffix(req.URL.Path, "/gateway") || strings.HasSuffix(req.URL.Path, "/gateway/bot"):
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"url": "ws" + strings.TrimPrefix(s.URL, "http") + "/gateway", "shards": 1})
+	default:
+		s.restMu.Lock()
+		n := len(s.REST.Actions())
+		s.REST.ServeHTTP(w, req)
+		actions := s.REST.Actions()
+		if n > len(actions) {
+			// actions were reset meanwhile.
+			n = 0
+		}
+		actions = actions[n:]
+		s.restMu.Unlock()
+
+		for _, a := range actions {
+			s.echo(a)
+		}
+	}
+}
+
+// WaitAction waits until an action matching given filter was recorded.
+func (s *Server) WaitAction(timeout time.Duration, filter func(a rosettatest.Action) bool) (rosettatest.Action, bool) {
+	deadline := time.Now().Add(timeout)
+	for {
+		for _, a := range s.REST.Actions() {
+			if filter(a) {
+				return a, true
+			}
+		}
+		if time.Now().After(deadline) {
+			return rosettatest.Action{}, false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// redirect returns a transport sending requests of every host to given server URL.
+func redirect(serverURL string) http.RoundTripper {
+	host := strings.TrimPrefix(serverURL, "http://")
+	return roundTripper(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.URL.Scheme, req.URL.Host, req.Host = "http", host, host
+		return http.DefaultTransport.RoundTrip(req)
+	})
+}
+
+type roundTripper func(req *http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,99 @@
+package fakediscord
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/rosettatest"
+)
+
+func makeTestServer(t *testing.T) (*Server, *discordgo.User) {
+	s := New()
+	t.Cleanup(s.Close)
+
+	user := &discordgo.User{ID: "2", Username: "user"}
+	require.NoError(t, s.AddGuild(&discordgo.Guild{
+		ID:       "1",
+		Name:     "guild",
+		Channels: []*discordgo.Channel{{ID: "10", GuildID: "1", Type: discordgo.ChannelTypeGuildText}},
+		Members:  []*discordgo.Member{{GuildID: "1", User: user}},
+	}))
+	return s, user
+}
+
+func TestServer_Open(t *testing.T) {
+	s, user := makeTestServer(t)
+
+	dg := s.Session()
+	guilds := make(chan string, 1)
+	dg.AddHandler(func(_ *discordgo.Session, e *discordgo.GuildCreate) { guilds <- e.ID })
+	require.NoError(t, dg.Open())
+	defer dg.Close()
+
+	assert.Equal(t, BotUserID, dg.State.User.ID)
+	assert.Equal(t, 1, s.Connected())
+	select {
+	case id := <-guilds:
+		assert.Equal(t, "1", id)
+	case <-time.After(time.Second):
+		t.Fatal("GUILD_CREATE not received")
+	}
+
+	// messages of our server are received and requests are echoed.
+	messages := make(chan *discordgo.Message, 2)
+	dg.AddHandler(func(_ *discordgo.Session, e *discordgo.MessageCreate) { messages <- e.Message })
+	s.SendMessage(user, "10", "hello")
+	select {
+	case msg := <-messages:
+		assert.Equal(t, "hello", msg.Content)
+		assert.Equal(t, "1", msg.GuildID)
+	case <-time.After(time.Second):
+		t.Fatal("MESSAGE_CREATE not received")
+	}
+
+	msg, err := dg.ChannelMessageSend("10", "world")
+	require.NoError(t, err)
+	assert.Equal(t, BotUserID, msg.Author.ID)
+	select {
+	case echo := <-messages:
+		assert.Equal(t, msg.ID, echo.ID)
+	case <-time.After(time.Second):
+		t.Fatal("sent message not echoed")
+	}
+}
+
+func TestServer_Router(t *testing.T) {
+	s, user := makeTestServer(t)
+
+	cfg := rosetta.NewDefaultConfig()
+	cfg.OnError = nil
+	r := rosetta.NewRouter(cfg)
+	defer r.Shutdown()
+
+	dg := s.Session()
+	r.Setup(dg)
+	require.NoError(t, dg.Open())
+	defer dg.Close()
+
+	// wait for the guild to be available in the state of our session.
+	require.Eventually(t, func() bool {
+		_, err := dg.State.Guild("1")
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+
+	s.SendMessage(user, "10", "r!help")
+	a, ok := s.WaitAction(time.Second, func(a rosettatest.Action) bool { return a.Kind == rosettatest.ActionSend })
+	require.True(t, ok, "help not sent")
+	require.NotNil(t, a.Embed())
+
+	// help is sent to the DM channel of the user.
+	c, err := s.State.Channel(a.ChannelID)
+	require.NoError(t, err)
+	assert.Equal(t, discordgo.ChannelTypeDM, c.Type)
+	assert.Equal(t, user.ID, c.Recipients[0].ID)
+}
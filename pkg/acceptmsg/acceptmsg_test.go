@@ -0,0 +1,69 @@
+package acceptmsg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/internal/components"
+	"github.com/Iridaceae/iridaceae/internal/testutils/fakediscord"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/rosettatest"
+)
+
+func TestAcceptMessage_Send(t *testing.T) {
+	_, err := New().Send("10")
+	assert.ErrorIs(t, err, components.ErrSessionNotDefined)
+
+	s := fakediscord.New()
+	defer s.Close()
+	require.NoError(t, s.AddGuild(&discordgo.Guild{
+		ID:       "1",
+		Channels: []*discordgo.Channel{{ID: "10", GuildID: "1", Type: discordgo.ChannelTypeGuildText}},
+	}))
+
+	dg := s.Session()
+	require.NoError(t, dg.Open())
+	defer dg.Close()
+
+	accepted := make(chan *discordgo.Message, 1)
+	am, err := New().
+		WithSession(dg).
+		WithContent("accept?").
+		AcceptOnlyUser("2").
+		OnAccept(func(msg *discordgo.Message) { accepted <- msg }).
+		Send("10")
+	require.NoError(t, err)
+
+	reactions := func(kind rosettatest.ActionKind) []string {
+		emojis := make([]string, 0)
+		for _, a := range s.REST.Actions() {
+			if a.Kind == kind && a.MessageID == am.ID {
+				emojis = append(emojis, a.Emoji)
+			}
+		}
+		return emojis
+	}
+	assert.Equal(t, []string{"✅", "❌"}, reactions(rosettatest.ActionReact))
+
+	// reactions of other users are removed and ignored.
+	s.AddReaction("3", "10", am.ID, "✅")
+	_, ok := s.WaitAction(time.Second, func(a rosettatest.Action) bool {
+		return a.Kind == rosettatest.ActionUnreact && a.UserID == "3"
+	})
+	assert.True(t, ok)
+
+	s.AddReaction("2", "10", am.ID, "✅")
+	select {
+	case msg := <-accepted:
+		assert.Equal(t, am.ID, msg.ID)
+	case <-time.After(time.Second):
+		t.Fatal("accept handler not called")
+	}
+	_, ok = s.WaitAction(time.Second, func(a rosettatest.Action) bool {
+		return a.Kind == rosettatest.ActionUnreact && a.Emoji == ""
+	})
+	assert.True(t, ok, "reactions not removed")
+}
@@ -18,6 +18,11 @@ var (
 	IridaceaeClientSecrets, _  = configparser.Register("iris.clientsecret", "ClientSecret of the bot", nil)
 	IridaceaeBotToken, _       = configparser.Register("iris.authtoken", "authentication token of the bot", nil)
 	CmdPrefix, _               = configparser.Register("iris.cmdprefix", "prefix for iris", "-ir ")
+	MetricsEnabled, _          = configparser.Register("iris.metrics.enabled", "serve prometheus metrics over http", false)
+	MetricsAddr, _             = configparser.Register("iris.metrics.addr", "listen address of the metrics server", ":9090")
+	MetricsPath, _             = configparser.Register("iris.metrics.path", "http path serving prometheus metrics", "/metrics")
+	DefaultLocale, _           = configparser.Register("iris.locale", "locale responses fall back to", "en")
+	LocalesDir, _              = configparser.Register("iris.locales.dir", "directory of additional <locale>.json message catalogs", "")
 	Loaded                     = false
 	CI                         = true
 )
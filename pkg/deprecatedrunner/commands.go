@@ -0,0 +1,78 @@
+package deprecatedrunner
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+// Commands returns the commands of cmdHandlers described in the default locale, e.g. to be
+// documented by rosetta/docs. They're only descriptions, messages are still dispatched by
+// onMessageReceived.
+func Commands() ([]rosetta.Command, error) {
+	translations, err := loadTranslations()
+	if err != nil {
+		return nil, err
+	}
+	ir := &Iris{translations: translations}
+	ir.registerCmdHandlers()
+
+	locale := translations.Fallback()
+	cmds := make([]rosetta.Command, 0, len(ir.cmdHandlers))
+	for invoke, cmd := range ir.cmdHandlers {
+		cmds = append(cmds, &legacyCommand{
+			invoke:  invoke,
+			desc:    ir.t(locale, "iris."+invoke+".description"),
+			example: strings.TrimSpace(cmd.exampleParams),
+		})
+	}
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].GetInvokers()[0] < cmds[j].GetInvokers()[0]
+	})
+	return cmds, nil
+}
+
+// legacyCommand describes a command of cmdHandlers as rosetta.Command.
+type legacyCommand struct {
+	invoke  string
+	desc    string
+	example string
+}
+
+func (c *legacyCommand) GetInvokers() []string {
+	return []string{c.invoke}
+}
+
+func (c *legacyCommand) GetDescription() string {
+	return c.desc
+}
+
+func (c *legacyCommand) GetUsage() string {
+	usage := c.invoke
+	if c.example != "" {
+		usage += " " + c.example
+	}
+	return "`" + usage + "`"
+}
+
+func (c *legacyCommand) GetGroup() string {
+	return rosetta.GroupGeneral
+}
+
+func (c *legacyCommand) GetDomain() string {
+	return "iris." + c.invoke
+}
+
+func (c *legacyCommand) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (c *legacyCommand) IsExecutableInDM() bool {
+	return true
+}
+
+// Exec does nothing, as the command is dispatched by onMessageReceived.
+func (c *legacyCommand) Exec(rosetta.Context) error {
+	return nil
+}
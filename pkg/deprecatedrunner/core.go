@@ -4,6 +4,7 @@ package deprecatedrunner
 import (
 	"bytes"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"regexp"
@@ -12,9 +13,11 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/Iridaceae/iridaceae/pkg/i18n"
 	"github.com/Iridaceae/iridaceae/pkg/log"
 
 	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/metrics"
 
 	"github.com/Iridaceae/iridaceae/pkg"
 
@@ -32,19 +35,19 @@ type cmdHandler func(s *discordgo.Session, m *discordgo.MessageCreate, ex string
 
 type botCommand struct {
 	handler       cmdHandler
-	desc          string
 	exampleParams string
 }
 
 // Iris defines the structure for the bots' functionality.
 type Iris struct {
-	helpMessage   string
-	inviteMessage string
+	inviteURL     string
 	discord       *discordgo.Session
 	cmdHandlers   map[string]botCommand
 	poms          UserPomodoroMap
-	// record metrics here
-	// metrics metrics.Recorder
+	metrics       *metrics.Registry
+	metricsServer *http.Server
+	translations  *i18n.Bundle
+	locales       rosetta.LocaleProvider
 }
 
 // New creates a new instance of Iris that can deploy over Heroku.
@@ -56,38 +59,49 @@ func New() *Iris {
 		log.Error(err).Msg("")
 	}
 
+	translations, err := loadTranslations()
+	if err != nil {
+		log.Error(err).Msg("")
+		translations = i18n.NewBundle(pkg.DefaultLocale.GetString())
+	}
+
 	ir := &Iris{
-		poms: NewUserPomodoroMap(),
+		poms:         NewUserPomodoroMap(),
+		metrics:      metrics.New(),
+		translations: translations,
+		locales:      rosetta.NewMemoryLocaleProvider(),
 	}
 
 	ir.registerCmdHandlers()
-	ir.helpMessage = ir.buildHelpMessage()
-	ir.inviteMessage = fmt.Sprintf("Click here: <"+pkg.BaseAuthURLTemplate+"> to invite me to the server", pkg.IridaceaeClientID.GetString())
+	ir.inviteURL = fmt.Sprintf(pkg.BaseAuthURLTemplate, pkg.IridaceaeClientID.GetString())
 	return ir
 }
 
 func (ir *Iris) registerCmdHandlers() {
 	ir.cmdHandlers = map[string]botCommand{
-		"help":   {handler: ir.onCmdHelp, desc: "Show this help message", exampleParams: ""},
-		"pom":    {handler: ir.onCmdStartPom, desc: "Start a pom work cycle. You can optionally specify the period of time (default: 25 mins)", exampleParams: "50"},
-		"stop":   {handler: ir.onCmdCancelPom, desc: "cancel current pom cycle", exampleParams: ""},
-		"status": {handler: ir.onCmdStatus, desc: "get status of given users", exampleParams: ""},
-		"invite": {handler: ir.onCmdInvite, desc: "SetZ an invite link you can use to have the bot join the server", exampleParams: ""},
+		"help":   {handler: ir.onCmdHelp, exampleParams: ""},
+		"pom":    {handler: ir.onCmdStartPom, exampleParams: "50"},
+		"stop":   {handler: ir.onCmdCancelPom, exampleParams: ""},
+		"status": {handler: ir.onCmdStatus, exampleParams: ""},
+		"invite": {handler: ir.onCmdInvite, exampleParams: ""},
+		"stats":  {handler: ir.onCmdStats, exampleParams: ""},
+		"lang":   {handler: ir.onCmdLang, exampleParams: " de"},
 		// "simp":   {handler: ir.onCmdSimp, desc: "notify another friend with the good stuff", exampleParams: ""},
 	}
 }
 
-func (ir *Iris) buildHelpMessage() string {
+// buildHelpMessage returns the help message in given locale. Descriptions are looked up by `iris.<command>.description`.
+func (ir *Iris) buildHelpMessage(locale string) string {
 	helpBuffer := bytes.Buffer{}
-	helpBuffer.WriteString("Made by **@aarnphm**\n")
+	helpBuffer.WriteString(ir.t(locale, "iris.help.made_by") + "\n")
 
 	// just use map iteration order
 	for cmdStr, cmd := range ir.cmdHandlers {
-		helpBuffer.WriteString(fmt.Sprintf("\n•  **%s**  -  %s\n", cmdStr, cmd.desc))
-		helpBuffer.WriteString(fmt.Sprintf("   Example: `%s%s%s`\n", pkg.CmdPrefix.GetString(), cmdStr, cmd.exampleParams))
+		helpBuffer.WriteString(fmt.Sprintf("\n•  **%s**  -  %s\n", cmdStr, ir.t(locale, "iris."+cmdStr+".description")))
+		helpBuffer.WriteString(fmt.Sprintf("   %s: `%s%s%s`\n", ir.t(locale, "iris.help.example"), pkg.CmdPrefix.GetString(), cmdStr, cmd.exampleParams))
 	}
 
-	helpBuffer.WriteString("\n" + ir.inviteMessage)
+	helpBuffer.WriteString("\n" + ir.t(locale, "iris.invite", ir.inviteURL))
 
 	return helpBuffer.String()
 }
@@ -100,26 +114,59 @@ func (ir *Iris) Start() error {
 		return err
 	}
 
-	// onReady will prepare our metrics, which will get from prometheus
 	ir.discord.AddHandler(ir.onReady)
 	ir.discord.AddHandler(ir.onMessageReceived)
 
+	if err = ir.serveMetrics(); err != nil {
+		return err
+	}
+
 	_ = ir.discord.Open()
 
-	defer func() {
-		sc := make(chan os.Signal, 1)
-		signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
-		<-sc
-	}()
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
+	<-sc
 
+	if ir.metricsServer != nil {
+		_ = ir.metricsServer.Close()
+	}
 	return ir.discord.Close()
 }
 
-// onReady should prepare metrics collector and setup web interface for configuration (features).
+// serveMetrics starts a http server for prometheus to scrape if enabled through configuration.
+func (ir *Iris) serveMetrics() error {
+	if !pkg.MetricsEnabled.GetBool() {
+		return nil
+	}
+
+	exp := metrics.NewExporter(ir.metrics).
+		Gauge("iris_guilds", "Number of guilds the bot is a member of.", ir.guildCount).
+		Gauge("iris_gateway_latency_seconds", "Latency between the last heartbeat and its acknowledgement.", func() float64 {
+			return ir.discord.HeartbeatLatency().Seconds()
+		}).
+		Gauge("iris_pomodoros_active", "Number of pomodoros currently running.", func() float64 {
+			return float64(ir.poms.Count())
+		})
+
+	srv, err := exp.Serve(pkg.MetricsAddr.GetString(), pkg.MetricsPath.GetString())
+	if err != nil {
+		return err
+	}
+	ir.metricsServer = srv
+	log.Info().Msgf("serving metrics on %s%s", srv.Addr, pkg.MetricsPath.GetString())
+	return nil
+}
+
+func (ir *Iris) guildCount() float64 {
+	ir.discord.State.RLock()
+	defer ir.discord.State.RUnlock()
+	return float64(len(ir.discord.State.Guilds))
+}
+
+// onReady logs the connected user. Metrics are served by serveMetrics.
 func (ir *Iris) onReady(s *discordgo.Session, event *discordgo.Ready) {
 	numGuilds := int64(len(s.State.Guilds))
 	log.Info().Msgf("connected. userName: %s#%s numGuilds: %d", event.User.Username, event.User.Discriminator, numGuilds)
-	// should include metrics collection down here
 }
 
 // onMessageReceived will be called everytime a new message is created on any channel that the bot is listening to.
@@ -130,6 +177,10 @@ func (ir *Iris) onMessageReceived(s *discordgo.Session, m *discordgo.MessageCrea
 		return
 	}
 
+	if !m.Author.Bot {
+		ir.metrics.ObserveScanned()
+	}
+
 	// we want to know who send the message
 	log.Debug().Msgf("sent by:%s#%s content:%s", m.Author.Username, m.Author.Discriminator, m.Content)
 	msg := m.Content
@@ -148,9 +199,12 @@ func (ir *Iris) onMessageReceived(s *discordgo.Session, m *discordgo.MessageCrea
 			}
 
 			if f.handler != nil {
+				start := time.Now()
 				f.handler(s, m, rest)
+				ir.metrics.ObserveMatched(m.ID)
+				ir.metrics.ObserveCommand("iris."+strings.ToLower(cmd[0]), time.Since(start), nil)
 			} else {
-				_, err := s.ChannelMessageSend(m.ChannelID, "Command error/not supported - dm **@aarnphm**")
+				_, err := s.ChannelMessageSend(m.ChannelID, ir.t(ir.locale(m.GuildID, m.Author.ID), "iris.unsupported"))
 				if err != nil {
 					log.Error(err).Msg("")
 				}
@@ -169,6 +223,7 @@ func (ir *Iris) onPomEnded(notify NotifyInfo, completed bool) {
 		notifyTitle string
 		notifyDesc  string
 	)
+	locale := ir.locale(notify.User.GUILDID, notify.User.DiscordID)
 	user, er := ir.discord.User(notify.User.DiscordID)
 	if er == nil {
 		toMention = append(toMention, user.Mention())
@@ -192,9 +247,9 @@ func (ir *Iris) onPomEnded(notify NotifyInfo, completed bool) {
 			}
 		}
 		// notify title
-		notifyTitle = "Pomodoro"
+		notifyTitle = ir.t(locale, "iris.pom.title")
 
-		notifyDesc = ":timer: Work cycle complete. :timer:\n :blush: Time to take a break! :blush:"
+		notifyDesc = ir.t(locale, "iris.pom.completed")
 
 		message := ""
 
@@ -217,7 +272,7 @@ func (ir *Iris) onPomEnded(notify NotifyInfo, completed bool) {
 
 		_, _ = ir.discord.ChannelMessageSendComplex(notify.User.ChannelID, data)
 	} else {
-		_, _ = ir.discord.ChannelMessageSend(notify.User.ChannelID, fmt.Sprintf("%s, pom canceled!", user.Mention()))
+		_, _ = ir.discord.ChannelMessageSend(notify.User.ChannelID, ir.t(locale, "iris.pom.canceled", user.Mention()))
 	}
 }
 
@@ -256,15 +311,16 @@ func (ir *Iris) onCmdStartPom(s *discordgo.Session, m *discordgo.MessageCreate,
 		},
 	}
 
+	locale := ir.locale(m.GuildID, m.Author.ID)
 	if ir.poms.CreateIfEmpty(pomDuration, ir.onPomEnded, notif) {
 		// notif title
 		var (
 			notifyTitle string
 			notifyDesc  string
 		)
-		notifyTitle = "Pomodoro"
+		notifyTitle = ir.t(locale, "iris.pom.title")
 
-		notifyDesc = fmt.Sprintf(":peach: Your timer is set to **%d minutes** :peach:\n :blush: Happy working :blush:", int(pomDuration.Minutes()))
+		notifyDesc = ir.t(locale, "iris.pom.started", int(pomDuration.Minutes()))
 
 		content := fmt.Sprintf("%s\n", m.Author.Mention())
 
@@ -281,7 +337,7 @@ func (ir *Iris) onCmdStartPom(s *discordgo.Session, m *discordgo.MessageCreate,
 		}
 		_, _ = s.ChannelMessageSendComplex(m.ChannelID, data)
 	} else {
-		_, _ = s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("A pomodoro is already running for %s", m.Author.Mention()))
+		_, _ = s.ChannelMessageSend(m.ChannelID, ir.t(locale, "iris.pom.running", m.Author.Mention()))
 	}
 }
 
@@ -290,9 +346,10 @@ func (ir *Iris) onCmdStatus(s *discordgo.Session, m *discordgo.MessageCreate, ex
 		notifyTitle string
 		notifyDesc  string
 	)
-	notifyTitle = "Status"
+	locale := ir.locale(m.GuildID, m.Author.ID)
+	notifyTitle = ir.t(locale, "iris.status.title")
 
-	notifyDesc = fmt.Sprintf("Amount of work time: *%s*", datastore.FetchNumHours(m.Author.ID))
+	notifyDesc = ir.t(locale, "iris.status.work_time", datastore.FetchNumHours(m.Author.ID))
 
 	content := fmt.Sprintf("%s\n", m.Author.Mention())
 
@@ -312,15 +369,19 @@ func (ir *Iris) onCmdStatus(s *discordgo.Session, m *discordgo.MessageCreate, ex
 
 func (ir *Iris) onCmdCancelPom(s *discordgo.Session, m *discordgo.MessageCreate, ex string) {
 	if exists := ir.poms.RemoveIfExists(m.Author.ID); !exists {
-		_, _ = s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("No pom is currently running for %s", m.Author.Mention()))
+		_, _ = s.ChannelMessageSend(m.ChannelID, ir.t(ir.locale(m.GuildID, m.Author.ID), "iris.pom.not_running", m.Author.Mention()))
 	}
 	// if this removal is success then call onPomEnded
 }
 
 func (ir *Iris) onCmdHelp(s *discordgo.Session, m *discordgo.MessageCreate, ex string) {
-	_, _ = s.ChannelMessageSend(m.ChannelID, ir.helpMessage)
+	_, _ = s.ChannelMessageSend(m.ChannelID, ir.buildHelpMessage(ir.locale(m.GuildID, m.Author.ID)))
+}
+
+func (ir *Iris) onCmdStats(s *discordgo.Session, m *discordgo.MessageCreate, ex string) {
+	_, _ = s.ChannelMessageSendEmbed(m.ChannelID, metrics.StatsEmbed(ir.metrics.Snapshot()))
 }
 
 func (ir *Iris) onCmdInvite(s *discordgo.Session, m *discordgo.MessageCreate, ex string) {
-	_, _ = s.ChannelMessageSend(m.ChannelID, ir.inviteMessage)
+	_, _ = s.ChannelMessageSend(m.ChannelID, ir.t(ir.locale(m.GuildID, m.Author.ID), "iris.invite", ir.inviteURL))
 }
@@ -0,0 +1,86 @@
+package deprecatedrunner
+
+import (
+	"embed"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg"
+	"github.com/Iridaceae/iridaceae/pkg/i18n"
+	"github.com/Iridaceae/iridaceae/pkg/log"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// loadTranslations returns our embedded catalogs merged with the ones of the configured locales directory.
+func loadTranslations() (*i18n.Bundle, error) {
+	b := i18n.NewBundle(pkg.DefaultLocale.GetString())
+	if err := b.LoadFS(localeFS, "locales"); err != nil {
+		return nil, err
+	}
+	if dir := pkg.LocalesDir.GetString(); dir != "" {
+		if err := b.LoadDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// locale returns the locale of given user in given guild.
+func (ir *Iris) locale(guildID, userID string) string {
+	l, err := rosetta.ResolveLocale(ir.locales, guildID, userID, ir.translations.Fallback())
+	if err != nil {
+		log.Error(err).Msg("")
+	}
+	return l
+}
+
+// t returns the message of given key in given locale.
+func (ir *Iris) t(locale, key string, args ...interface{}) string {
+	return ir.translations.T(locale, key, args...)
+}
+
+func (ir *Iris) onCmdLang(s *discordgo.Session, m *discordgo.MessageCreate, ex string) {
+	args := strings.Fields(ex)
+	locale := ir.locale(m.GuildID, m.Author.ID)
+
+	var err error
+	switch {
+	case len(args) == 0:
+		_, err = s.ChannelMessageSend(m.ChannelID, ir.t(locale, "iris.lang.current", locale, strings.Join(ir.translations.Locales(), ", ")))
+	case args[0] == "reset":
+		if err = ir.locales.SetUserLocale(m.Author.ID, ""); err == nil {
+			locale = ir.locale(m.GuildID, m.Author.ID)
+			_, err = s.ChannelMessageSend(m.ChannelID, ir.t(locale, "iris.lang.reset"))
+		}
+	case args[0] == "guild" && len(args) > 1:
+		perms, _ := s.UserChannelPermissions(m.Author.ID, m.ChannelID)
+		if m.GuildID == "" || perms&discordgo.PermissionAdministrator == 0 {
+			_, err = s.ChannelMessageSend(m.ChannelID, ir.t(locale, "iris.lang.not_permitted"))
+			break
+		}
+		err = ir.setLocale(s, m, args[1], func(l string) error { return ir.locales.SetGuildLocale(m.GuildID, l) })
+	default:
+		err = ir.setLocale(s, m, args[0], func(l string) error { return ir.locales.SetUserLocale(m.Author.ID, l) })
+	}
+	if err != nil {
+		log.Error(err).Msg("")
+	}
+}
+
+// setLocale validates given locale, passes it to set and confirms it in the new locale.
+func (ir *Iris) setLocale(s *discordgo.Session, m *discordgo.MessageCreate, locale string, set func(string) error) error {
+	locale = i18n.NormalizeLocale(locale)
+	if !ir.translations.Has(locale) {
+		_, err := s.ChannelMessageSend(m.ChannelID, ir.t(ir.locale(m.GuildID, m.Author.ID), "iris.lang.unknown", locale))
+		return err
+	}
+	if err := set(locale); err != nil {
+		return err
+	}
+	_, err := s.ChannelMessageSend(m.ChannelID, ir.t(ir.locale(m.GuildID, m.Author.ID), "iris.lang.updated", locale))
+	return err
+}
@@ -0,0 +1,238 @@
+// Package i18n provides message catalogs with plural forms, which are loaded from JSON files
+// named by their locale, e.g. `de.json`:
+//
+//	{
+//	  "greeting": "Hallo %s",
+//	  "minutes": {"one": "%d Minute", "other": "%d Minuten"}
+//	}
+//
+// Messages are formatted with fmt.Sprintf. The plural form of a message is chosen by its
+// first integer argument.
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is the locale messages fall back to if not specified otherwise.
+const DefaultLocale = "en"
+
+var (
+	// ErrInvalidMessage is thrown when a catalog message is neither a string nor plural forms.
+	ErrInvalidMessage = errors.New("message must be a string or an object of plural forms")
+
+	// ErrMissingOther is thrown when plural forms of a message lack the `other` form.
+	ErrMissingOther = errors.New("plural forms must contain other")
+)
+
+// Message maps plural forms, i.e. zero, one, two, few, many and other, to their text.
+// Messages without plural forms only have other.
+type Message map[string]string
+
+// Catalog maps keys to messages of a single locale.
+type Catalog map[string]Message
+
+// ParseCatalog parses a JSON object of keys to either strings or plural forms.
+func ParseCatalog(data []byte) (Catalog, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	c := make(Catalog, len(raw))
+	for key, v := range raw {
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			c[key] = Message{PluralOther: s}
+			continue
+		}
+		var m Message
+		if err := json.Unmarshal(v, &m); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, ErrInvalidMessage)
+		}
+		if _, ok := m[PluralOther]; !ok {
+			return nil, fmt.Errorf("%s: %w", key, ErrMissingOther)
+		}
+		c[key] = m
+	}
+	return c, nil
+}
+
+// Bundle holds catalogs of multiple locales. It is safe for concurrent use.
+type Bundle struct {
+	mu       sync.RWMutex
+	fallback string
+	catalogs map[string]Catalog
+}
+
+// NewBundle returns an empty Bundle falling back to given locale, DefaultLocale if empty.
+func NewBundle(fallback string) *Bundle {
+	if fallback == "" {
+		fallback = DefaultLocale
+	}
+	return &Bundle{fallback: NormalizeLocale(fallback), catalogs: make(map[string]Catalog)}
+}
+
+// Fallback returns the locale messages fall back to.
+func (b *Bundle) Fallback() string {
+	return b.fallback
+}
+
+// Add merges given catalog into the one of given locale. Existing keys are overridden.
+func (b *Bundle) Add(locale string, c Catalog) {
+	locale = NormalizeLocale(locale)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	dst, ok := b.catalogs[locale]
+	if !ok {
+		dst = make(Catalog, len(c))
+		b.catalogs[locale] = dst
+	}
+	for k, m := range c {
+		dst[k] = m
+	}
+}
+
+// LoadFS adds all `<locale>.json` catalogs of given directory of fsys, e.g. an embed.FS.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		data, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return err
+		}
+		c, err := ParseCatalog(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f, err)
+		}
+		b.Add(strings.TrimSuffix(path.Base(f), ".json"), c)
+	}
+	return nil
+}
+
+// LoadDir adds all `<locale>.json` catalogs of given directory.
+func (b *Bundle) LoadDir(dir string) error {
+	return b.LoadFS(os.DirFS(dir), ".")
+}
+
+// Locales returns all locales with a catalog, sorted.
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	res := make([]string, 0, len(b.catalogs))
+	for l := range b.catalogs {
+		res = append(res, l)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Has returns true if a catalog of given locale or its language exists.
+func (b *Bundle) Has(locale string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, l := range chain(locale, "") {
+		if _, ok := b.catalogs[l]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the message of given key formatted with args. The message is looked up in
+// given locale, its language, e.g. `pt` for `pt-br`, and the fallback locale. If none of
+// them contains key, false is returned.
+func (b *Bundle) Lookup(locale, key string, args ...interface{}) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, l := range chain(locale, b.fallback) {
+		if m, ok := b.catalogs[l][key]; ok {
+			return m.Format(l, args...), true
+		}
+	}
+	return "", false
+}
+
+// T returns the message of given key like Lookup, or the key itself if it doesn't exist.
+func (b *Bundle) T(locale, key string, args ...interface{}) string {
+	if s, ok := b.Lookup(locale, key, args...); ok {
+		return s
+	}
+	return key
+}
+
+// Format returns the plural form of given message matching the first integer of args
+// in given locale, formatted with args.
+func (m Message) Format(locale string, args ...interface{}) string {
+	text := m[PluralOther]
+	if len(m) > 1 {
+		if n, ok := firstInt(args); ok {
+			if form, ok := m[PluralForm(locale, n)]; ok {
+				text = form
+			}
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// NormalizeLocale lower cases given locale and separates its parts by dashes, e.g. `pt-br` for `pt_BR`.
+func NormalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+// chain returns locales to look messages up in, most specific first.
+func chain(locale, fallback string) []string {
+	locale = NormalizeLocale(locale)
+	res := make([]string, 0, 3)
+	if locale != "" {
+		res = append(res, locale)
+		if i := strings.IndexByte(locale, '-'); i > 0 {
+			res = append(res, locale[:i])
+		}
+	}
+	if fallback != "" && fallback != locale {
+		res = append(res, fallback)
+	}
+	return res
+}
+
+func firstInt(args []interface{}) (int64, bool) {
+	for _, a := range args {
+		switch v := a.(type) {
+		case int:
+			return int64(v), true
+		case int8:
+			return int64(v), true
+		case int16:
+			return int64(v), true
+		case int32:
+			return int64(v), true
+		case int64:
+			return v, true
+		case uint:
+			return int64(v), true
+		case uint8:
+			return int64(v), true
+		case uint16:
+			return int64(v), true
+		case uint32:
+			return int64(v), true
+		case uint64:
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
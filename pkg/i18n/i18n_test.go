@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCatalog(t *testing.T) {
+	c, err := ParseCatalog([]byte(`{"a": "text", "b": {"one": "%d item", "other": "%d items"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, Catalog{
+		"a": {PluralOther: "text"},
+		"b": {PluralOne: "%d item", PluralOther: "%d items"},
+	}, c)
+
+	_, err = ParseCatalog([]byte(`{"a": 1}`))
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+	_, err = ParseCatalog([]byte(`{"a": {"one": "item"}}`))
+	assert.ErrorIs(t, err, ErrMissingOther)
+	_, err = ParseCatalog([]byte(`[]`))
+	assert.Error(t, err)
+}
+
+func TestBundle(t *testing.T) {
+	b := NewBundle("")
+	require.NoError(t, b.LoadFS(fstest.MapFS{
+		"locales/en.json":    {Data: []byte(`{"hello": "hello %s", "only.en": "english", "items": {"one": "%d item", "other": "%d items"}}`)},
+		"locales/de.json":    {Data: []byte(`{"hello": "hallo %s", "items": {"one": "%d Eintrag", "other": "%d Einträge"}}`)},
+		"locales/de_AT.json": {Data: []byte(`{"hello": "servus %s"}`)},
+		"locales/README.md":  {Data: []byte(`not a catalog`)},
+	}, "locales"))
+
+	assert.Equal(t, []string{"de", "de-at", "en"}, b.Locales())
+	assert.True(t, b.Has("de-CH"))
+	assert.False(t, b.Has("fr"))
+
+	tests := []struct {
+		name     string
+		locale   string
+		key      string
+		args     []interface{}
+		expected string
+	}{
+		{"fallback locale", "", "hello", []interface{}{"you"}, "hello you"},
+		{"locale", "de", "hello", []interface{}{"du"}, "hallo du"},
+		{"region", "de-AT", "hello", []interface{}{"du"}, "servus du"},
+		{"region falls back to language", "de-at", "items", []interface{}{2}, "2 Einträge"},
+		{"missing locale", "fr", "hello", []interface{}{"toi"}, "hello toi"},
+		{"missing key falls back", "de", "only.en", nil, "english"},
+		{"missing key", "de", "nothing", nil, "nothing"},
+		{"plural one", "en", "items", []interface{}{1}, "1 item"},
+		{"plural other", "en", "items", []interface{}{int64(0)}, "0 items"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, b.T(tt.locale, tt.key, tt.args...))
+		})
+	}
+
+	_, ok := b.Lookup("de", "nothing")
+	assert.False(t, ok)
+
+	b.Add("de", Catalog{"hello": {PluralOther: "moin %s"}})
+	assert.Equal(t, "moin du", b.T("de", "hello", "du"))
+	assert.Equal(t, "1 Eintrag", b.T("de", "items", 1))
+}
+
+func TestPluralForm(t *testing.T) {
+	tests := []struct {
+		locale   string
+		n        int64
+		expected string
+	}{
+		{"en", 1, PluralOne},
+		{"en", 0, PluralOther},
+		{"en-US", 2, PluralOther},
+		{"fr", 0, PluralOne},
+		{"pt_BR", 1, PluralOne},
+		{"ja", 1, PluralOther},
+		{"ru", 21, PluralOne},
+		{"ru", 3, PluralFew},
+		{"ru", 12, PluralMany},
+		{"pl", 22, PluralFew},
+		{"pl", 21, PluralMany},
+		{"cs", 4, PluralFew},
+		{"cs", 5, PluralOther},
+		{"xx", -1, PluralOne},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, PluralForm(tt.locale, tt.n), "%s %d", tt.locale, tt.n)
+	}
+}
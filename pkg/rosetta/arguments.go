@@ -143,6 +143,22 @@ func (a Arguments) Args() []Argument {
 	return a.args
 }
 
+// From returns the arguments starting at index n. Raw content is kept from the n-th
+// argument on, thus whitespace and line breaks in between stay intact.
+func (a Arguments) From(n int) *Arguments {
+	if n <= 0 {
+		return &Arguments{raw: a.raw, args: a.args}
+	}
+	if n >= len(a.args) {
+		return &Arguments{args: make([]Argument, 0)}
+	}
+	raw := ""
+	if idx := ArgumentsRegex.FindAllStringIndex(a.raw, -1); len(idx) == len(a.args) {
+		raw = a.raw[idx[n][0]:]
+	}
+	return &Arguments{raw: raw, args: a.args[n:]}
+}
+
 // AsSingle returns a singleton of arguments with raw content without args.
 func (a Arguments) AsSingle() *Arguments {
 	return &Arguments{raw: a.raw}
@@ -78,6 +78,15 @@ func TestFromArguments(t *testing.T) {
 	assert.Equal(t, rarg, []Argument{"a", "b"})
 }
 
+func TestArguments_From(t *testing.T) {
+	args := ParseArguments("add faq  first line\nsecond \"quoted arg\"")
+	assert.Equal(t, "faq  first line\nsecond \"quoted arg\"", args.From(1).Raw())
+	assert.Equal(t, []Argument{"first", "line", "second", "quoted arg"}, args.From(2).Args())
+	assert.Equal(t, args.Raw(), args.From(0).Raw())
+	assert.Equal(t, 0, args.From(10).Len())
+	assert.Equal(t, "", FromArguments([]Argument{"a", "b"}).From(1).Raw())
+}
+
 func TestArgument_AsChannelMentionID(t *testing.T) {
 	t.Run("invalid channel mention id", func(t *testing.T) {
 		cid := Argument("#asdf")
@@ -0,0 +1,69 @@
+package rosetta
+
+import (
+	"strings"
+)
+
+// Segment is a single command of a chained message, e.g. `pom 25; status | upper`.
+type Segment struct {
+	Content string
+
+	// Piped is true if the text output of the previous segment is passed to this one.
+	Piped bool
+}
+
+// SplitChain splits given content into segments at `;` and at `|` surrounded by whitespace.
+// Operators inside quotes or code are ignored and can be escaped with a backslash.
+// Spoilers (`||`) aren't treated as pipes.
+func SplitChain(content string) []Segment {
+	segments := make([]Segment, 0, 1)
+	var (
+		cur      strings.Builder
+		piped    bool
+		quoted   bool
+		backtick bool
+	)
+	push := func(nextPiped bool) {
+		segments = append(segments, Segment{Content: strings.TrimSpace(cur.String()), Piped: piped})
+		cur.Reset()
+		piped = nextPiped
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\\' && i+1 < len(content) && (content[i+1] == ';' || content[i+1] == '|'):
+			i++
+			cur.WriteByte(content[i])
+			continue
+		case c == '"' && !backtick:
+			quoted = !quoted
+		case c == '`' && !quoted:
+			backtick = !backtick
+		case quoted || backtick:
+		case c == ';':
+			push(false)
+			continue
+		case c == '|' && isPipe(content, i):
+			push(true)
+			continue
+		}
+		cur.WriteByte(c)
+	}
+	push(false)
+
+	res := segments[:0]
+	for _, s := range segments {
+		if s.Content != "" {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// isPipe returns true if the `|` at i is surrounded by whitespace or the content bounds.
+func isPipe(content string, i int) bool {
+	before := i == 0 || content[i-1] == ' ' || content[i-1] == '\n' || content[i-1] == '\t'
+	after := i+1 == len(content) || content[i+1] == ' ' || content[i+1] == '\n' || content[i+1] == '\t'
+	return before && after
+}
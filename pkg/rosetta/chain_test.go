@@ -0,0 +1,128 @@
+package rosetta
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitChain(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Segment
+	}{
+		{"single", "pom 25", []Segment{{Content: "pom 25"}}},
+		{"sequence", "pom 25; status", []Segment{{Content: "pom 25"}, {Content: "status"}}},
+		{"pipe", "quote | upper", []Segment{{Content: "quote"}, {Content: "upper", Piped: true}}},
+		{"mixed", "a;b | c; d", []Segment{{Content: "a"}, {Content: "b"}, {Content: "c", Piped: true}, {Content: "d"}}},
+		{"empty segments", "a;; ;b;", []Segment{{Content: "a"}, {Content: "b"}}},
+		{"quoted", `say "a; b | c"`, []Segment{{Content: `say "a; b | c"`}}},
+		{"code", "say `a;b`", []Segment{{Content: "say `a;b`"}}},
+		{"escaped", `say a\; b \| c`, []Segment{{Content: "say a; b | c"}}},
+		{"spoiler", "say ||secret||", []Segment{{Content: "say ||secret||"}}},
+		{"unspaced pipe", "say a|b", []Segment{{Content: "say a|b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SplitChain(tt.content))
+		})
+	}
+}
+
+type TestPipeCmd struct {
+	invoke string
+	out    string
+	inputs []string
+	piped  []bool
+}
+
+func (t *TestPipeCmd) GetInvokers() []string {
+	return []string{t.invoke}
+}
+
+func (t *TestPipeCmd) GetDescription() string {
+	return "pipe"
+}
+
+func (t *TestPipeCmd) GetUsage() string {
+	return ""
+}
+
+func (t *TestPipeCmd) GetGroup() string {
+	return "test"
+}
+
+func (t *TestPipeCmd) GetDomain() string {
+	return "test.pipe." + t.invoke
+}
+
+func (t *TestPipeCmd) GetSubPermissionRules() []SubPermission {
+	return nil
+}
+
+func (t *TestPipeCmd) IsExecutableInDM() bool {
+	return true
+}
+
+func (t *TestPipeCmd) Exec(ctx Context) error {
+	in, ok := ctx.GetPipedInput()
+	t.inputs = append(t.inputs, in)
+	t.piped = append(t.piped, ok)
+	if t.out == "" {
+		return nil
+	}
+	// responses are captured, since tests only pipe commands responding.
+	_, err := ctx.RespondEmbed(&discordgo.MessageEmbed{Title: t.out, Description: strings.ToUpper(in)})
+	return err
+}
+
+func TestRouter_Chain(t *testing.T) {
+	var errType ErrorType = -1
+	cfg := makeTestConfig()
+	cfg.AllowChaining = true
+	cfg.MaxChainLength = 3
+	cfg.OnError = func(_ Context, t ErrorType, _ error) { errType = t }
+	r, _ := NewRouter(cfg).(*router)
+
+	quote := &TestPipeCmd{invoke: "quote", out: "hello"}
+	echo := &TestPipeCmd{invoke: "echo", out: "world"}
+	upper := &TestPipeCmd{invoke: "upper"}
+	ping := &TestCmd{}
+	r.Register(quote)
+	r.Register(echo)
+	r.Register(upper)
+	r.Register(ping)
+
+	s := makeTestStateSession(t)
+	msg := &discordgo.Message{ID: "100", ChannelID: "10", GuildID: "1", Author: &discordgo.User{ID: "2"}}
+
+	// output of quote and echo is captured, since both are piped.
+	r.handleMessage(s, msg, nil, "!", "quote | echo | !upper", false)
+	assert.Equal(t, []bool{false}, quote.piped)
+	assert.Equal(t, []string{"hello"}, echo.inputs)
+	assert.Equal(t, []string{"world\nHELLO"}, upper.inputs)
+
+	// sequenced commands don't receive input and the chain stops on the first failure.
+	ping.fail = true
+	r.handleMessage(s, msg, nil, "!", "upper; !ping; quote | upper", false)
+	assert.True(t, ping.executed)
+	assert.Equal(t, ErrTypeCommandExec, errType)
+	assert.Equal(t, []bool{true, false}, upper.piped)
+	assert.Len(t, quote.inputs, 1)
+
+	// segments exceeding MaxChainLength are dropped.
+	ping.fail = false
+	r.handleMessage(s, msg, nil, "!", "upper; upper; upper; upper", false)
+	assert.Len(t, upper.inputs, 5)
+
+	// chaining is opt-in, thus free text arguments keep their separators by default.
+	assert.False(t, NewDefaultConfig().AllowChaining)
+	cfg.AllowChaining = false
+	errType = -1
+	r.handleMessage(s, msg, nil, "!", "upper; upper", false)
+	assert.Len(t, upper.inputs, 5)
+	assert.Equal(t, ErrTypeCommandNotFound, errType)
+}
@@ -0,0 +1,187 @@
+package rosetta
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// permissionNames lists the names of Discord permissions as shown in the client, in bit order.
+var permissionNames = []struct {
+	bit  int64
+	name string
+}{
+	{discordgo.PermissionCreateInstantInvite, "Create Invite"},
+	{discordgo.PermissionKickMembers, "Kick Members"},
+	{discordgo.PermissionBanMembers, "Ban Members"},
+	{discordgo.PermissionAdministrator, "Administrator"},
+	{discordgo.PermissionManageChannels, "Manage Channels"},
+	{discordgo.PermissionManageServer, "Manage Server"},
+	{discordgo.PermissionAddReactions, "Add Reactions"},
+	{discordgo.PermissionViewAuditLogs, "View Audit Log"},
+	{discordgo.PermissionVoicePrioritySpeaker, "Priority Speaker"},
+	{discordgo.PermissionViewChannel, "View Channel"},
+	{discordgo.PermissionSendMessages, "Send Messages"},
+	{discordgo.PermissionSendTTSMessages, "Send TTS Messages"},
+	{discordgo.PermissionManageMessages, "Manage Messages"},
+	{discordgo.PermissionEmbedLinks, "Embed Links"},
+	{discordgo.PermissionAttachFiles, "Attach Files"},
+	{discordgo.PermissionReadMessageHistory, "Read Message History"},
+	{discordgo.PermissionMentionEveryone, "Mention Everyone"},
+	{discordgo.PermissionUseExternalEmojis, "Use External Emojis"},
+	{discordgo.PermissionVoiceConnect, "Connect"},
+	{discordgo.PermissionVoiceSpeak, "Speak"},
+	{discordgo.PermissionVoiceMuteMembers, "Mute Members"},
+	{discordgo.PermissionVoiceDeafenMembers, "Deafen Members"},
+	{discordgo.PermissionVoiceMoveMembers, "Move Members"},
+	{discordgo.PermissionVoiceUseVAD, "Use Voice Activity"},
+	{discordgo.PermissionChangeNickname, "Change Nickname"},
+	{discordgo.PermissionManageNicknames, "Manage Nicknames"},
+	{discordgo.PermissionManageRoles, "Manage Roles"},
+	{discordgo.PermissionManageWebhooks, "Manage Webhooks"},
+	{discordgo.PermissionManageEmojis, "Manage Emojis"},
+}
+
+// PermissionNames returns the names of given permissions as shown in the Discord client.
+func PermissionNames(perms int64) []string {
+	res := make([]string, 0)
+	for _, p := range permissionNames {
+		if perms&p.bit != 0 {
+			res = append(res, p.name)
+		}
+	}
+	return res
+}
+
+// MissingPermissionsError is returned when the invoking member or the bot lacks permissions
+// required by a PermissionsCommand.
+type MissingPermissionsError struct {
+	Missing int64
+	Bot     bool
+}
+
+func (e *MissingPermissionsError) Error() string {
+	return e.Unwrap().Error() + ": " + strings.Join(PermissionNames(e.Missing), ", ")
+}
+
+func (e *MissingPermissionsError) Unwrap() error {
+	if e.Bot {
+		return ErrBotMissingPermissions
+	}
+	return ErrMissingPermissions
+}
+
+// ChannelPermissions returns the permissions of a member with given user ID and roles in
+// given channel of given guild. Permissions of the roles are combined and the overwrites
+// of the channel applied. If channel is nil, the guild permissions are returned.
+func ChannelPermissions(guild *discordgo.Guild, channel *discordgo.Channel, userID string, roles []string) int64 {
+	perms := GuildPermissions(guild, userID, roles)
+	if channel == nil || perms&discordgo.PermissionAdministrator != 0 {
+		return perms
+	}
+
+	// overwrites apply in order of @everyone, roles and member.
+	var allow, deny int64
+	var member *discordgo.PermissionOverwrite
+	for _, o := range channel.PermissionOverwrites {
+		switch {
+		case o.Type == discordgo.PermissionOverwriteTypeRole && o.ID == guild.ID:
+			perms = perms&^o.Deny | o.Allow
+		case o.Type == discordgo.PermissionOverwriteTypeRole && arrayContains(roles, o.ID, false):
+			allow, deny = allow|o.Allow, deny|o.Deny
+		case o.Type == discordgo.PermissionOverwriteTypeMember && o.ID == userID:
+			member = o
+		}
+	}
+	perms = perms&^deny | allow
+	if member != nil {
+		perms = perms&^member.Deny | member.Allow
+	}
+
+	// members who can't view a channel have no permissions in it.
+	if perms&discordgo.PermissionViewChannel == 0 {
+		return 0
+	}
+	return perms
+}
+
+// checkCommand checks the restrictions given command declares by GuildOnlyCommand,
+// OwnerOnlyCommand, NSFWCommand and PermissionsCommand. The ErrorType and error of the
+// first failed check are returned.
+func (r *router) checkCommand(cmd Command, ctx Context) (ErrorType, error) {
+	guild, channel := ctx.GetGuild(), ctx.GetChannel()
+	inGuild := guild != nil && !ctx.IsDM()
+
+	if c, ok := cmd.(GuildOnlyCommand); ok && c.IsGuildOnly() && !inGuild {
+		return ErrTypeGuildOnly, ErrGuildOnly
+	}
+	if c, ok := cmd.(OwnerOnlyCommand); ok && c.IsOwnerOnly() && !arrayContains(r.config.OwnerIDs, ctx.GetUser().ID, false) {
+		return ErrTypeOwnerOnly, ErrOwnerOnly
+	}
+	if c, ok := cmd.(NSFWCommand); ok && c.IsNSFW() && (channel == nil || !channel.NSFW) {
+		return ErrTypeNSFWOnly, ErrNSFWOnly
+	}
+
+	c, ok := cmd.(PermissionsCommand)
+	if !ok || !inGuild {
+		return 0, nil
+	}
+	if required := c.GetMemberPermissions(); required != 0 {
+		var roles []string
+		if m := ctx.GetMember(); m != nil {
+			roles = m.Roles
+		}
+		if missing := required &^ ChannelPermissions(guild, channel, ctx.GetUser().ID, roles); missing != 0 {
+			return ErrTypeMissingPermissions, &MissingPermissionsError{Missing: missing}
+		}
+	}
+	if required := c.GetBotPermissions(); required != 0 {
+		bot, err := botMember(ctx.GetSession(), guild.ID)
+		if err != nil {
+			return ErrTypeGetGuild, err
+		}
+		if missing := required &^ ChannelPermissions(guild, channel, bot.User.ID, bot.Roles); missing != 0 {
+			return ErrTypeBotMissingPermissions, &MissingPermissionsError{Missing: missing, Bot: true}
+		}
+	}
+	return 0, nil
+}
+
+// respondCheckFailed tells the invoking user why a check of checkCommand failed.
+func respondCheckFailed(ctx Context, err error) {
+	var msg string
+	switch {
+	case err == ErrGuildOnly:
+		msg = ctx.T("rosetta.checks.guild_only")
+	case err == ErrOwnerOnly:
+		msg = ctx.T("rosetta.checks.owner_only")
+	case err == ErrNSFWOnly:
+		msg = ctx.T("rosetta.checks.nsfw_only")
+	}
+
+	if perr, ok := err.(*MissingPermissionsError); ok {
+		key := "rosetta.checks.missing_permissions"
+		if perr.Bot {
+			key = "rosetta.checks.bot_missing_permissions"
+		}
+		msg = ctx.T(key) + "\n- " + strings.Join(PermissionNames(perr.Missing), "\n- ")
+
+		// embeds can't be sent without their permission.
+		if perr.Bot && perr.Missing&discordgo.PermissionEmbedLinks != 0 {
+			_, _ = ctx.RespondText(msg)
+			return
+		}
+	}
+	if msg != "" {
+		_, _ = ctx.RespondEmbed(&discordgo.MessageEmbed{Description: msg, Color: EmbedColorError})
+	}
+}
+
+// botMember returns the member of our bot user in given guild.
+func botMember(s *discordgo.Session, guildID string) (*discordgo.Member, error) {
+	id := s.State.User.ID
+	if m, err := s.State.Member(guildID, id); err == nil {
+		return m, nil
+	}
+	return s.GuildMember(guildID, id)
+}
@@ -0,0 +1,93 @@
+package rosetta_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+func TestRouter_Checks(t *testing.T) {
+	h, user := makeHarness(t, nil)
+	h.Router.GetConfig().OwnerIDs = []string{"3"}
+	cmd := &testCmd{}
+	// reset replaces the checks of our command, which responds with `checked` if they pass.
+	reset := func(c testCmd) {
+		c.invoke = "checked"
+		c.exec = func(ctx rosetta.Context) error {
+			_, err := ctx.RespondText("checked")
+			return err
+		}
+		*cmd = c
+	}
+	reset(testCmd{})
+	h.Router.Register(cmd)
+	h.AddRole("1", &discordgo.Role{ID: "mod", Permissions: discordgo.PermissionManageMessages})
+	h.AddMember(&discordgo.Member{GuildID: "1", User: h.Session.State.User})
+	h.AddChannel(&discordgo.Channel{ID: "11", GuildID: "1", Type: discordgo.ChannelTypeGuildText, NSFW: true})
+	owner := &discordgo.User{ID: "3", Username: "owner"}
+	h.AddMember(&discordgo.Member{GuildID: "1", User: owner, Roles: []string{"mod"}})
+
+	tests := []struct {
+		name    string
+		setup   func()
+		author  *discordgo.User
+		channel string
+		errType rosetta.ErrorType
+		reply   string
+	}{
+		{"guild only", func() { cmd.guildOnly = true }, user, "20", rosetta.ErrTypeGuildOnly, "can only be used in a server"},
+		{"owner only", func() { cmd.ownerOnly = true }, user, "10", rosetta.ErrTypeOwnerOnly, "owners of this bot"},
+		{"nsfw", func() { cmd.nsfw = true }, user, "10", rosetta.ErrTypeNSFWOnly, "NSFW channels"},
+		{"member permissions", func() { cmd.member = discordgo.PermissionManageMessages | discordgo.PermissionBanMembers }, user, "10",
+			rosetta.ErrTypeMissingPermissions, "You are missing the following permissions in this channel:\n- Ban Members\n- Manage Messages"},
+		{"bot permissions", func() { cmd.bot = discordgo.PermissionAddReactions }, owner, "10",
+			rosetta.ErrTypeBotMissingPermissions, "I am missing the following permissions in this channel:\n- Add Reactions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset(testCmd{})
+			tt.setup()
+			h.Reset()
+			h.Send(tt.author, tt.channel, "r!checked")
+			h.ExpectError(tt.errType)
+			a := h.Last()
+			require.NotNil(t, a)
+			require.Len(t, a.Embeds, 1)
+			assert.Contains(t, a.Embed().Description, tt.reply)
+		})
+	}
+
+	// the bot can't send embeds without permission to.
+	reset(testCmd{bot: discordgo.PermissionEmbedLinks})
+	h.Reset()
+	h.Send(user, "10", "r!checked")
+	h.ExpectError(rosetta.ErrTypeBotMissingPermissions)
+	h.ExpectText("I am missing the following permissions in this channel:\n- Embed Links")
+
+	reset(testCmd{guildOnly: true, ownerOnly: true, nsfw: true, member: discordgo.PermissionManageMessages, bot: discordgo.PermissionSendMessages})
+	h.Reset()
+	h.Send(owner, "11", "r!checked")
+	h.ExpectNoErrors()
+	h.ExpectText("checked")
+
+	// help hides commands failing their checks.
+	groupText := func(author *discordgo.User) string {
+		h.Reset()
+		h.Send(author, "10", "r!help fun")
+		a := h.Last()
+		require.NotNil(t, a)
+		var b strings.Builder
+		for _, f := range a.Embed().Fields {
+			b.WriteString(f.Value)
+		}
+		return b.String()
+	}
+	reset(testCmd{ownerOnly: true})
+	assert.NotContains(t, groupText(user), "`checked`")
+	assert.Contains(t, groupText(owner), "`checked`")
+}
@@ -0,0 +1,59 @@
+package rosetta
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelPermissions(t *testing.T) {
+	guild := &discordgo.Guild{
+		ID:      "1",
+		OwnerID: "owner",
+		Roles: []*discordgo.Role{
+			{ID: "1", Permissions: discordgo.PermissionViewChannel | discordgo.PermissionSendMessages},
+			{ID: "mod", Permissions: discordgo.PermissionManageMessages},
+			{ID: "admin", Permissions: discordgo.PermissionAdministrator},
+		},
+	}
+	channel := &discordgo.Channel{ID: "10", PermissionOverwrites: []*discordgo.PermissionOverwrite{
+		{ID: "1", Type: discordgo.PermissionOverwriteTypeRole, Deny: discordgo.PermissionSendMessages},
+		{ID: "mod", Type: discordgo.PermissionOverwriteTypeRole, Allow: discordgo.PermissionSendMessages},
+		{ID: "muted", Type: discordgo.PermissionOverwriteTypeMember, Deny: discordgo.PermissionSendMessages},
+		{ID: "hidden", Type: discordgo.PermissionOverwriteTypeMember, Deny: discordgo.PermissionViewChannel},
+	}}
+
+	tests := []struct {
+		name     string
+		channel  *discordgo.Channel
+		userID   string
+		roles    []string
+		expected int64
+	}{
+		{"guild", nil, "user", nil, discordgo.PermissionViewChannel | discordgo.PermissionSendMessages},
+		{"everyone overwrite", channel, "user", nil, discordgo.PermissionViewChannel},
+		{"role overwrite", channel, "user", []string{"mod"}, discordgo.PermissionViewChannel | discordgo.PermissionSendMessages | discordgo.PermissionManageMessages},
+		{"member overwrite", channel, "muted", []string{"mod"}, discordgo.PermissionViewChannel | discordgo.PermissionManageMessages},
+		{"not viewable", channel, "hidden", []string{"mod"}, 0},
+		{"administrator", channel, "hidden", []string{"admin"}, discordgo.PermissionAll},
+		{"owner", channel, "owner", nil, discordgo.PermissionAll},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ChannelPermissions(guild, tt.channel, tt.userID, tt.roles))
+		})
+	}
+}
+
+func TestMissingPermissionsError(t *testing.T) {
+	perms := int64(discordgo.PermissionEmbedLinks | discordgo.PermissionBanMembers)
+	assert.Equal(t, []string{"Ban Members", "Embed Links"}, PermissionNames(perms))
+	assert.Empty(t, PermissionNames(0))
+
+	err := &MissingPermissionsError{Missing: perms}
+	assert.Equal(t, "member is missing permissions: Ban Members, Embed Links", err.Error())
+	assert.True(t, errors.Is(err, ErrMissingPermissions))
+	assert.True(t, errors.Is(&MissingPermissionsError{Missing: perms, Bot: true}, ErrBotMissingPermissions))
+}
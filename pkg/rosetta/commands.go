@@ -29,6 +29,63 @@ type LimitedConfig interface {
 	IsLimiterGlobal() bool
 }
 
+// ParentCommand defines command that holds a tree of child commands, e.g. `config prefix set`.
+// Each child is a fully-fledged Command with its own invokers, usage, group, domain and DM flag.
+type ParentCommand interface {
+
+	// GetSubCommands returns the direct children of given command.
+	// Router walks parsed arguments down this tree before dispatching.
+	GetSubCommands() []Command
+}
+
+// TimeoutCommand defines command that overrides Config.CommandTimeout.
+type TimeoutCommand interface {
+
+	// GetTimeout returns the duration after which the context of given command is cancelled.
+	// A zero value disables the timeout.
+	GetTimeout() time.Duration
+}
+
+// HiddenCommand defines command that can be executed but isn't listed by DefaultHelpCommand.
+type HiddenCommand interface {
+
+	// IsHidden returns true if command shall not be listed in help.
+	IsHidden() bool
+}
+
+// GuildOnlyCommand defines command that can only be executed in guild channels.
+type GuildOnlyCommand interface {
+
+	// IsGuildOnly returns true if command requires a guild.
+	IsGuildOnly() bool
+}
+
+// OwnerOnlyCommand defines command that can only be executed by bot owners, see Config.OwnerIDs.
+type OwnerOnlyCommand interface {
+
+	// IsOwnerOnly returns true if command is restricted to bot owners.
+	IsOwnerOnly() bool
+}
+
+// NSFWCommand defines command that can only be executed in channels marked as NSFW.
+type NSFWCommand interface {
+
+	// IsNSFW returns true if command requires a NSFW channel.
+	IsNSFW() bool
+}
+
+// PermissionsCommand defines command that requires Discord permissions in the invoking channel.
+// Permissions are computed from guild roles and channel overwrites.
+type PermissionsCommand interface {
+
+	// GetMemberPermissions returns the permissions the invoking member needs, e.g.
+	// discordgo.PermissionManageMessages.
+	GetMemberPermissions() int64
+
+	// GetBotPermissions returns the permissions the bot needs to execute command.
+	GetBotPermissions() int64
+}
+
 // SubPermission wraps information about a command sub permission.
 type SubPermission struct {
 	Term        string `json:"term"`
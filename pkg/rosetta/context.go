@@ -1,7 +1,9 @@
 package rosetta
 
 import (
+	gocontext "context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/bwmarrin/discordgo"
@@ -15,9 +17,17 @@ type Context interface {
 	// GetSession returns our instance of discordgo.Session.
 	GetSession() *discordgo.Session
 
+	// GetContext returns a context.Context which is cancelled when the command times out,
+	// its invoking message is deleted or the router shuts down.
+	GetContext() gocontext.Context
+
 	// GetArguments returns our Arguments list and parsed Command Arguments.
 	GetArguments() *Arguments
 
+	// GetParams returns arguments bound to the command Schema. Returns nil
+	// if command doesn't implement SchemaCommand.
+	GetParams() *Params
+
 	// GetChannel returns the channel where message is sent.
 	GetChannel() *discordgo.Channel
 
@@ -40,6 +50,23 @@ type Context interface {
 	// IsEdit returns true if event is a *discordgo.MessageUpdate event.
 	IsEdit() bool
 
+	// GetInteraction returns the interaction when command was invoked as a slash command,
+	// nil otherwise. GetMessage will then return a message built from the interaction.
+	GetInteraction() *Interaction
+
+	// GetPipedInput returns the text output of the previous command if this command is
+	// piped, e.g. `r!quote | r!upper`. Commands opt in to piping by reading it, false is
+	// returned if nothing was piped.
+	GetPipedInput() (string, bool)
+
+	// GetLocale returns the locale responses are translated to, i.e. the locale of the invoking
+	// user, else the one of the guild, else the fallback locale of Config.Translations.
+	GetLocale() string
+
+	// T returns the message of given key translated to GetLocale and formatted with args.
+	// If no catalog contains key, the key itself is returned.
+	T(key string, args ...interface{}) string
+
 	// RespondText wraps around responses of given text message.
 	RespondText(content string) (*discordgo.Message, error)
 
@@ -48,20 +75,54 @@ type Context interface {
 
 	// RespondEmbedError responds with the given error in a embed message.
 	RespondEmbedError(title string, err error) (*discordgo.Message, error)
+
+	// RespondTextEmbed responds with given text and embed in a single message.
+	RespondTextEmbed(content string, embed *discordgo.MessageEmbed) (*discordgo.Message, error)
+
+	// RespondTextEmbedError responds with given text and error in a embed message.
+	RespondTextEmbedError(content, title string, err error) (*discordgo.Message, error)
+
+	// Respond returns a Response builder for replies, attachments, allowed mentions and DMs.
+	Respond() *Response
 }
 
 // context is our default implementation of Context.
 type context struct {
-	isDM      bool
-	isEdit    bool
-	router    Router
-	args      *Arguments
-	objectMap *sync.Map
-	session   *discordgo.Session
-	message   *discordgo.Message
-	guild     *discordgo.Guild
-	channel   *discordgo.Channel
-	member    *discordgo.Member
+	isDM   bool
+	isEdit bool
+	router Router
+	ctx    gocontext.Context
+	args   *Arguments
+
+	// base is cancelled when the invoking message is deleted, command contexts derive from it.
+	base gocontext.Context
+
+	params *Params
+	invoke string
+	root   Command
+	cmd    Command
+	event  interface{}
+
+	// replies of this run and of the previous run if an edit re-triggered the command.
+	replies     []trackedReply
+	prevReplies []trackedReply
+	objectMap   *sync.Map
+	session     *discordgo.Session
+	message     *discordgo.Message
+	guild       *discordgo.Guild
+	channel     *discordgo.Channel
+	member      *discordgo.Member
+
+	interaction *Interaction
+	responded   bool
+
+	// locale is resolved lazily by GetLocale.
+	locale string
+
+	// pipeIn is the output of the previous chain segment, pipeOut captures responses
+	// which are piped into the next segment instead of being sent.
+	pipeIn  *string
+	pipeOut *strings.Builder
 }
 
 func (c *context) GetObject(key string) (value interface{}) {
@@ -88,6 +149,17 @@ func (c *context) GetArguments() *Arguments {
 	return c.args
 }
 
+func (c *context) GetContext() gocontext.Context {
+	if c.ctx == nil {
+		return gocontext.Background()
+	}
+	return c.ctx
+}
+
+func (c *context) GetParams() *Params {
+	return c.params
+}
+
 func (c *context) GetChannel() *discordgo.Channel {
 	return c.channel
 }
@@ -116,14 +188,69 @@ func (c *context) IsEdit() bool {
 	return c.isEdit
 }
 
+func (c *context) GetInteraction() *Interaction {
+	return c.interaction
+}
+
+func (c *context) GetPipedInput() (string, bool) {
+	if c.pipeIn == nil {
+		return "", false
+	}
+	return *c.pipeIn, true
+}
+
 func (c *context) RespondText(content string) (*discordgo.Message, error) {
-	return c.session.ChannelMessageSend(c.channel.ID, content)
+	return c.Respond().Text(content).Send()
 }
 
 func (c *context) RespondEmbed(embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
-	return c.session.ChannelMessageSendEmbed(c.channel.ID, embed)
+	return c.Respond().Embed(embed).Send()
+}
+
+func (c *context) RespondTextEmbed(content string, embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
+	return c.Respond().Text(content).Embed(embed).Send()
 }
 
 func (c *context) RespondEmbedError(title string, err error) (*discordgo.Message, error) {
-	return c.session.ChannelMessageSendEmbed(c.channel.ID, &discordgo.MessageEmbed{Title: title, Description: fmt.Sprintf("*%s*", err.Error()), Color: EmbedColorError})
+	return c.RespondEmbed(errorEmbed(title, err))
+}
+
+func (c *context) RespondTextEmbedError(content, title string, err error) (*discordgo.Message, error) {
+	return c.RespondTextEmbed(content, errorEmbed(title, err))
+}
+
+func errorEmbed(title string, err error) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{Title: title, Description: fmt.Sprintf("*%s*", err.Error()), Color: EmbedColorError}
+}
+
+// domain returns the domain of the dispatched command, empty if none.
+func (c *context) domain() string {
+	if c == nil || c.cmd == nil {
+		return ""
+	}
+	return c.cmd.GetDomain()
+}
+
+// capture appends given content to the output piped into the next segment and returns
+// a message which wasn't sent.
+func (c *context) capture(content string) *discordgo.Message {
+	if c.pipeOut.Len() > 0 {
+		c.pipeOut.WriteByte('\n')
+	}
+	c.pipeOut.WriteString(content)
+	return &discordgo.Message{ChannelID: c.channel.ID, Content: content, Author: c.message.Author}
+}
+
+// respondInteraction fills the deferred interaction response first, following responses
+// are sent as followup messages.
+func (c *context) respondInteraction(data *InteractionResponseData) (*discordgo.Message, error) {
+	if !c.responded {
+		// the deferred response is only filled once the edit succeeded, so a retry edits it again.
+		msg, err := InteractionResponseEdit(c.session, c.interaction, data)
+		if err == nil {
+			c.responded = true
+		}
+		return msg, err
+	}
+	return FollowupMessageCreate(c.session, c.interaction, data)
 }
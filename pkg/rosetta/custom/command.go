@@ -0,0 +1,246 @@
+package custom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+// Command returns admin command to create, edit, list and delete custom commands stored in c.
+func (c *Custom) Command() rosetta.Command {
+	return &customCommand{
+		c: c,
+		subs: []rosetta.Command{
+			&setCommand{c: c, create: true},
+			&setCommand{c: c, create: false},
+			&deleteCommand{c: c},
+		},
+	}
+}
+
+type customCommand struct {
+	c    *Custom
+	subs []rosetta.Command
+}
+
+func (cc *customCommand) GetInvokers() []string {
+	return []string{"customcommands", "custom", "cc"}
+}
+
+func (cc *customCommand) GetDescription() string {
+	return "manage custom text commands of this guild"
+}
+
+func (cc *customCommand) GetUsage() string {
+	return "`cc` - list custom commands and template variables\n" +
+		"`cc add <name> <response...>` - create a custom command, quote the response to keep `;` and `|`\n" +
+		"`cc edit <name> <response...>` - change the response of a custom command\n" +
+		"`cc delete <name>` - delete a custom command"
+}
+
+func (cc *customCommand) GetGroup() string {
+	return rosetta.GroupGuildConfig
+}
+
+func (cc *customCommand) GetDomain() string {
+	return "rs.guild.config.custom"
+}
+
+func (cc *customCommand) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (cc *customCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (cc *customCommand) GetSubCommands() []rosetta.Command {
+	return cc.subs
+}
+
+func (cc *customCommand) Exec(ctx rosetta.Context) error {
+	cmds, err := cc.c.p.GetCommands(ctx.GetGuild().ID)
+	if err != nil {
+		return err
+	}
+
+	names := ctx.T("rosetta.custom.none")
+	if len(cmds) > 0 {
+		n := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			n[i] = cmd.Name
+		}
+		names = "`" + strings.Join(n, "` `") + "`"
+	}
+
+	vars := make([]string, 0, len(Variables))
+	for v, desc := range Variables {
+		vars = append(vars, fmt.Sprintf("`%s` - %s", v, desc))
+	}
+	sort.Strings(vars)
+
+	_, err = ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title:       ctx.T("rosetta.custom.title"),
+		Description: cc.GetUsage(),
+		Color:       rosetta.EmbedColorDefault,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: ctx.T("rosetta.custom.commands"), Value: names},
+			{Name: ctx.T("rosetta.custom.variables"), Value: strings.Join(vars, "\n")},
+		},
+	})
+	return err
+}
+
+// setCommand creates or edits a custom command.
+type setCommand struct {
+	c      *Custom
+	create bool
+}
+
+func (s *setCommand) GetInvokers() []string {
+	if s.create {
+		return []string{"add", "create"}
+	}
+	return []string{"edit", "set"}
+}
+
+func (s *setCommand) GetDescription() string {
+	if s.create {
+		return "create a custom command"
+	}
+	return "change the response of a custom command"
+}
+
+func (s *setCommand) GetUsage() string {
+	return ""
+}
+
+func (s *setCommand) GetGroup() string {
+	return rosetta.GroupGuildConfig
+}
+
+func (s *setCommand) GetDomain() string {
+	return "rs.guild.config.custom." + s.GetInvokers()[0]
+}
+
+func (s *setCommand) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (s *setCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (s *setCommand) GetSchema() *rosetta.Schema {
+	return rosetta.NewSchema().
+		String("name", "name of the command").
+		String("response", "response template").Rest()
+}
+
+func (s *setCommand) Exec(ctx rosetta.Context) error {
+	if ok, err := rosetta.RequireGuildManager(ctx); !ok {
+		return err
+	}
+
+	// quoted arguments are bound without their quotes, thus responses can contain `;` and `|`
+	// while chaining is enabled.
+	guildID, name := ctx.GetGuild().ID, strings.ToLower(ctx.GetParams().String("name"))
+	response := ctx.GetParams().String("response")
+
+	cmd, err := s.c.p.GetCommand(guildID, name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case s.create && cmd != nil:
+		err = ErrNameTaken
+	case !s.create && cmd == nil:
+		err = ErrNotFound
+	case s.create:
+		rr, _ := ctx.GetObject(rosetta.ObjectMapKeyRouter).(rosetta.Router)
+		err = validateName(rr, name)
+	}
+	if err != nil {
+		_, err = ctx.RespondEmbedError(ctx.T("rosetta.custom."+s.GetInvokers()[0]+"_failed", name), err)
+		return err
+	}
+
+	if cmd == nil {
+		cmd = &Command{Name: name, CreatedBy: ctx.GetUser().ID}
+	}
+	cmd.Response = response
+	if err = s.c.p.SetCommand(guildID, cmd); err != nil {
+		return err
+	}
+	_, err = ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title:       ctx.T("rosetta.custom.title"),
+		Description: ctx.T("rosetta.custom.saved", name),
+		Color:       rosetta.EmbedColorDefault,
+	})
+	return err
+}
+
+type deleteCommand struct {
+	c *Custom
+}
+
+func (d *deleteCommand) GetInvokers() []string {
+	return []string{"delete", "remove", "rm"}
+}
+
+func (d *deleteCommand) GetDescription() string {
+	return "delete a custom command"
+}
+
+func (d *deleteCommand) GetUsage() string {
+	return ""
+}
+
+func (d *deleteCommand) GetGroup() string {
+	return rosetta.GroupGuildConfig
+}
+
+func (d *deleteCommand) GetDomain() string {
+	return "rs.guild.config.custom.delete"
+}
+
+func (d *deleteCommand) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (d *deleteCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (d *deleteCommand) GetSchema() *rosetta.Schema {
+	return rosetta.NewSchema().String("name", "name of the command")
+}
+
+func (d *deleteCommand) Exec(ctx rosetta.Context) error {
+	if ok, err := rosetta.RequireGuildManager(ctx); !ok {
+		return err
+	}
+
+	guildID, name := ctx.GetGuild().ID, strings.ToLower(ctx.GetParams().String("name"))
+	cmd, err := d.c.p.GetCommand(guildID, name)
+	if err != nil {
+		return err
+	}
+	if cmd == nil {
+		_, err = ctx.RespondEmbedError(ctx.T("rosetta.custom.delete_failed", name), ErrNotFound)
+		return err
+	}
+	if err = d.c.p.DeleteCommand(guildID, name); err != nil {
+		return err
+	}
+	_, err = ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title:       ctx.T("rosetta.custom.title"),
+		Description: ctx.T("rosetta.custom.deleted", name),
+		Color:       rosetta.EmbedColorDefault,
+	})
+	return err
+}
@@ -0,0 +1,121 @@
+// Package custom provides text commands which guild admins define at runtime, e.g. FAQ
+// answers. Responses are templates which can use variables like `{user}` or `{random:a|b}`.
+package custom
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+// Group is the group custom commands are listed under.
+const Group = "CUSTOM"
+
+var (
+	// ErrInvalidName is thrown when a command name has an invalid format.
+	ErrInvalidName = errors.New("invalid command name")
+
+	// ErrNameTaken is thrown when a command name is used by a built-in command.
+	ErrNameTaken = errors.New("command name is already taken")
+
+	// ErrNotFound is thrown when a custom command doesn't exist.
+	ErrNotFound = errors.New("custom command not found")
+
+	nameRegex = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+)
+
+// Command is a custom text command of a guild.
+type Command struct {
+	Name      string `json:"name"`
+	Response  string `json:"response"`
+	CreatedBy string `json:"created_by"`
+}
+
+// Custom implements rosetta.GuildCommandSource serving the commands stored in its Provider.
+type Custom struct {
+	p Provider
+}
+
+// New returns a new instance of Custom. If no provider is given, commands are kept in memory.
+func New(p Provider) *Custom {
+	if p == nil {
+		p = NewMemoryProvider()
+	}
+	return &Custom{p: p}
+}
+
+// GetProvider returns the Provider commands are stored in.
+func (c *Custom) GetProvider() Provider {
+	return c.p
+}
+
+func (c *Custom) GetGuildCommand(guildID, invoke string) (rosetta.Command, bool, error) {
+	cmd, err := c.p.GetCommand(guildID, strings.ToLower(invoke))
+	if err != nil || cmd == nil {
+		return nil, false, err
+	}
+	return &textCommand{cmd: cmd}, true, nil
+}
+
+func (c *Custom) GetGuildCommands(guildID string) ([]rosetta.Command, error) {
+	cmds, err := c.p.GetCommands(guildID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]rosetta.Command, len(cmds))
+	for i, cmd := range cmds {
+		res[i] = &textCommand{cmd: cmd}
+	}
+	return res, nil
+}
+
+// textCommand wraps a custom command as rosetta.Command.
+type textCommand struct {
+	cmd *Command
+}
+
+func (t *textCommand) GetInvokers() []string {
+	return []string{t.cmd.Name}
+}
+
+func (t *textCommand) GetDescription() string {
+	return "custom command"
+}
+
+func (t *textCommand) GetUsage() string {
+	return "`" + t.cmd.Name + "`"
+}
+
+func (t *textCommand) GetGroup() string {
+	return Group
+}
+
+func (t *textCommand) GetDomain() string {
+	return "rs.chat.custom." + t.cmd.Name
+}
+
+func (t *textCommand) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (t *textCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (t *textCommand) Exec(ctx rosetta.Context) error {
+	_, err := ctx.RespondText(Render(t.cmd.Response, ctx))
+	return err
+}
+
+// validateName returns an error if name can't be used for a custom command.
+func validateName(rr rosetta.Router, name string) error {
+	if !nameRegex.MatchString(name) {
+		return ErrInvalidName
+	}
+	if _, ok := rr.GetCommand(name); ok {
+		return ErrNameTaken
+	}
+	return nil
+}
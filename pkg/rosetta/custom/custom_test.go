@@ -0,0 +1,90 @@
+package custom
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/rosettatest"
+)
+
+func TestCustom_GuildCommands(t *testing.T) {
+	c := New(nil)
+	require.NoError(t, c.GetProvider().SetCommand("1", &Command{Name: "rules", Response: "be nice"}))
+	require.NoError(t, c.GetProvider().SetCommand("1", &Command{Name: "faq", Response: "{user} read it"}))
+
+	cmd, ok, err := c.GetGuildCommand("1", "FAQ")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"faq"}, cmd.GetInvokers())
+	assert.Equal(t, Group, cmd.GetGroup())
+	assert.Equal(t, "rs.chat.custom.faq", cmd.GetDomain())
+
+	_, ok, _ = c.GetGuildCommand("2", "faq")
+	assert.False(t, ok)
+
+	cmds, err := c.GetGuildCommands("1")
+	assert.NoError(t, err)
+	require.Len(t, cmds, 2)
+	assert.Equal(t, "faq", cmds[0].GetInvokers()[0])
+
+	require.NoError(t, c.GetProvider().DeleteCommand("1", "faq"))
+	_, ok, _ = c.GetGuildCommand("1", "faq")
+	assert.False(t, ok)
+}
+
+func TestValidateName(t *testing.T) {
+	cfg := rosetta.NewDefaultConfig()
+	cfg.UseSlashCommands = false
+	rr := rosetta.NewRouter(cfg)
+
+	assert.NoError(t, validateName(rr, "faq"))
+	assert.NoError(t, validateName(rr, "read-the_docs2"))
+	assert.Equal(t, ErrInvalidName, validateName(rr, "FAQ"))
+	assert.Equal(t, ErrInvalidName, validateName(rr, ""))
+	assert.Equal(t, ErrInvalidName, validateName(rr, "a b"))
+	assert.Equal(t, ErrNameTaken, validateName(rr, "help"))
+}
+
+func TestCustom_Manage(t *testing.T) {
+	c := New(nil)
+	cfg := rosetta.NewDefaultConfig()
+	cfg.OnError = nil
+	cfg.AllowChaining = true
+	h := rosettatest.New(t, cfg)
+	h.Router.Register(c)
+	h.Router.Register(c.Command())
+	h.AddGuild(&discordgo.Guild{ID: "1"})
+	h.AddRole("1", &discordgo.Role{ID: "1", Permissions: discordgo.PermissionViewChannel | discordgo.PermissionSendMessages})
+	h.AddRole("1", &discordgo.Role{ID: "manager", Permissions: discordgo.PermissionManageServer})
+	h.AddChannel(&discordgo.Channel{ID: "10", GuildID: "1", Type: discordgo.ChannelTypeGuildText})
+	user, manager := &discordgo.User{ID: "2"}, &discordgo.User{ID: "3"}
+	h.AddMember(&discordgo.Member{GuildID: "1", User: user})
+	h.AddMember(&discordgo.Member{GuildID: "1", User: manager, Roles: []string{"manager"}})
+
+	// only members managing the guild can change custom commands.
+	for _, content := range []string{"r!cc add faq a", "r!cc delete faq"} {
+		h.Reset()
+		h.Send(user, "10", content)
+		h.ExpectNoErrors()
+		assert.Contains(t, h.Last().Embed().Description, "Manage Server", content)
+	}
+	cmds, _ := c.GetGuildCommands("1")
+	assert.Empty(t, cmds)
+
+	// quoted responses keep separators of chained commands.
+	h.Send(manager, "10", `r!cc add faq "read #rules; be nice | thanks"`)
+	h.ExpectNoErrors()
+	cmd, err := c.GetProvider().GetCommand("1", "faq")
+	require.NoError(t, err)
+	require.NotNil(t, cmd)
+	assert.Equal(t, "read #rules; be nice | thanks", cmd.Response)
+
+	h.Send(manager, "10", "r!cc delete faq")
+	h.ExpectNoErrors()
+	cmd, _ = c.GetProvider().GetCommand("1", "faq")
+	assert.Nil(t, cmd)
+}
@@ -0,0 +1,71 @@
+package custom
+
+import (
+	"sort"
+	"sync"
+)
+
+// Provider stores custom commands per guild.
+// This can be implemented to persist commands into a database.
+type Provider interface {
+
+	// GetCommand returns the command of given guild by name, nil if it doesn't exist.
+	GetCommand(guildID, name string) (*Command, error)
+
+	// GetCommands returns all commands of given guild.
+	GetCommands(guildID string) ([]*Command, error)
+
+	// SetCommand creates or replaces given command.
+	SetCommand(guildID string, cmd *Command) error
+
+	// DeleteCommand removes the command of given guild by name.
+	DeleteCommand(guildID, name string) error
+}
+
+type memoryProvider struct {
+	mu   sync.RWMutex
+	cmds map[string]map[string]*Command
+}
+
+// NewMemoryProvider returns a Provider which keeps commands in memory.
+func NewMemoryProvider() Provider {
+	return &memoryProvider{cmds: make(map[string]map[string]*Command)}
+}
+
+func (m *memoryProvider) GetCommand(guildID, name string) (*Command, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.cmds[guildID][name], nil
+}
+
+func (m *memoryProvider) GetCommands(guildID string) ([]*Command, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cmds := make([]*Command, 0, len(m.cmds[guildID]))
+	for _, c := range m.cmds[guildID] {
+		cmds = append(cmds, c)
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+	return cmds, nil
+}
+
+func (m *memoryProvider) SetCommand(guildID string, cmd *Command) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.cmds[guildID]; !ok {
+		m.cmds[guildID] = make(map[string]*Command)
+	}
+	m.cmds[guildID][cmd.Name] = cmd
+	return nil
+}
+
+func (m *memoryProvider) DeleteCommand(guildID, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.cmds[guildID], name)
+	return nil
+}
@@ -0,0 +1,110 @@
+package custom
+
+import (
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+// varRegex matches template variables like `{user}` or `{random:a|b}`.
+var varRegex = regexp.MustCompile(`\{([a-z.]+)(?::([^{}]*))?\}`)
+
+// Variables lists the variables a response template can use.
+var Variables = map[string]string{
+	"{user}":         "mention of the invoking user",
+	"{user.name}":    "name of the invoking user",
+	"{user.id}":      "ID of the invoking user",
+	"{channel}":      "mention of the channel",
+	"{channel.name}": "name of the channel",
+	"{guild}":        "name of the guild",
+	"{guild.id}":     "ID of the guild",
+	"{args}":         "all arguments",
+	"{arg:N}":        "N-th argument, starting at 1",
+	"{random:a|b|c}": "one random choice",
+}
+
+// templateData holds values template variables are resolved from.
+type templateData struct {
+	user    *discordgo.User
+	channel *discordgo.Channel
+	guild   *discordgo.Guild
+	args    []string
+}
+
+// Render resolves variables of given response template with values of ctx. Unknown
+// variables are kept as they are.
+func Render(template string, ctx rosetta.Context) string {
+	data := &templateData{
+		user:    ctx.GetUser(),
+		channel: ctx.GetChannel(),
+		guild:   ctx.GetGuild(),
+		args:    make([]string, 0),
+	}
+	if args := ctx.GetArguments(); args != nil {
+		for _, a := range args.Args() {
+			data.args = append(data.args, a.String())
+		}
+	}
+	return render(template, data)
+}
+
+func render(template string, data *templateData) string {
+	return varRegex.ReplaceAllStringFunc(template, func(v string) string {
+		m := varRegex.FindStringSubmatch(v)
+		if res, ok := data.resolve(m[1], m[2]); ok {
+			return res
+		}
+		return v
+	})
+}
+
+func (d *templateData) resolve(name, param string) (string, bool) {
+	base, field := name, ""
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		base, field = name[:i], name[i+1:]
+	}
+
+	switch {
+	case name == "args":
+		return strings.Join(d.args, " "), true
+	case name == "arg":
+		i, err := strconv.Atoi(param)
+		if err != nil {
+			return "", false
+		}
+		if i < 1 || i > len(d.args) {
+			return "", true
+		}
+		return d.args[i-1], true
+	case name == "random":
+		choices := strings.Split(param, "|")
+		return choices[rand.Intn(len(choices))], true
+	case base == "user" && d.user != nil:
+		return resolveField(field, d.user.Mention(), d.user.Username, d.user.ID)
+	case base == "channel" && d.channel != nil:
+		return resolveField(field, d.channel.Mention(), d.channel.Name, d.channel.ID)
+	case base == "guild" && d.guild != nil:
+		return resolveField(field, d.guild.Name, d.guild.Name, d.guild.ID)
+	default:
+		return "", false
+	}
+}
+
+// resolveField returns value if no field is given, otherwise the value of field `name` or `id`.
+func resolveField(field, value, name, id string) (string, bool) {
+	switch field {
+	case "":
+		return value, true
+	case "name":
+		return name, true
+	case "id":
+		return id, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,38 @@
+package custom
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	data := &templateData{
+		user:    &discordgo.User{ID: "1", Username: "iris"},
+		channel: &discordgo.Channel{ID: "2", Name: "general"},
+		guild:   &discordgo.Guild{ID: "3", Name: "iridaceae"},
+		args:    []string{"a", "b"},
+	}
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"user", "hi {user}, {user.name} ({user.id})", "hi <@1>, iris (1)"},
+		{"channel", "{channel} {channel.name}", "<#2> general"},
+		{"guild", "welcome to {guild} {guild.id}", "welcome to iridaceae 3"},
+		{"args", "{args} - {arg:2} {arg:1} {arg:3}", "a b - b a "},
+		{"random single", "{random:x}", "x"},
+		{"unknown", "{foo} {user.foo} {arg:x} {username}", "{foo} {user.foo} {arg:x} {username}"},
+		{"no variables", "read the #faq\nplease", "read the #faq\nplease"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, render(tt.template, data))
+		})
+	}
+
+	assert.Contains(t, []string{"a", "b", "c"}, render("{random:a|b|c}", data))
+	assert.Equal(t, "{user}", render("{user}", &templateData{}))
+}
@@ -0,0 +1,242 @@
+package rosetta
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const groupEntryPrefix = "group:"
+
+// DisabledProvider stores commands and groups which are disabled per guild.
+// This can be implemented to persist entries into a database.
+type DisabledProvider interface {
+
+	// GetDisabled returns disabled entries of given guild. An entry is either the
+	// lowercased primary invoker of a command or `group:` followed by a lowercased group.
+	GetDisabled(guildID string) ([]string, error)
+
+	// SetDisabled replaces disabled entries of given guild.
+	SetDisabled(guildID string, entries []string) error
+}
+
+type memoryDisabledProvider struct {
+	mu      sync.RWMutex
+	entries map[string][]string
+}
+
+// NewMemoryDisabledProvider returns a DisabledProvider which keeps entries in memory.
+func NewMemoryDisabledProvider() DisabledProvider {
+	return &memoryDisabledProvider{entries: make(map[string][]string)}
+}
+
+func (m *memoryDisabledProvider) GetDisabled(guildID string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]string{}, m.entries[guildID]...), nil
+}
+
+func (m *memoryDisabledProvider) SetDisabled(guildID string, entries []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[guildID] = entries
+	return nil
+}
+
+// getCommandEntry returns the disabled entry of given root command.
+func getCommandEntry(cmd Command) string {
+	return strings.ToLower(cmd.GetInvokers()[0])
+}
+
+// getGroupEntry returns the disabled entry of given group.
+func getGroupEntry(group string) string {
+	return groupEntryPrefix + strings.ToLower(group)
+}
+
+// isDisabled returns true if given command, its root or one of their groups is disabled in given guild.
+// DefaultCommandsCommand can't be disabled, so guilds can always revert.
+func (r *router) isDisabled(guildID string, root, cmd Command) (bool, error) {
+	if _, ok := root.(*DefaultCommandsCommand); ok {
+		return false, nil
+	}
+	entries, err := r.config.DisabledProvider.GetDisabled(guildID)
+	if err != nil || len(entries) == 0 {
+		return false, err
+	}
+	return arrayContains(entries, getCommandEntry(root), false) ||
+		arrayContains(entries, getGroupEntry(root.GetGroup()), false) ||
+		arrayContains(entries, getGroupEntry(cmd.GetGroup()), false), nil
+}
+
+// DefaultCommandsCommand lets guild admins disable and enable commands or whole groups in their guild.
+type DefaultCommandsCommand struct{}
+
+func (d *DefaultCommandsCommand) GetInvokers() []string {
+	return []string{"commands", "cmds"}
+}
+
+func (d *DefaultCommandsCommand) GetDescription() string {
+	return "disable or enable commands and groups in this guild"
+}
+
+func (d *DefaultCommandsCommand) GetUsage() string {
+	return "`commands` - list disabled commands and groups\n" +
+		"`commands disable <command|group>` - disable a command or group, e.g. `commands disable fun`\n" +
+		"`commands enable <command|group>` - enable a command or group again"
+}
+
+func (d *DefaultCommandsCommand) GetGroup() string {
+	return GroupGuildAdmin
+}
+
+func (d *DefaultCommandsCommand) GetDomain() string {
+	return "rs.guild.config.commands"
+}
+
+func (d *DefaultCommandsCommand) GetSubPermissionRules() []SubPermission {
+	return nil
+}
+
+func (d *DefaultCommandsCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (d *DefaultCommandsCommand) GetSubCommands() []Command {
+	return []Command{&toggleCommand{disable: true}, &toggleCommand{disable: false}}
+}
+
+func (d *DefaultCommandsCommand) Exec(ctx Context) error {
+	rr, _ := ctx.GetObject(ObjectMapKeyRouter).(Router)
+	entries, err := rr.GetConfig().DisabledProvider.GetDisabled(ctx.GetGuild().ID)
+	if err != nil {
+		return err
+	}
+
+	desc := ctx.T("rosetta.commands.all_enabled")
+	if len(entries) > 0 {
+		sort.Strings(entries)
+		desc = "`" + strings.Join(entries, "`\n`") + "`"
+	}
+	_, err = ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title:       ctx.T("rosetta.commands.disabled_title"),
+		Description: desc,
+		Color:       EmbedColorDefault,
+	})
+	return err
+}
+
+type toggleCommand struct {
+	disable bool
+}
+
+func (t *toggleCommand) GetInvokers() []string {
+	if t.disable {
+		return []string{"disable", "off"}
+	}
+	return []string{"enable", "on"}
+}
+
+func (t *toggleCommand) GetDescription() string {
+	return t.GetInvokers()[0] + " a command or group in this guild"
+}
+
+func (t *toggleCommand) GetUsage() string {
+	return ""
+}
+
+func (t *toggleCommand) GetGroup() string {
+	return GroupGuildAdmin
+}
+
+func (t *toggleCommand) GetDomain() string {
+	return "rs.guild.config.commands." + t.GetInvokers()[0]
+}
+
+func (t *toggleCommand) GetSubPermissionRules() []SubPermission {
+	return nil
+}
+
+func (t *toggleCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (t *toggleCommand) GetSchema() *Schema {
+	return NewSchema().String("target", "command invoker or group name").Rest()
+}
+
+func (t *toggleCommand) Exec(ctx Context) error {
+	if ok, err := RequireGuildManager(ctx); !ok {
+		return err
+	}
+
+	rr, _ := ctx.GetObject(ObjectMapKeyRouter).(Router)
+	target := ctx.GetParams().String("target")
+	provider, guildID := rr.GetConfig().DisabledProvider, ctx.GetGuild().ID
+
+	entry, ok, err := getToggleEntry(rr, guildID, target)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		_, err := ctx.RespondEmbedError(ctx.T("rosetta.commands.not_found", target), ErrInvokeDoesNotExists)
+		return err
+	}
+
+	entries, err := provider.GetDisabled(guildID)
+	if err != nil {
+		return err
+	}
+	updated := make([]string, 0, len(entries)+1)
+	for _, e := range entries {
+		if e != entry {
+			updated = append(updated, e)
+		}
+	}
+	if t.disable {
+		updated = append(updated, entry)
+	}
+	if err = provider.SetDisabled(guildID, updated); err != nil {
+		return err
+	}
+
+	key := "rosetta.commands.enabled"
+	if t.disable {
+		key = "rosetta.commands.disabled"
+	}
+	_, err = ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title:       ctx.T("rosetta.commands.title"),
+		Description: ctx.T(key, entry),
+		Color:       EmbedColorDefault,
+	})
+	return err
+}
+
+// getToggleEntry returns the disabled entry of given command invoker or group name.
+// Commands of given guild and their groups are resolved as well.
+func getToggleEntry(rr Router, guildID, target string) (string, bool, error) {
+	if cmd, ok := rr.GetCommand(target); ok {
+		if _, ok = cmd.(*DefaultCommandsCommand); !ok {
+			return getCommandEntry(cmd), true, nil
+		}
+		return "", false, nil
+	}
+	guildCmds, err := rr.GetGuildCommands(guildID)
+	if err != nil {
+		return "", false, err
+	}
+	for _, cmd := range guildCmds {
+		if arrayContains(cmd.GetInvokers(), target, true) {
+			return getCommandEntry(cmd), true, nil
+		}
+	}
+	for _, cmd := range append(rr.GetCommandInstances(), guildCmds...) {
+		if strings.EqualFold(cmd.GetGroup(), target) {
+			return getGroupEntry(cmd.GetGroup()), true, nil
+		}
+	}
+	return "", false, nil
+}
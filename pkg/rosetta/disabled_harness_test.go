@@ -0,0 +1,32 @@
+package rosetta_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+func TestDefaultCommandsCommand_Toggle(t *testing.T) {
+	cfg := rosetta.NewDefaultConfig()
+	cfg.OnError = nil
+	cfg.UseDefaultCommandsCommand = true
+	h, user := makeHarness(t, cfg)
+	manager := addManager(h)
+
+	// only members managing the guild can disable commands.
+	h.Send(user, "10", "r!commands disable ping")
+	h.ExpectNoErrors()
+	a := h.Last()
+	assert.Contains(t, a.Embed().Description, "Manage Server")
+	h.Reset()
+	h.Send(user, "10", "r!ping")
+	h.ExpectText("pong")
+
+	h.Reset()
+	h.Send(manager, "10", "r!commands disable ping")
+	h.ExpectNoErrors()
+	h.Send(user, "10", "r!ping")
+	h.ExpectError(rosetta.ErrTypeCommandDisabled)
+}
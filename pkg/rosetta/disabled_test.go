@@ -0,0 +1,100 @@
+package rosetta
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_IsDisabled(t *testing.T) {
+	cfg := makeTestConfig()
+	cfg.UseDefaultCommandsCommand = true
+	r, _ := NewRouter(cfg).(*router)
+	ping := &TestCmd{}
+	set := &TestSubCmd{invokers: []string{"set"}}
+	config := &TestSubCmd{invokers: []string{"config"}, subs: []Command{set}}
+	r.Register(ping)
+	r.Register(config)
+	faq := &TestGroupCmd{TestSubCmd: TestSubCmd{invokers: []string{"faq"}}, group: "CUSTOM"}
+	r.Register(&TestGuildSource{cmds: map[string]Command{"1:faq": faq}})
+
+	tests := []struct {
+		name     string
+		target   string
+		root     Command
+		cmd      Command
+		disabled bool
+	}{
+		{"command", "ping", ping, ping, true},
+		{"alias", "p", ping, ping, true},
+		{"other command", "ping", config, set, false},
+		{"root of sub command", "config", config, set, true},
+		{"group", "fun", ping, ping, true},
+		{"group of sub command", "Fun", config, set, true},
+		{"group with spaces", "guild admin", ping, ping, false},
+		{"guild command", "FAQ", faq, faq, true},
+		{"group of guild command", "custom", faq, faq, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok, err := getToggleEntry(r, "1", tt.target)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.NoError(t, cfg.DisabledProvider.SetDisabled("1", []string{entry}))
+
+			disabled, err := r.isDisabled("1", tt.root, tt.cmd)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.disabled, disabled)
+
+			disabled, _ = r.isDisabled("2", tt.root, tt.cmd)
+			assert.False(t, disabled)
+		})
+	}
+
+	_, ok, _ := getToggleEntry(r, "1", "abc")
+	assert.False(t, ok)
+	_, ok, _ = getToggleEntry(r, "1", "commands")
+	assert.False(t, ok)
+	// commands of other guilds are unknown.
+	_, ok, _ = getToggleEntry(r, "2", "faq")
+	assert.False(t, ok)
+
+	// commands command must stay usable, even if its group is disabled.
+	require.NoError(t, cfg.DisabledProvider.SetDisabled("1", []string{getGroupEntry(GroupGuildAdmin)}))
+	cmds, _ := r.GetCommand("commands")
+	disabled, _ := r.isDisabled("1", cmds, cmds)
+	assert.False(t, disabled)
+}
+
+func TestRouter_DispatchDisabled(t *testing.T) {
+	var errType ErrorType = -1
+	cfg := makeTestConfig()
+	cfg.OnError = func(_ Context, t ErrorType, _ error) { errType = t }
+	r, _ := NewRouter(cfg).(*router)
+	ping := &TestCmd{}
+	r.Register(ping)
+	require.NoError(t, cfg.DisabledProvider.SetDisabled("1", []string{"ping"}))
+
+	ctx := r.acquireContext(nil)
+	ctx.guild = &discordgo.Guild{ID: "1"}
+	ctx.root = ping
+	ctx.args = ParseArguments("")
+	assert.False(t, r.dispatch(ping, ctx))
+	assert.False(t, ping.executed)
+	assert.Equal(t, ErrTypeCommandDisabled, errType)
+
+	ctx.guild.ID = "2"
+	assert.True(t, r.dispatch(ping, ctx))
+	assert.True(t, ping.executed)
+}
+
+type TestGroupCmd struct {
+	TestSubCmd
+	group string
+}
+
+func (t *TestGroupCmd) GetGroup() string {
+	return t.group
+}
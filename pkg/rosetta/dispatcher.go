@@ -0,0 +1,218 @@
+package rosetta
+
+import (
+	"sync"
+	"time"
+)
+
+// OverflowPolicy defines how commands are handled when the dispatcher queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the event handler until a queue slot is free.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDrop rejects the command and calls OnError with ErrTypeQueueFull.
+	OverflowDrop
+)
+
+// SerializeMode defines which commands are executed one after another in order of arrival.
+type SerializeMode int
+
+const (
+	// SerializeNone executes commands concurrently without any ordering.
+	SerializeNone SerializeMode = iota
+
+	// SerializeGuild executes commands of the same guild in order.
+	SerializeGuild
+
+	// SerializeChannel executes commands of the same channel in order.
+	SerializeChannel
+)
+
+// DispatcherStats holds metrics of the command dispatcher, which can be used to tune
+// Config.Workers and Config.QueueSize.
+type DispatcherStats struct {
+	Workers int `json:"workers"`
+
+	// Queued is the amount of accepted commands which haven't started yet.
+	Queued int `json:"queued"`
+
+	// Running is the amount of commands being executed.
+	Running int `json:"running"`
+
+	Processed uint64 `json:"processed"`
+	Dropped   uint64 `json:"dropped"`
+
+	// LastWait, AvgWait and MaxWait are the durations commands spent queued.
+	LastWait time.Duration `json:"last_wait"`
+	AvgWait  time.Duration `json:"avg_wait"`
+	MaxWait  time.Duration `json:"max_wait"`
+}
+
+type job struct {
+	key      string
+	f        func()
+	enqueued time.Time
+}
+
+// dispatcher runs submitted jobs on a fixed amount of workers. Jobs sharing a key are
+// executed in order of submission, a job waiting on its key doesn't block a worker.
+// Only the head job of each key is put on the queue, following ones wait in its backlog.
+type dispatcher struct {
+	workers int
+	policy  OverflowPolicy
+	slots   chan struct{}
+	queue   chan *job
+	done    <-chan struct{}
+	start   sync.Once
+
+	mu        sync.Mutex
+	backlog   map[string][]*job
+	running   int
+	processed uint64
+	dropped   uint64
+	totalWait time.Duration
+	lastWait  time.Duration
+	maxWait   time.Duration
+}
+
+func newDispatcher(workers, queueSize int, policy OverflowPolicy, done <-chan struct{}) *dispatcher {
+	if queueSize < workers {
+		queueSize = workers
+	}
+	return &dispatcher{
+		workers: workers,
+		policy:  policy,
+		slots:   make(chan struct{}, queueSize),
+		queue:   make(chan *job, queueSize),
+		done:    done,
+		backlog: make(map[string][]*job),
+	}
+}
+
+// submit queues f to be executed after all previous jobs of given key. An empty key
+// isn't ordered. Without workers f is executed inline. Workers are started on first submit.
+func (d *dispatcher) submit(key string, f func()) error {
+	if d.workers <= 0 {
+		f()
+		return nil
+	}
+	d.start.Do(func() {
+		for i := 0; i < d.workers; i++ {
+			go d.work()
+		}
+	})
+
+	// slots bound the amount of accepted jobs, thus sending to queue never blocks.
+	select {
+	case d.slots <- struct{}{}:
+	case <-d.done:
+		return ErrRouterShutdown
+	default:
+		if d.policy == OverflowDrop {
+			d.mu.Lock()
+			d.dropped++
+			d.mu.Unlock()
+			return ErrQueueFull
+		}
+		select {
+		case d.slots <- struct{}{}:
+		case <-d.done:
+			return ErrRouterShutdown
+		}
+	}
+	j := &job{key: key, f: f, enqueued: time.Now()}
+	if !d.claim(j) {
+		return nil
+	}
+	d.queue <- j
+	return nil
+}
+
+func (d *dispatcher) work() {
+	for {
+		select {
+		case <-d.done:
+			return
+		case j := <-d.queue:
+			for j != nil {
+				d.run(j)
+				j = d.next(j.key)
+			}
+		}
+	}
+}
+
+// claim returns true if no other job of the same key is queued or running, thus j is put
+// on the queue. Otherwise j is appended to the backlog of its key and will be run by the
+// worker holding it. Keys are claimed while submitting, so jobs keep their submission order.
+func (d *dispatcher) claim(j *job) bool {
+	if j.key == "" {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if backlog, ok := d.backlog[j.key]; ok {
+		d.backlog[j.key] = append(backlog, j)
+		return false
+	}
+	d.backlog[j.key] = nil
+	return true
+}
+
+// next pops the next job of given key, or releases the key if there is none.
+func (d *dispatcher) next(key string) *job {
+	if key == "" {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	backlog := d.backlog[key]
+	if len(backlog) == 0 {
+		delete(d.backlog, key)
+		return nil
+	}
+	d.backlog[key] = backlog[1:]
+	return backlog[0]
+}
+
+func (d *dispatcher) run(j *job) {
+	<-d.slots
+	wait := time.Since(j.enqueued)
+
+	d.mu.Lock()
+	d.running++
+	d.lastWait = wait
+	d.totalWait += wait
+	if wait > d.maxWait {
+		d.maxWait = wait
+	}
+	d.mu.Unlock()
+
+	defer func() {
+		d.mu.Lock()
+		d.running--
+		d.processed++
+		d.mu.Unlock()
+	}()
+	j.f()
+}
+
+func (d *dispatcher) stats() DispatcherStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := DispatcherStats{
+		Workers:   d.workers,
+		Queued:    len(d.slots),
+		Running:   d.running,
+		Processed: d.processed,
+		Dropped:   d.dropped,
+		LastWait:  d.lastWait,
+		MaxWait:   d.maxWait,
+	}
+	if started := d.processed + uint64(d.running); started > 0 {
+		s.AvgWait = d.totalWait / time.Duration(started)
+	}
+	return s
+}
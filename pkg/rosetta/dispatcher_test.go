@@ -0,0 +1,111 @@
+package rosetta
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher_Inline(t *testing.T) {
+	d := newDispatcher(0, 0, OverflowDrop, nil)
+	executed := false
+	require.NoError(t, d.submit("a", func() { executed = true }))
+	assert.True(t, executed)
+}
+
+func TestDispatcher_Ordering(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	d := newDispatcher(4, 100, OverflowBlock, done)
+
+	var (
+		mu    sync.Mutex
+		order = make(map[string][]int)
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < 50; i++ {
+		for _, key := range []string{"a", "b"} {
+			i, key := i, key
+			wg.Add(1)
+			require.NoError(t, d.submit(key, func() {
+				defer wg.Done()
+				mu.Lock()
+				order[key] = append(order[key], i)
+				mu.Unlock()
+			}))
+		}
+	}
+	wg.Wait()
+
+	for _, key := range []string{"a", "b"} {
+		require.Len(t, order[key], 50)
+		for i, v := range order[key] {
+			assert.Equal(t, i, v)
+		}
+	}
+	assert.Equal(t, uint64(100), d.stats().Processed)
+}
+
+func TestDispatcher_KeyDoesNotBlockOthers(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	d := newDispatcher(2, 10, OverflowBlock, done)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	require.NoError(t, d.submit("a", func() { close(started); <-release }))
+	<-started
+
+	// second job of a waits in the backlog without holding a worker.
+	finished := make(chan string, 2)
+	require.NoError(t, d.submit("a", func() { finished <- "a" }))
+	require.NoError(t, d.submit("b", func() { finished <- "b" }))
+	assert.Equal(t, "b", <-finished)
+
+	close(release)
+	assert.Equal(t, "a", <-finished)
+}
+
+func TestDispatcher_Overflow(t *testing.T) {
+	done := make(chan struct{})
+	d := newDispatcher(1, 1, OverflowDrop, done)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	require.NoError(t, d.submit("", func() { close(started); <-release }))
+	<-started
+	require.NoError(t, d.submit("", func() {}))
+	assert.Equal(t, ErrQueueFull, d.submit("", func() {}))
+
+	stats := d.stats()
+	assert.Equal(t, 1, stats.Queued)
+	assert.Equal(t, 1, stats.Running)
+	assert.Equal(t, uint64(1), stats.Dropped)
+
+	d.policy = OverflowBlock
+	close(done)
+	assert.Equal(t, ErrRouterShutdown, d.submit("", func() {}))
+	close(release)
+}
+
+func TestDispatcher_WaitStats(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	d := newDispatcher(1, 2, OverflowBlock, done)
+
+	release := make(chan struct{})
+	require.NoError(t, d.submit("", func() { <-release }))
+	var wg sync.WaitGroup
+	wg.Add(1)
+	require.NoError(t, d.submit("", wg.Done))
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	stats := d.stats()
+	assert.GreaterOrEqual(t, int64(stats.MaxWait), int64(20*time.Millisecond))
+	assert.Greater(t, int64(stats.AvgWait), int64(0))
+}
@@ -0,0 +1,272 @@
+// Package docs generates documentation of the commands registered to a rosetta router,
+// so command lists e.g. of our website can be generated instead of maintained by hand.
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+// Command documents a single command and its sub commands.
+type Command struct {
+	Invokers       []string                `json:"invokers"`
+	Description    string                  `json:"description"`
+	Group          string                  `json:"group"`
+	Domain         string                  `json:"domain"`
+	Usage          string                  `json:"usage"`
+	ExecutableInDM bool                    `json:"executable_in_dm"`
+	Hidden         bool                    `json:"hidden,omitempty"`
+	GuildOnly      bool                    `json:"guild_only,omitempty"`
+	OwnerOnly      bool                    `json:"owner_only,omitempty"`
+	NSFW           bool                    `json:"nsfw,omitempty"`
+	MemberPerms    []string                `json:"member_permissions,omitempty"`
+	BotPerms       []string                `json:"bot_permissions,omitempty"`
+	SubPermissions []rosetta.SubPermission `json:"sub_permissions"`
+	Params         []Param                 `json:"params,omitempty"`
+	RateLimit      *RateLimit              `json:"rate_limit,omitempty"`
+	SubCommands    []Command               `json:"sub_commands,omitempty"`
+}
+
+// Param documents a parameter of a rosetta.SchemaCommand.
+type Param struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Type        string   `json:"type"`
+	Optional    bool     `json:"optional"`
+	Rest        bool     `json:"rest,omitempty"`
+	Choices     []string `json:"choices,omitempty"`
+}
+
+// RateLimit documents the limiter config of a rosetta.LimitedConfig command.
+type RateLimit struct {
+	Burst int `json:"burst"`
+
+	// Restoration is the duration between new tokens in seconds.
+	Restoration float64 `json:"restoration"`
+	Global      bool    `json:"global"`
+}
+
+// Generate returns docs of all commands registered to given router sorted by group and
+// invoker. Hidden commands are left out unless includeHidden is true.
+func Generate(r rosetta.Router, includeHidden bool) []Command {
+	instances := r.GetCommandInstances()
+	sort.SliceStable(instances, func(i, j int) bool {
+		if instances[i].GetGroup() != instances[j].GetGroup() {
+			return instances[i].GetGroup() < instances[j].GetGroup()
+		}
+		return instances[i].GetInvokers()[0] < instances[j].GetInvokers()[0]
+	})
+
+	cmds := make([]Command, 0, len(instances))
+	for _, c := range instances {
+		if doc, ok := newCommand(c, includeHidden); ok {
+			cmds = append(cmds, doc)
+		}
+	}
+	return cmds
+}
+
+func newCommand(c rosetta.Command, includeHidden bool) (Command, bool) {
+	doc := Command{
+		Invokers:       c.GetInvokers(),
+		Description:    c.GetDescription(),
+		Group:          c.GetGroup(),
+		Domain:         c.GetDomain(),
+		Usage:          c.GetUsage(),
+		ExecutableInDM: c.IsExecutableInDM(),
+		SubPermissions: c.GetSubPermissionRules(),
+	}
+	if doc.SubPermissions == nil {
+		doc.SubPermissions = []rosetta.SubPermission{}
+	}
+	if h, ok := c.(rosetta.HiddenCommand); ok && h.IsHidden() {
+		if !includeHidden {
+			return doc, false
+		}
+		doc.Hidden = true
+	}
+	if g, ok := c.(rosetta.GuildOnlyCommand); ok {
+		doc.GuildOnly = g.IsGuildOnly()
+	}
+	if o, ok := c.(rosetta.OwnerOnlyCommand); ok {
+		doc.OwnerOnly = o.IsOwnerOnly()
+	}
+	if n, ok := c.(rosetta.NSFWCommand); ok {
+		doc.NSFW = n.IsNSFW()
+	}
+	if pc, ok := c.(rosetta.PermissionsCommand); ok {
+		if perms := rosetta.PermissionNames(pc.GetMemberPermissions()); len(perms) > 0 {
+			doc.MemberPerms = perms
+		}
+		if perms := rosetta.PermissionNames(pc.GetBotPermissions()); len(perms) > 0 {
+			doc.BotPerms = perms
+		}
+	}
+	if sc, ok := c.(rosetta.SchemaCommand); ok {
+		for _, p := range sc.GetSchema().Params() {
+			doc.Params = append(doc.Params, Param{
+				Name:        p.Name,
+				Description: p.Description,
+				Type:        p.Type.String(),
+				Optional:    p.Optional,
+				Rest:        p.Rest,
+				Choices:     p.Choices,
+			})
+		}
+		if doc.Usage == "" {
+			doc.Usage = sc.GetSchema().Usage(c.GetInvokers()[0])
+		}
+	}
+	if lc, ok := c.(rosetta.LimitedConfig); ok {
+		doc.RateLimit = &RateLimit{
+			Burst:       lc.GetLimiterBurst(),
+			Restoration: lc.GetLimiterRestoration().Seconds(),
+			Global:      lc.IsLimiterGlobal(),
+		}
+	}
+	if p, ok := c.(rosetta.ParentCommand); ok {
+		for _, sub := range p.GetSubCommands() {
+			if subDoc, ok := newCommand(sub, includeHidden); ok {
+				doc.SubCommands = append(doc.SubCommands, subDoc)
+			}
+		}
+	}
+	return doc, true
+}
+
+// WriteJSON writes given docs as indented JSON object with a `commands` array to w.
+func WriteJSON(w io.Writer, cmds []Command) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Commands []Command `json:"commands"`
+	}{cmds})
+}
+
+// WriteMarkdown writes given docs to w with a section per group and command.
+func WriteMarkdown(w io.Writer, cmds []Command) error {
+	p := &printer{w: w}
+	p.printf("# Commands\n")
+
+	group := ""
+	for i, c := range cmds {
+		if i == 0 || c.Group != group {
+			group = c.Group
+			p.printf("\n## %s\n", group)
+		}
+		writeCommand(p, c, c.Invokers[0], 3)
+	}
+	return p.err
+}
+
+func writeCommand(p *printer, c Command, path string, level int) {
+	if level > 6 {
+		level = 6
+	}
+	p.printf("\n%s %s\n\n", strings.Repeat("#", level), path)
+	if c.Description != "" {
+		p.printf("%s\n\n", c.Description)
+	}
+
+	p.printf("| | |\n|---|---|\n")
+	p.printf("| Invokers | %s |\n", escapeCell(codeList(c.Invokers)))
+	p.printf("| Domain | `%s` |\n", escapeCell(c.Domain))
+	p.printf("| Executable in DMs | %s |\n", yesNo(c.ExecutableInDM))
+	if c.GuildOnly {
+		p.printf("| Guild only | yes |\n")
+	}
+	if c.OwnerOnly {
+		p.printf("| Bot owners only | yes |\n")
+	}
+	if c.NSFW {
+		p.printf("| NSFW channels only | yes |\n")
+	}
+	if len(c.MemberPerms) > 0 {
+		p.printf("| Required permissions | %s |\n", escapeCell(strings.Join(c.MemberPerms, ", ")))
+	}
+	if len(c.BotPerms) > 0 {
+		p.printf("| Bot permissions | %s |\n", escapeCell(strings.Join(c.BotPerms, ", ")))
+	}
+	if c.RateLimit != nil {
+		scope := "per guild"
+		if c.RateLimit.Global {
+			scope = "global"
+		}
+		p.printf("| Rate limit | %d per %gs, %s |\n", c.RateLimit.Burst, c.RateLimit.Restoration, scope)
+	}
+
+	if c.Usage != "" {
+		p.printf("\n**Usage**\n\n%s\n", strings.ReplaceAll(c.Usage, "\n", "  \n"))
+	}
+
+	if len(c.Params) > 0 {
+		p.printf("\n**Parameters**\n\n")
+		for _, param := range c.Params {
+			opt := ""
+			if param.Optional {
+				opt = ", optional"
+			}
+			p.printf("- `%s` (%s%s) - %s\n", param.Name, param.Type, opt, param.Description)
+		}
+	}
+
+	if len(c.SubPermissions) > 0 {
+		p.printf("\n**Sub permissions**\n\n")
+		for _, sp := range c.SubPermissions {
+			expl := ""
+			if sp.Explicit {
+				expl = " (explicit)"
+			}
+			p.printf("- `%s`%s - %s\n", termAssembly(c.Domain, sp.Term), expl, sp.Description)
+		}
+	}
+
+	for _, sub := range c.SubCommands {
+		writeCommand(p, sub, path+" "+sub.Invokers[0], level+1)
+	}
+}
+
+// termAssembly mirrors rosetta.GetTermAssembly for documented commands.
+func termAssembly(domain, term string) string {
+	if strings.HasPrefix(term, "/") {
+		return term[1:]
+	}
+	return domain + "." + term
+}
+
+func codeList(v []string) string {
+	res := make([]string, len(v))
+	for i, s := range v {
+		res[i] = "`" + s + "`"
+	}
+	return strings.Join(res, ", ")
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// printer writes formatted output, keeping the first error.
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, args...)
+}
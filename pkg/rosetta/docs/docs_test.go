@@ -0,0 +1,174 @@
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+type testCmd struct {
+	invoke string
+	group  string
+	hidden bool
+	subs   []rosetta.Command
+}
+
+func (t *testCmd) GetInvokers() []string {
+	return []string{t.invoke, t.invoke[:1]}
+}
+
+func (t *testCmd) GetDescription() string {
+	return "test " + t.invoke
+}
+
+func (t *testCmd) GetUsage() string {
+	return "`" + t.invoke + "` - a | b\n`" + t.invoke + " x` - x"
+}
+
+func (t *testCmd) GetGroup() string {
+	return t.group
+}
+
+func (t *testCmd) GetDomain() string {
+	return "test." + t.invoke
+}
+
+func (t *testCmd) GetSubPermissionRules() []rosetta.SubPermission {
+	return []rosetta.SubPermission{{Term: "edit", Explicit: true, Description: "edit things"}}
+}
+
+func (t *testCmd) IsExecutableInDM() bool {
+	return false
+}
+
+func (t *testCmd) Exec(_ rosetta.Context) error {
+	return nil
+}
+
+func (t *testCmd) IsHidden() bool {
+	return t.hidden
+}
+
+func (t *testCmd) GetSubCommands() []rosetta.Command {
+	return t.subs
+}
+
+func (t *testCmd) GetLimiterBurst() int {
+	return 3
+}
+
+func (t *testCmd) GetLimiterRestoration() time.Duration {
+	return 10 * time.Second
+}
+
+func (t *testCmd) IsLimiterGlobal() bool {
+	return true
+}
+
+type testSchemaCmd struct {
+	testCmd
+}
+
+func (t *testSchemaCmd) GetUsage() string {
+	return ""
+}
+
+func (t *testSchemaCmd) IsGuildOnly() bool {
+	return true
+}
+
+func (t *testSchemaCmd) GetMemberPermissions() int64 {
+	return discordgo.PermissionManageMessages | discordgo.PermissionKickMembers
+}
+
+func (t *testSchemaCmd) GetBotPermissions() int64 {
+	return 0
+}
+
+func (t *testSchemaCmd) GetSchema() *rosetta.Schema {
+	return rosetta.NewSchema().User("target", "user to kick").String("reason", "why").Optional().Rest()
+}
+
+func makeTestRouter() rosetta.Router {
+	cfg := rosetta.NewDefaultConfig()
+	cfg.UseDefaultHelpCommand = false
+	r := rosetta.NewRouter(cfg)
+	r.Register(&testCmd{invoke: "zeta", group: rosetta.GroupFun})
+	r.Register(&testCmd{invoke: "config", group: rosetta.GroupGuildConfig, subs: []rosetta.Command{
+		&testCmd{invoke: "prefix", group: rosetta.GroupGuildConfig},
+		&testCmd{invoke: "secret", group: rosetta.GroupGuildConfig, hidden: true},
+	}})
+	r.Register(&testCmd{invoke: "alpha", group: rosetta.GroupFun})
+	r.Register(&testCmd{invoke: "hidden", group: rosetta.GroupFun, hidden: true})
+	r.Register(&testSchemaCmd{testCmd{invoke: "kick", group: rosetta.GroupModeration}})
+	return r
+}
+
+func TestGenerate(t *testing.T) {
+	cmds := Generate(makeTestRouter(), false)
+	require.Len(t, cmds, 4)
+	assert.Equal(t, []string{"alpha", "a"}, cmds[0].Invokers)
+	assert.Equal(t, "zeta", cmds[1].Invokers[0])
+	assert.Equal(t, "config", cmds[2].Invokers[0])
+	assert.Equal(t, "kick", cmds[3].Invokers[0])
+
+	config := cmds[2]
+	assert.Equal(t, &RateLimit{Burst: 3, Restoration: 10, Global: true}, config.RateLimit)
+	require.Len(t, config.SubCommands, 1)
+	assert.Equal(t, "prefix", config.SubCommands[0].Invokers[0])
+
+	kick := cmds[3]
+	assert.Contains(t, kick.Usage, "`kick <target> [reason...]`\n")
+	assert.Equal(t, []Param{
+		{Name: "target", Description: "user to kick", Type: "user"},
+		{Name: "reason", Description: "why", Type: "text", Optional: true, Rest: true},
+	}, kick.Params)
+
+	withHidden := Generate(makeTestRouter(), true)
+	require.Len(t, withHidden, 5)
+	assert.True(t, withHidden[1].Hidden)
+	assert.Len(t, withHidden[3].SubCommands, 2)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, Generate(makeTestRouter(), false)))
+
+	var res struct {
+		Commands []map[string]interface{} `json:"commands"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+	require.Len(t, res.Commands, 4)
+	c := res.Commands[0]
+	assert.Equal(t, "test.alpha", c["domain"])
+	assert.Equal(t, false, c["executable_in_dm"])
+	assert.NotContains(t, c, "hidden")
+	assert.Equal(t, map[string]interface{}{"burst": float64(3), "restoration": float64(10), "global": true}, c["rate_limit"])
+	assert.Len(t, c["sub_permissions"], 1)
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, Generate(makeTestRouter(), false)))
+	md := buf.String()
+
+	assert.Contains(t, md, "# Commands\n\n## FUN\n\n### alpha\n\ntest alpha\n")
+	assert.Contains(t, md, "| Invokers | `alpha`, `a` |\n")
+	assert.Contains(t, md, "| Executable in DMs | no |\n")
+	assert.Contains(t, md, "| Rate limit | 3 per 10s, global |\n")
+	assert.Contains(t, md, "`alpha` - a | b  \n`alpha x` - x\n")
+	assert.Contains(t, md, "- `test.alpha.edit` (explicit) - edit things\n")
+	assert.Contains(t, md, "\n#### config prefix\n")
+	assert.Contains(t, md, "- `reason` (text, optional) - why\n")
+	assert.Contains(t, md, "| Guild only | yes |\n| Required permissions | Kick Members, Manage Messages |\n")
+	assert.NotContains(t, md, "Bot permissions")
+	assert.NotContains(t, md, "hidden")
+	assert.NotContains(t, md, "secret")
+}
@@ -0,0 +1,164 @@
+package rosetta
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/log"
+)
+
+// errorTypes maps each ErrorType to the error it represents.
+var errorTypes = map[ErrorType]error{
+	ErrTypeGuildPrefixGetter:     ErrGuildPrefixGetter,
+	ErrTypeGetChannel:            ErrGetChannel,
+	ErrTypeGetGuild:              ErrGetGuild,
+	ErrTypeCommandNotFound:       ErrCommandNotFound,
+	ErrTypeNotExecutableInDM:     ErrNotExecutableInDMs,
+	ErrTypeMiddleware:            ErrMiddleware,
+	ErrTypeCommandExec:           ErrCommandExec,
+	ErrTypeDeleteCommandMessage:  ErrDeleteCommandMessage,
+	ErrTypeRegisterSlashCommands: ErrRegisterSlashCommands,
+	ErrTypeInteractionRespond:    ErrInteractionRespond,
+	ErrTypeInvalidArguments:      ErrInvalidArgument,
+	ErrTypeCommandTimeout:        ErrCommandTimeout,
+	ErrTypeCommandCanceled:       ErrCommandCanceled,
+	ErrTypeQueueFull:             ErrQueueFull,
+	ErrTypeCommandDisabled:       ErrCommandDisabled,
+	ErrTypeCommandPanic:          ErrCommandPanic,
+	ErrTypeEventHandler:          ErrEventHandler,
+	ErrTypeGetGuildCommand:       ErrGetGuildCommand,
+	ErrTypeGetLocale:             ErrGetLocale,
+	ErrTypeGuildOnly:             ErrGuildOnly,
+	ErrTypeOwnerOnly:             ErrOwnerOnly,
+	ErrTypeNSFWOnly:              ErrNSFWOnly,
+	ErrTypeMissingPermissions:    ErrMissingPermissions,
+	ErrTypeBotMissingPermissions: ErrBotMissingPermissions,
+}
+
+// Err returns the error represented by this type, nil if unknown.
+func (e ErrorType) Err() error {
+	return errorTypes[e]
+}
+
+// Error is passed to OnError by the router. It carries the type of the failure, the domain
+// of the failed command, the wrapped cause and a message which is safe to show users.
+// Every occurrence has a short correlation ID which is logged and shown to users, so
+// reports can be matched with the logs.
+type Error struct {
+	Type   ErrorType
+	Domain string
+	Err    error
+
+	// Message is shown to users instead of the cause. Errors without message aren't answered
+	// by DefaultOnError, Embed shows a generic message for them.
+	Message string
+
+	// ID is the correlation ID of this occurrence.
+	ID string
+}
+
+// NewUserError returns an error whose message is shown to users when returned by a command.
+// Given cause is kept internal, it may be nil.
+func NewUserError(message string, cause error) *Error {
+	return &Error{Type: ErrTypeCommandExec, Err: cause, Message: message}
+}
+
+// WrapError returns given error as Error of given type and domain. The message, ID and
+// domain of an Error within the chain of err are kept, missing IDs are generated.
+func WrapError(errType ErrorType, domain string, err error) *Error {
+	e := &Error{Type: errType, Domain: domain, Err: err}
+	var inner *Error
+	if errors.As(err, &inner) {
+		e.Message, e.ID = inner.Message, inner.ID
+		if e.Domain == "" {
+			e.Domain = inner.Domain
+		}
+		// avoid nesting if err is an Error itself.
+		if err == error(inner) {
+			e.Err = inner.Err
+		}
+	}
+	if e.ID == "" {
+		e.ID = newErrorID()
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	s := "[" + e.ID + "] " + e.Type.String()
+	if e.Domain != "" {
+		s += " (" + e.Domain + ")"
+	}
+	switch {
+	case e.Err != nil && e.Err == e.Type.Err():
+		// the cause is the sentinel of our type, which was already printed.
+	case e.Err != nil:
+		s += ": " + e.Err.Error()
+	case e.Message != "":
+		s += ": " + e.Message
+	}
+	return s
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the error represented by our type, thus errors.Is works
+// with the sentinel errors as well as with the cause.
+func (e *Error) Is(target error) bool {
+	return target != nil && target == e.Type.Err()
+}
+
+// UserMessage returns the message shown to users, translated in the locale of ctx if
+// no message was set.
+func (e *Error) UserMessage(ctx Context) string {
+	switch {
+	case e.Message != "":
+		return e.Message
+	case e.Type == ErrTypeCommandTimeout:
+		return ctx.T("rosetta.error.timeout")
+	}
+	return ctx.T("rosetta.error.generic")
+}
+
+// Embed returns an embed showing the user message and correlation ID of this error.
+func (e *Error) Embed(ctx Context) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       ctx.T("rosetta.error.title"),
+		Description: e.UserMessage(ctx),
+		Color:       EmbedColorError,
+		Footer:      &discordgo.MessageEmbedFooter{Text: ctx.T("rosetta.error.id", e.ID)},
+	}
+}
+
+// DefaultOnError logs given error with its correlation ID. Only errors carrying a user message,
+// e.g. by NewUserError, are answered with the message and correlation ID of the error. Other
+// failures are internal, thus they're just logged.
+func DefaultOnError(ctx Context, errType ErrorType, err error) {
+	e := WrapError(errType, "", err)
+	log.Error(e.Err).
+		Str("id", e.ID).
+		Str("type", errType.String()).
+		Str("domain", e.Domain).
+		Msg(e.Message)
+
+	if ctx == nil || ctx.GetChannel() == nil || e.Message == "" {
+		return
+	}
+	if _, err := ctx.RespondEmbed(e.Embed(ctx)); err != nil {
+		log.Error(err).Str("id", e.ID).Msg("failed to respond error")
+	}
+}
+
+// newErrorID returns a random correlation ID of 8 hex characters.
+func newErrorID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "00000000"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,49 @@
+package rosetta_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+func TestDefaultOnError(t *testing.T) {
+	h, user := makeHarness(t, rosetta.NewDefaultConfig())
+	cmd := &testCmd{invoke: "respond"}
+	h.Router.Register(cmd)
+
+	// internal failures are only logged.
+	cmd.exec = func(ctx rosetta.Context) error {
+		return fmt.Errorf("query: %w", errors.New("password=hunter2"))
+	}
+	h.Send(user, "10", "r!respond")
+	h.ExpectNoResponse()
+	var rerr *rosetta.Error
+	require.True(t, errors.As(h.ExpectError(rosetta.ErrTypeCommandExec), &rerr))
+	assert.Equal(t, "test.respond", rerr.Domain)
+
+	// messages of user errors are answered with their correlation ID.
+	h.Reset()
+	cmd.exec = func(ctx rosetta.Context) error {
+		return rosetta.NewUserError("Join a voice channel first.", errors.New("password=hunter2"))
+	}
+	h.Send(user, "10", "r!respond")
+	a := h.ExpectEmbedTitle("Something went wrong")
+	require.NotNil(t, a)
+	assert.Equal(t, "Join a voice channel first.", a.Embed().Description)
+	require.True(t, errors.As(h.ExpectError(rosetta.ErrTypeCommandExec), &rerr))
+	assert.Equal(t, "Error ID: "+rerr.ID, a.Embed().Footer.Text)
+	assert.NotContains(t, a.Embed().Description, "hunter2")
+
+	// unknown commands are answered by suggestions only.
+	h.Reset()
+	h.Send(user, "10", "r!nothing")
+	h.ExpectError(rosetta.ErrTypeCommandNotFound)
+	for _, a := range h.Sent() {
+		assert.NotEqual(t, "Something went wrong", a.Embed().Title)
+	}
+}
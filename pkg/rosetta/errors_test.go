@@ -0,0 +1,57 @@
+package rosetta
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapError(t *testing.T) {
+	cause := errors.New("connection refused")
+	e := WrapError(ErrTypeCommandExec, "test.fun.ping", cause)
+	assert.Regexp(t, "^[0-9a-f]{8}$", e.ID)
+	assert.Equal(t, "["+e.ID+"] command failed to execute (test.fun.ping): connection refused", e.Error())
+	assert.ErrorIs(t, e, cause)
+	assert.ErrorIs(t, e, ErrCommandExec)
+	assert.False(t, errors.Is(e, ErrCommandPanic))
+	assert.NotEqual(t, e.ID, WrapError(ErrTypeCommandExec, "", cause).ID)
+
+	// wrapping keeps message and ID of an inner error without nesting it.
+	user := NewUserError("join a voice channel first", cause)
+	user.ID = "abcd1234"
+	e = WrapError(ErrTypeMiddleware, "test.fun.ping", user)
+	assert.Equal(t, "abcd1234", e.ID)
+	assert.Equal(t, "join a voice channel first", e.Message)
+	assert.Equal(t, cause, e.Err)
+	assert.Equal(t, ErrTypeMiddleware, e.Type)
+
+	e = WrapError(ErrTypeCommandExec, "", fmt.Errorf("play: %w", user))
+	assert.Equal(t, "join a voice channel first", e.Message)
+	var inner *Error
+	require.True(t, errors.As(e.Err, &inner))
+	assert.Equal(t, user, inner)
+
+	e = WrapError(ErrTypeCommandExec, "", NewUserError("nope", nil))
+	assert.Equal(t, "["+e.ID+"] command failed to execute: nope", e.Error())
+
+	// the sentinel of the type isn't repeated.
+	e = WrapError(ErrTypeCommandNotFound, "", ErrCommandNotFound)
+	assert.Equal(t, "["+e.ID+"] command not found", e.Error())
+	assert.ErrorIs(t, e, ErrCommandNotFound)
+}
+
+func TestError_UserMessage(t *testing.T) {
+	ctx := makeTestCtx(false, true)
+	assert.Equal(t, "nope", NewUserError("nope", nil).UserMessage(ctx))
+	assert.Equal(t, ctx.T("rosetta.error.generic"), WrapError(ErrTypeCommandPanic, "", ErrCommandPanic).UserMessage(ctx))
+	assert.Equal(t, ctx.T("rosetta.error.timeout"), WrapError(ErrTypeCommandTimeout, "", ErrCommandTimeout).UserMessage(ctx))
+
+	e := WrapError(ErrTypeCommandExec, "", errors.New("secret"))
+	embed := e.Embed(ctx)
+	assert.NotContains(t, embed.Description, "secret")
+	assert.Contains(t, embed.Footer.Text, e.ID)
+	assert.Equal(t, EmbedColorError, embed.Color)
+}
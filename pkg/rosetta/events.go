@@ -0,0 +1,101 @@
+package rosetta
+
+import (
+	gocontext "context"
+	"errors"
+	"reflect"
+	"runtime/debug"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// EventHandler handles discordgo events other than commands, e.g. reactions, member joins
+// or voice state changes. It is registered with Router.Register or Router.RegisterEventHandler.
+type EventHandler interface {
+
+	// GetEvents returns values of the handled event types, e.g. &discordgo.MessageReactionAdd{}.
+	GetEvents() []interface{}
+
+	// HandleEvent is called with an event of one of the types returned by GetEvents.
+	// A returned error is passed to OnError with ErrTypeEventHandler.
+	HandleEvent(ctx EventContext, event interface{}) error
+}
+
+// EventContext is passed to an EventHandler. Objects can be obtained from the object
+// container the same way as from a command Context.
+type EventContext interface {
+	ObjectMap
+
+	// GetSession returns our instance of discordgo.Session.
+	GetSession() *discordgo.Session
+
+	// GetContext returns a context.Context which is cancelled when the router shuts down.
+	GetContext() gocontext.Context
+
+	// GetEvent returns the handled event.
+	GetEvent() interface{}
+}
+
+func (c *context) GetEvent() interface{} {
+	return c.event
+}
+
+func (r *router) RegisterEventHandler(h EventHandler) {
+	r.eventMu.Lock()
+	defer r.eventMu.Unlock()
+	for _, e := range h.GetEvents() {
+		t := reflect.TypeOf(e)
+		r.eventHandlers[t] = append(r.eventHandlers[t], h)
+	}
+}
+
+// getEventHandlers returns handlers registered for the type of given event.
+func (r *router) getEventHandlers(event interface{}) []EventHandler {
+	r.eventMu.RLock()
+	defer r.eventMu.RUnlock()
+	return r.eventHandlers[reflect.TypeOf(event)]
+}
+
+// triggerEvent passes given event to its registered handlers.
+func (r *router) triggerEvent(s *discordgo.Session, event interface{}) {
+	for _, h := range r.getEventHandlers(event) {
+		h := h
+		err := r.dispatcher.submit("", func() { r.handleEvent(s, h, event) })
+		if err != nil && !errors.Is(err, ErrRouterShutdown) {
+			ctx := r.acquireContext(s)
+			ctx.event = event
+			r.onError(ctx, ErrTypeQueueFull, err)
+			r.releaseContext(ctx)
+		}
+	}
+}
+
+func (r *router) handleEvent(s *discordgo.Session, h EventHandler, event interface{}) {
+	if r.baseCtx.Err() != nil {
+		return
+	}
+
+	ctx := r.acquireContext(s)
+	ctx.event = event
+	ctx.ctx = r.baseCtx
+	defer r.releaseContext(ctx)
+
+	if ctx.GetObject(ObjectMapKeyRouter) != r {
+		ctx.SetObject(ObjectMapKeyRouter, r)
+	}
+
+	if err := r.runEventHandler(h, ctx, event); err != nil {
+		r.onError(ctx, ErrTypeEventHandler, err)
+	}
+}
+
+func (r *router) runEventHandler(h EventHandler, ctx EventContext, event interface{}) (err error) {
+	if r.config.UseRecovery {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Value: v, Stack: debug.Stack()}
+			}
+		}()
+	}
+	return h.HandleEvent(ctx, event)
+}
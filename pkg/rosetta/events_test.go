@@ -0,0 +1,66 @@
+package rosetta
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/sarulabs/di/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TestEventHandler struct {
+	handled []interface{}
+	value   interface{}
+	err     error
+	panics  bool
+}
+
+func (t *TestEventHandler) GetEvents() []interface{} {
+	return []interface{}{&discordgo.MessageReactionAdd{}, &discordgo.GuildMemberAdd{}}
+}
+
+func (t *TestEventHandler) HandleEvent(ctx EventContext, event interface{}) error {
+	if t.panics {
+		panic("test panic")
+	}
+	t.handled = append(t.handled, ctx.GetEvent())
+	t.value = ctx.GetObject("rosetta_testObject")
+	return t.err
+}
+
+func TestRouter_EventHandler(t *testing.T) {
+	b, _ := di.NewBuilder()
+	require.NoError(t, b.Set("rosetta_testObject", "rosetta_testValue"))
+
+	var errTypes []ErrorType
+	cfg := makeTestConfig()
+	cfg.ObjectContainer = b.Build()
+	cfg.OnError = func(_ Context, t ErrorType, _ error) { errTypes = append(errTypes, t) }
+	r, _ := NewRouter(cfg).(*router)
+
+	h := &TestEventHandler{}
+	r.Register(h)
+
+	reaction := &discordgo.MessageReactionAdd{MessageReaction: &discordgo.MessageReaction{UserID: "1"}}
+	r.triggerEvent(nil, reaction)
+	r.triggerEvent(nil, &discordgo.MessageReactionRemove{})
+	assert.Equal(t, []interface{}{reaction}, h.handled)
+	assert.Equal(t, "rosetta_testValue", h.value)
+	assert.Empty(t, errTypes)
+
+	h.err = errors.New("test error")
+	r.triggerEvent(nil, &discordgo.GuildMemberAdd{})
+	assert.Len(t, h.handled, 2)
+	assert.Equal(t, []ErrorType{ErrTypeEventHandler}, errTypes)
+
+	cfg.UseRecovery = true
+	h.panics = true
+	r.triggerEvent(nil, reaction)
+	assert.Equal(t, []ErrorType{ErrTypeEventHandler, ErrTypeEventHandler}, errTypes)
+
+	r.Shutdown()
+	r.triggerEvent(nil, reaction)
+	assert.Len(t, errTypes, 2)
+}
@@ -0,0 +1,53 @@
+package rosetta
+
+// GuildCommandSource provides commands which are defined per guild at runtime, e.g. custom
+// text commands. Router resolves them when no registered command matches the invoke.
+type GuildCommandSource interface {
+
+	// GetGuildCommand returns the command of given guild by invoker. If command could
+	// not be found, false is returned.
+	GetGuildCommand(guildID, invoke string) (Command, bool, error)
+
+	// GetGuildCommands returns all commands of given guild.
+	GetGuildCommands(guildID string) ([]Command, error)
+}
+
+func (r *router) RegisterGuildCommandSource(src GuildCommandSource) {
+	r.cmdMu.Lock()
+	defer r.cmdMu.Unlock()
+	r.guildSources = append(r.guildSources, src)
+}
+
+func (r *router) getGuildSources() []GuildCommandSource {
+	r.cmdMu.RLock()
+	defer r.cmdMu.RUnlock()
+	return append([]GuildCommandSource{}, r.guildSources...)
+}
+
+func (r *router) GetGuildCommand(guildID, invoke string) (Command, bool, error) {
+	if guildID == "" {
+		return nil, false, nil
+	}
+	for _, src := range r.getGuildSources() {
+		cmd, ok, err := src.GetGuildCommand(guildID, invoke)
+		if err != nil || ok {
+			return cmd, ok, err
+		}
+	}
+	return nil, false, nil
+}
+
+func (r *router) GetGuildCommands(guildID string) ([]Command, error) {
+	cmds := make([]Command, 0)
+	if guildID == "" {
+		return cmds, nil
+	}
+	for _, src := range r.getGuildSources() {
+		c, err := src.GetGuildCommands(guildID)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, c...)
+	}
+	return cmds, nil
+}
@@ -0,0 +1,72 @@
+package rosetta
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TestGuildSource struct {
+	cmds map[string]Command
+	err  error
+}
+
+func (t *TestGuildSource) GetGuildCommand(guildID, invoke string) (Command, bool, error) {
+	cmd, ok := t.cmds[guildID+":"+invoke]
+	return cmd, ok, t.err
+}
+
+func (t *TestGuildSource) GetGuildCommands(guildID string) ([]Command, error) {
+	cmds := make([]Command, 0)
+	for k, c := range t.cmds {
+		if k[:len(guildID)] == guildID {
+			cmds = append(cmds, c)
+		}
+	}
+	return cmds, t.err
+}
+
+func makeTestStateSession(t *testing.T) *discordgo.Session {
+	s := &discordgo.Session{State: discordgo.NewState()}
+	s.State.User = &discordgo.User{ID: "bot"}
+	require.NoError(t, s.State.GuildAdd(&discordgo.Guild{
+		ID:       "1",
+		Channels: []*discordgo.Channel{{ID: "10", GuildID: "1", Type: discordgo.ChannelTypeGuildText}},
+	}))
+	return s
+}
+
+func TestRouter_GuildCommands(t *testing.T) {
+	var errType ErrorType = -1
+	cfg := makeTestConfig()
+	cfg.OnError = func(_ Context, t ErrorType, _ error) { errType = t }
+	r, _ := NewRouter(cfg).(*router)
+
+	ping, faq := &TestCmd{}, &TestCmd{}
+	src := &TestGuildSource{cmds: map[string]Command{"1:faq": faq, "1:ping": &TestCmd{}}}
+	r.Register(ping)
+	r.Register(src)
+
+	cmds, err := r.GetGuildCommands("1")
+	assert.NoError(t, err)
+	assert.Len(t, cmds, 2)
+	_, ok, _ := r.GetGuildCommand("", "faq")
+	assert.False(t, ok)
+
+	s := makeTestStateSession(t)
+	msg := &discordgo.Message{ID: "100", ChannelID: "10", GuildID: "1", Author: &discordgo.User{ID: "2"}}
+
+	// registered commands take precedence.
+	r.handleMessage(s, msg, nil, "!", "ping", false)
+	assert.True(t, ping.executed)
+
+	r.handleMessage(s, msg, nil, "!", "faq a b", false)
+	assert.True(t, faq.executed)
+
+	src.err = errors.New("test error")
+	r.handleMessage(s, msg, nil, "!", "abc", false)
+	assert.Equal(t, ErrTypeGetGuildCommand, errType)
+}
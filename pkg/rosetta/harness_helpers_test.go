@@ -0,0 +1,116 @@
+package rosetta_test
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/rosettatest"
+)
+
+// testCmd is a configurable command for tests driving a router with rosettatest. By default
+// it's invoked by `ping` and responds with `pong` followed by its arguments.
+type testCmd struct {
+	invoke string
+	group  string
+	hidden bool
+	exec   func(ctx rosetta.Context) error
+
+	guildOnly, ownerOnly, nsfw bool
+	member, bot                int64
+}
+
+func (c *testCmd) GetInvokers() []string {
+	if c.invoke == "" {
+		return []string{"ping"}
+	}
+	return []string{c.invoke}
+}
+
+func (c *testCmd) GetDescription() string {
+	return "ping pong"
+}
+
+func (c *testCmd) GetUsage() string {
+	return "`ping` - ping"
+}
+
+func (c *testCmd) GetGroup() string {
+	if c.group == "" {
+		return rosetta.GroupFun
+	}
+	return c.group
+}
+
+func (c *testCmd) GetDomain() string {
+	return "test." + c.GetInvokers()[0]
+}
+
+func (c *testCmd) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (c *testCmd) IsExecutableInDM() bool {
+	return true
+}
+
+func (c *testCmd) IsHidden() bool {
+	return c.hidden
+}
+
+func (c *testCmd) IsGuildOnly() bool {
+	return c.guildOnly
+}
+
+func (c *testCmd) IsOwnerOnly() bool {
+	return c.ownerOnly
+}
+
+func (c *testCmd) IsNSFW() bool {
+	return c.nsfw
+}
+
+func (c *testCmd) GetMemberPermissions() int64 {
+	return c.member
+}
+
+func (c *testCmd) GetBotPermissions() int64 {
+	return c.bot
+}
+
+func (c *testCmd) Exec(ctx rosetta.Context) error {
+	if c.exec != nil {
+		return c.exec(ctx)
+	}
+	_, err := ctx.RespondText("pong " + ctx.GetArguments().Raw())
+	return err
+}
+
+// makeHarness returns a harness with a router created from given config, which has
+// testCmd registered. Our state has guild 1 with text channel 10, a DM channel 20 and
+// the member `user` without roles. If config is nil, rosetta.NewDefaultConfig is used
+// without its OnError.
+func makeHarness(t *testing.T, cfg *rosetta.Config) (*rosettatest.Harness, *discordgo.User) {
+	if cfg == nil {
+		cfg = rosetta.NewDefaultConfig()
+		cfg.OnError = nil
+	}
+	h := rosettatest.New(t, cfg)
+	h.Router.Register(&testCmd{})
+	h.AddGuild(&discordgo.Guild{ID: "1", Name: "guild"})
+	h.AddRole("1", &discordgo.Role{ID: "1", Permissions: discordgo.PermissionViewChannel | discordgo.PermissionSendMessages})
+	h.AddChannel(&discordgo.Channel{ID: "10", GuildID: "1", Type: discordgo.ChannelTypeGuildText})
+	user := &discordgo.User{ID: "2", Username: "user"}
+	h.AddMember(&discordgo.Member{GuildID: "1", User: user})
+	h.AddChannel(&discordgo.Channel{ID: "20", Type: discordgo.ChannelTypeDM, Recipients: []*discordgo.User{user}})
+	return h, user
+}
+
+// addManager adds a member managing guild 1 to the state of given harness.
+func addManager(h *rosettatest.Harness) *discordgo.User {
+	h.AddRole("1", &discordgo.Role{ID: "manager", Permissions: discordgo.PermissionManageServer})
+	manager := &discordgo.User{ID: "3", Username: "manager"}
+	h.AddMember(&discordgo.Member{GuildID: "1", User: manager, Roles: []string{"manager"}})
+	return manager
+}
@@ -4,14 +4,49 @@ package rosetta
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-type DefaultHelpCommand struct{}
+const (
+	// helpPageTTL defines how long the pages of a help message can be navigated.
+	helpPageTTL = 5 * time.Minute
+
+	// helpFieldLength defines the maximum length of a group field, which is the limit of discord.
+	helpFieldLength = 1024
+
+	helpEmotePrev = "◀"
+	helpEmoteNext = "▶"
+	helpFooter    = "with :hearts: and :coffee: by iridaceae"
+)
+
+// DefaultHelpCommand lists commands the invoking user can execute, one page per group, or
+// describes a specific command. Pages are navigated by reactions, thus the command has to be
+// registered as EventHandler as well. NewRouter does so when Config.UseDefaultHelpCommand is set.
+type DefaultHelpCommand struct {
+	mu    sync.Mutex
+	pages map[string]*helpPages
+}
+
+// helpPages holds the pages of a sent help message.
+type helpPages struct {
+	userID  string
+	embeds  []*discordgo.MessageEmbed
+	current int
+	expires time.Time
+}
+
+// helpGroup holds the rendered command entries of a group.
+type helpGroup struct {
+	name    string
+	entries []string
+}
 
 func (d *DefaultHelpCommand) GetInvokers() []string {
 	return []string{"help", "h", "?", "man"}
@@ -22,7 +57,10 @@ func (d *DefaultHelpCommand) GetDescription() string {
 }
 
 func (d *DefaultHelpCommand) GetUsage() string {
-	return "`help` - display command list\n" + "`help <command>` - display help of a specific command"
+	return "`help` - display command list\n" +
+		"`help <group>` - display commands of a group\n" +
+		"`help <command>` - display help of a specific command\n" +
+		"`help <command> <sub command>...` - display help of a specific sub command"
 }
 
 func (d *DefaultHelpCommand) GetGroup() string {
@@ -42,125 +80,364 @@ func (d *DefaultHelpCommand) IsExecutableInDM() bool {
 }
 
 func (d *DefaultHelpCommand) Exec(ctx Context) error {
-	embed := &discordgo.MessageEmbed{
-		Color:     EmbedColorDefault,
-		Fields:    make([]*discordgo.MessageEmbedField, 0),
-		Timestamp: time.RFC3339,
-		Footer:    &discordgo.MessageEmbedFooter{Text: "with :hearts: and :coffee: by iridaceae"},
-	}
-
 	rr, _ := ctx.GetObject(ObjectMapKeyRouter).(Router)
 
 	if ctx.GetArguments().Len() == 0 {
-		// we will send a general help to dms.
-		cmds := make(map[string][]Command)
-		for _, c := range rr.GetCommandInstances() {
-			group := c.GetGroup()
-			if _, ok := cmds[group]; !ok {
-				cmds[group] = make([]Command, 0)
-			}
-			cmds[group] = append(cmds[group], c)
+		groups, err := getHelpGroups(rr, ctx)
+		if err != nil {
+			return err
 		}
+		return d.send(ctx, buildHelpPages(ctx, groups))
+	}
 
-		embed.Title = fmt.Sprintf("Command List for %s", ctx.GetGuild().Name)
-
-		for group, groupCmds := range cmds {
-			cmdInfo := ""
-			for i, c := range groupCmds {
-				cmdInfo += fmt.Sprintf("%d. `%s` - *%s* `[%s]`", i, c.GetInvokers()[0], c.GetDescription(), c.GetDomain())
-			}
-			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: group, Value: cmdInfo})
-		}
-	} else {
-		// specific commands we want to render
-		invoke := ctx.GetArguments().Get(0).String()
-		cmd, ok := rr.GetCommand(invoke)
-		if !ok {
-			_, err := ctx.RespondEmbedError(fmt.Sprintf("No command was found with given invoke `%s`.", invoke), ErrInvokeDoesNotExists)
+	// specific commands we want to render, walking down sub commands if given.
+	cmd, depth, ok := rr.ResolveCommand(ctx.GetArguments().Args())
+	if !ok && ctx.GetGuild() != nil {
+		cmd, ok, _ = rr.GetGuildCommand(ctx.GetGuild().ID, ctx.GetArguments().Get(0).String())
+		depth = 1
+	}
+	if ok && depth == ctx.GetArguments().Len() {
+		// commands which aren't listed for the user are treated as unknown.
+		listed, err := isListed(rr, ctx, cmd)
+		if err != nil {
 			return err
 		}
+		if listed {
+			return d.send(ctx, []*discordgo.MessageEmbed{buildCommandHelp(rr, ctx, cmd)})
+		}
+	}
+
+	// groups are matched after commands, e.g. `help guild admin`.
+	invoke := strings.Join(argsToStrings(ctx.GetArguments().Args()), " ")
+	groups, err := getHelpGroups(rr, ctx)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if strings.EqualFold(g.name, invoke) {
+			return d.send(ctx, buildHelpPages(ctx, []helpGroup{g}))
+		}
+	}
+	_, err = ctx.RespondEmbedError(ctx.T("rosetta.help.not_found", invoke), ErrInvokeDoesNotExists)
+	return err
+}
+
+// GetEvents lets DefaultHelpCommand navigate pages as EventHandler.
+func (d *DefaultHelpCommand) GetEvents() []interface{} {
+	return []interface{}{&discordgo.MessageReactionAdd{}}
+}
+
+// HandleEvent turns the page of a help message when its invoking user reacts with an arrow.
+func (d *DefaultHelpCommand) HandleEvent(ctx EventContext, event interface{}) error {
+	e, ok := event.(*discordgo.MessageReactionAdd)
+	if !ok || e.MessageReaction == nil {
+		return nil
+	}
+
+	var delta int
+	switch e.Emoji.Name {
+	case helpEmotePrev:
+		delta = -1
+	case helpEmoteNext:
+		delta = 1
+	default:
+		return nil
+	}
+
+	d.mu.Lock()
+	p, ok := d.pages[e.MessageID]
+	if !ok || p.userID != e.UserID || time.Now().After(p.expires) {
+		d.mu.Unlock()
+		return nil
+	}
+	p.current = (p.current + delta + len(p.embeds)) % len(p.embeds)
+	p.expires = time.Now().Add(helpPageTTL)
+	embed := p.embeds[p.current]
+	d.mu.Unlock()
+
+	s := ctx.GetSession()
+	// reactions of other users can't be removed in DMs.
+	if e.GuildID != "" {
+		_ = s.MessageReactionRemove(e.ChannelID, e.MessageID, e.Emoji.Name, e.UserID)
+	}
+	_, err := s.ChannelMessageEditEmbed(e.ChannelID, e.MessageID, embed)
+	return err
+}
+
+// send sends the first of given pages to the DMs of the invoking user, falling back to the
+// invoking channel if the user doesn't accept DMs. Multiple pages get navigation reactions.
+func (d *DefaultHelpCommand) send(ctx Context, pages []*discordgo.MessageEmbed) error {
+	msg, err := ctx.Respond().Embed(pages[0]).DM().OnDMFallback(func() {
+		for _, p := range pages {
+			p.Footer = &discordgo.MessageEmbedFooter{Text: ctx.T("rosetta.help.dms_disabled")}
+		}
+	}).Send()
+	if err != nil || len(pages) < 2 {
+		return err
+	}
+
+	s := ctx.GetSession()
+	d.track(msg.ID, ctx.GetUser().ID, pages)
+	_ = s.MessageReactionAdd(msg.ChannelID, msg.ID, helpEmotePrev)
+	_ = s.MessageReactionAdd(msg.ChannelID, msg.ID, helpEmoteNext)
+	return nil
+}
+
+// track remembers given pages of a sent message and forgets expired ones.
+func (d *DefaultHelpCommand) track(msgID, userID string, pages []*discordgo.MessageEmbed) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if d.pages == nil {
+		d.pages = make(map[string]*helpPages)
+	}
+	for id, p := range d.pages {
+		if now.After(p.expires) {
+			delete(d.pages, id)
+		}
+	}
+	d.pages[msgID] = &helpPages{userID: userID, embeds: pages, expires: now.Add(helpPageTTL)}
+}
+
+// getHelpGroups returns the commands the invoking user can execute sorted by group and invoker.
+// Hidden commands are left out.
+func getHelpGroups(rr Router, ctx Context) ([]helpGroup, error) {
+	instances := rr.GetCommandInstances()
+	if guild := ctx.GetGuild(); guild != nil {
+		guildCmds, err := rr.GetGuildCommands(guild.ID)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, guildCmds...)
+	}
+	sort.SliceStable(instances, func(i, j int) bool {
+		return instances[i].GetInvokers()[0] < instances[j].GetInvokers()[0]
+	})
+
+	cmds := make(map[string][]Command)
+	for _, c := range instances {
+		if ok, err := isListed(rr, ctx, c); err != nil {
+			return nil, err
+		} else if ok {
+			cmds[c.GetGroup()] = append(cmds[c.GetGroup()], c)
+		}
+	}
 
-		embed.Title = "Command Description"
-		description := cmd.GetDescription()
-		if description == "" {
-			description = "`no description`"
-		}
-
-		usage := cmd.GetUsage()
-		if usage == "" {
-			usage = "`no usage information`"
-		}
-
-		embed.Fields = []*discordgo.MessageEmbedField{
-			{
-				Name:   "Invokers",
-				Value:  strings.Join(cmd.GetInvokers(), " "),
-				Inline: true,
-			},
-			{
-				Name:   "Group",
-				Value:  cmd.GetGroup(),
-				Inline: true,
-			},
-			{
-				Name:   "Domain",
-				Value:  cmd.GetDomain(),
-				Inline: true,
-			},
-			{
-				Name:   "IsDM-able",
-				Value:  strconv.FormatBool(cmd.IsExecutableInDM()),
-				Inline: true,
-			},
-			{
-				Name:  "Description",
-				Value: description,
-			},
-			{
-				Name:  "Usage",
-				Value: usage,
-			},
-		}
-
-		if spr := cmd.GetSubPermissionRules(); spr != nil {
-			txt := "*`[E]` means explicit permissions, or permission must be explicitly allowed and cannot be wild-carded.\n" +
-				"`[NE]` means non-explicit permissions, or wildcards will apply to this sub permission.*\n\n"
-
-			for _, rule := range spr {
-				expl := "NE"
-				if rule.Explicit {
-					expl = "E"
-				}
-				txt = fmt.Sprintf("%s`[%s]` %s - *%s*\n", txt, expl, getTermAssembly(cmd, rule.Term), rule.Description)
+	groups := make([]helpGroup, 0, len(cmds))
+	for name, groupCmds := range cmds {
+		g := helpGroup{name: name, entries: make([]string, len(groupCmds))}
+		for i, c := range groupCmds {
+			tree, err := getSubCommandTree(rr, ctx, c, c.GetInvokers()[0], 1)
+			if err != nil {
+				return nil, err
 			}
+			g.entries[i] = fmt.Sprintf("%d. `%s` - *%s* `[%s]`%s", i+1, c.GetInvokers()[0], CommandDescription(ctx, c), c.GetDomain(), tree)
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	return groups, nil
+}
 
-			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-				Name:  "Sub Permission Rules",
-				Value: txt,
+// isListed returns true if given command isn't hidden and can be executed by the invoking user.
+func isListed(rr Router, ctx Context, cmd Command) (bool, error) {
+	if h, ok := cmd.(HiddenCommand); ok && h.IsHidden() {
+		return false, nil
+	}
+	return rr.CanExecute(cmd, ctx)
+}
+
+// buildHelpPages returns a page per group, splitting groups whose entries exceed a field.
+func buildHelpPages(ctx Context, groups []helpGroup) []*discordgo.MessageEmbed {
+	title := ctx.T("rosetta.help.list_title")
+	if guild := ctx.GetGuild(); guild != nil && !ctx.IsDM() {
+		title = ctx.T("rosetta.help.list_title_guild", guild.Name)
+	}
+
+	pages := make([]*discordgo.MessageEmbed, 0, len(groups))
+	for _, g := range groups {
+		for _, value := range splitHelpEntries(g.entries, helpFieldLength) {
+			pages = append(pages, &discordgo.MessageEmbed{
+				Title:     title,
+				Color:     EmbedColorDefault,
+				Fields:    []*discordgo.MessageEmbedField{{Name: g.name, Value: value}},
+				Timestamp: time.Now().Format(time.RFC3339),
 			})
 		}
 	}
+	if len(pages) == 0 {
+		pages = append(pages, &discordgo.MessageEmbed{
+			Title:       title,
+			Color:       EmbedColorDefault,
+			Description: ctx.T("rosetta.help.no_commands"),
+			Timestamp:   time.Now().Format(time.RFC3339),
+		})
+	}
 
-	channel, err := ctx.GetSession().UserChannelCreate(ctx.GetUser().ID)
-	if err != nil {
-		return err
+	for i, p := range pages {
+		p.Footer = &discordgo.MessageEmbedFooter{Text: helpFooter}
+		if len(pages) > 1 {
+			p.Footer.Text = ctx.T("rosetta.help.page", i+1, len(pages)) + " • " + helpFooter
+		}
 	}
-	_, err = ctx.GetSession().ChannelMessageSendEmbed(channel.ID, embed)
-	if err != nil {
-		if strings.Contains(err.Error(), `{"code": 50007, "message": "Cannot send messages to this user"}`) {
-			embed.Footer = &discordgo.MessageEmbedFooter{
-				Text: "This message appears in DMs, but you have disabled `receiving DMs from server members`",
+	return pages
+}
+
+// splitHelpEntries joins given entries by newlines into chunks not exceeding max bytes.
+// Entries exceeding max on their own are truncated.
+func splitHelpEntries(entries []string, max int) []string {
+	chunks := make([]string, 0, 1)
+	var chunk strings.Builder
+	for _, e := range entries {
+		e = truncate(e, max)
+		if chunk.Len() > 0 && chunk.Len()+1+len(e) > max {
+			chunks = append(chunks, chunk.String())
+			chunk.Reset()
+		}
+		if chunk.Len() > 0 {
+			chunk.WriteByte('\n')
+		}
+		chunk.WriteString(e)
+	}
+	if chunk.Len() > 0 {
+		chunks = append(chunks, chunk.String())
+	}
+	return chunks
+}
+
+// truncate shortens given string to max bytes without splitting a rune, marking cuts with an ellipsis.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	const ellipsis = "…"
+	s = s[:max-len(ellipsis)]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s + ellipsis
+}
+
+// buildCommandHelp returns an embed describing given command.
+func buildCommandHelp(rr Router, ctx Context, cmd Command) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:     ctx.T("rosetta.help.command_title"),
+		Color:     EmbedColorDefault,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer:    &discordgo.MessageEmbedFooter{Text: helpFooter},
+	}
+
+	description := CommandDescription(ctx, cmd)
+	if description == "" {
+		description = ctx.T("rosetta.help.no_description")
+	}
+
+	// the schema is what arguments are validated against, so it's preferred over free-form usage.
+	usage := CommandUsage(ctx, cmd)
+	if sc, ok := cmd.(SchemaCommand); ok {
+		usage = sc.GetSchema().Usage(strings.Join(argsToStrings(ctx.GetArguments().Args()), " "))
+	}
+	if usage == "" {
+		usage = ctx.T("rosetta.help.no_usage")
+	}
+
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   ctx.T("rosetta.help.invokers"),
+			Value:  strings.Join(cmd.GetInvokers(), " "),
+			Inline: true,
+		},
+		{
+			Name:   ctx.T("rosetta.help.group"),
+			Value:  cmd.GetGroup(),
+			Inline: true,
+		},
+		{
+			Name:   ctx.T("rosetta.help.domain"),
+			Value:  cmd.GetDomain(),
+			Inline: true,
+		},
+		{
+			Name:   ctx.T("rosetta.help.dm"),
+			Value:  strconv.FormatBool(cmd.IsExecutableInDM()),
+			Inline: true,
+		},
+		{
+			Name:  ctx.T("rosetta.help.description"),
+			Value: description,
+		},
+		{
+			Name:  ctx.T("rosetta.help.usage"),
+			Value: usage,
+		},
+	}
+
+	if spr := cmd.GetSubPermissionRules(); spr != nil {
+		txt := ctx.T("rosetta.help.sub_permissions_legend") + "\n\n"
+
+		for _, rule := range spr {
+			expl := "NE"
+			if rule.Explicit {
+				expl = "E"
 			}
-			_, err = ctx.GetSession().ChannelMessageSendEmbed(ctx.GetChannel().ID, embed)
+			txt = fmt.Sprintf("%s`[%s]` %s - *%s*\n", txt, expl, GetTermAssembly(cmd, rule.Term), rule.Description)
 		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  ctx.T("rosetta.help.sub_permissions"),
+			Value: txt,
+		})
 	}
-	return err
+
+	if p, ok := cmd.(ParentCommand); ok {
+		path := strings.Join(argsToStrings(ctx.GetArguments().Args()), " ")
+		entries := make([]string, 0, len(p.GetSubCommands()))
+		for _, sub := range p.GetSubCommands() {
+			if ok, _ := isListed(rr, ctx, sub); ok {
+				entries = append(entries, fmt.Sprintf("`%s %s` - *%s*", path, sub.GetInvokers()[0], CommandDescription(ctx, sub)))
+			}
+		}
+		if len(entries) > 0 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  ctx.T("rosetta.help.sub_commands"),
+				Value: truncate(strings.Join(entries, "\n"), helpFieldLength),
+			})
+		}
+	}
+	return embed
 }
 
-// parse our SubPermissions rules to our domain.
-func getTermAssembly(cmd Command, term string) string {
+// GetTermAssembly parses given SubPermission term to its full domain. Terms prefixed
+// with `/` are treated as absolute, otherwise they are appended to the command domain.
+func GetTermAssembly(cmd Command, term string) string {
 	if strings.HasPrefix(term, "/") {
 		return term[1:]
 	}
 	return cmd.GetDomain() + "." + term
 }
+
+// getSubCommandTree renders children of given command, which are listed to the invoking user,
+// as an indented tree prefixed with the invokers path.
+func getSubCommandTree(rr Router, ctx Context, cmd Command, path string, level int) (string, error) {
+	p, ok := cmd.(ParentCommand)
+	if !ok {
+		return "", nil
+	}
+	tree := ""
+	for _, sub := range p.GetSubCommands() {
+		if ok, err := isListed(rr, ctx, sub); err != nil || !ok {
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+		subPath := path + " " + sub.GetInvokers()[0]
+		subTree, err := getSubCommandTree(rr, ctx, sub, subPath, level+1)
+		if err != nil {
+			return "", err
+		}
+		tree += fmt.Sprintf("\n%s↳ `%s` - *%s* `[%s]`%s", strings.Repeat("  ", level), subPath, CommandDescription(ctx, sub), sub.GetDomain(), subTree)
+	}
+	return tree, nil
+}
@@ -0,0 +1,130 @@
+package rosetta_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/rosettatest"
+)
+
+// denyChecker denies commands with domain test.denied.
+type denyChecker struct{}
+
+func (d *denyChecker) Handle(cmd rosetta.Command, ctx rosetta.Context, layer rosetta.MiddlewareLayer) (bool, error) {
+	return d.CanExecute(cmd, ctx)
+}
+
+func (d *denyChecker) GetLayer() rosetta.MiddlewareLayer {
+	return rosetta.LayerBeforeCommand
+}
+
+func (d *denyChecker) CanExecute(cmd rosetta.Command, _ rosetta.Context) (bool, error) {
+	return cmd.GetDomain() != "test.denied", nil
+}
+
+func TestDefaultHelpCommand_List(t *testing.T) {
+	h, user := makeHarness(t, nil)
+	h.Router.Register(&denyChecker{})
+	h.Router.Register(&testCmd{invoke: "guild", guildOnly: true})
+	h.Router.Register(&testCmd{invoke: "secret", group: rosetta.GroupChat, hidden: true})
+	h.Router.Register(&testCmd{invoke: "denied", group: rosetta.GroupChat})
+	for i := 0; i < 20; i++ {
+		h.Router.Register(&testCmd{invoke: fmt.Sprintf("chat%02d-%s", i, "a long invoker of a chat command"), group: rosetta.GroupChat})
+	}
+
+	h.Send(user, "10", "r!help")
+	h.ExpectNoErrors()
+	sent := h.Sent()
+	require.Len(t, sent, 1)
+	msg := sent[0]
+	assert.NotEqual(t, "10", msg.ChannelID)
+	embed := msg.Embed()
+	assert.Equal(t, "Command List for guild", embed.Title)
+	assert.Equal(t, rosetta.GroupChat, embed.Fields[0].Name)
+	assert.Contains(t, embed.Fields[0].Value, "1. `chat00-")
+	assert.Contains(t, embed.Fields[0].Value, "\n2. `chat01-")
+	assert.LessOrEqual(t, len(embed.Fields[0].Value), 1024)
+	assert.NotContains(t, embed.Fields[0].Value, "secret")
+	assert.NotContains(t, embed.Fields[0].Value, "denied")
+	assert.Regexp(t, `^Page 1/\d+ `, embed.Footer.Text)
+	h.ExpectReaction(msg.MessageID, "▶")
+
+	// reactions of other users are ignored, the invoking user turns the page.
+	h.Reset()
+	react := func(userID, emoji string) {
+		h.Trigger(&discordgo.MessageReactionAdd{MessageReaction: &discordgo.MessageReaction{
+			UserID: userID, MessageID: msg.MessageID, ChannelID: msg.ChannelID, Emoji: discordgo.Emoji{Name: emoji},
+		}})
+	}
+	react("3", "▶")
+	assert.Empty(t, h.Filter(rosettatest.ActionEdit))
+	react(user.ID, "▶")
+	edits := h.Filter(rosettatest.ActionEdit)
+	require.Len(t, edits, 1)
+	assert.Regexp(t, `^Page 2/\d+ `, edits[0].Embed().Footer.Text)
+	react(user.ID, "◀")
+	react(user.ID, "◀")
+	edits = h.Filter(rosettatest.ActionEdit)
+	require.Len(t, edits, 3)
+	assert.NotContains(t, edits[2].Embed().Footer.Text, "Page 1/")
+
+	// a single group fits on one page without navigation.
+	h.Reset()
+	h.Send(user, "10", "r!help fun")
+	sent = h.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, rosetta.GroupFun, sent[0].Embed().Fields[0].Name)
+	assert.Contains(t, sent[0].Embed().Fields[0].Value, "`guild`")
+	assert.Equal(t, "with :hearts: and :coffee: by iridaceae", sent[0].Embed().Footer.Text)
+	assert.Empty(t, h.Filter(rosettatest.ActionReact))
+
+	// in DMs commands which aren't executable in DMs are left out.
+	h.Reset()
+	h.Send(user, "20", "r!help")
+	h.ExpectNoErrors()
+	h.ExpectEmbedTitle("Command List")
+	for _, a := range h.Sent() {
+		for _, f := range a.Embed().Fields {
+			assert.NotContains(t, f.Value, "`guild`")
+		}
+	}
+
+	h.Reset()
+	h.Send(user, "10", "r!help nothing")
+	h.ExpectEmbedTitle("No command or group was found with given invoke `nothing`.")
+}
+
+func TestDefaultHelpCommand_Unlisted(t *testing.T) {
+	h, user := makeHarness(t, nil)
+	h.Router.Register(&denyChecker{})
+	h.Router.Register(&testCmd{invoke: "secret", hidden: true})
+	h.Router.Register(&testCmd{invoke: "denied"})
+	h.Router.Register(&testCmd{invoke: "guild", guildOnly: true})
+
+	// commands which aren't listed are reported just like unknown ones.
+	for _, tt := range []struct{ channelID, invoke string }{{"10", "secret"}, {"10", "denied"}, {"20", "guild"}} {
+		h.Reset()
+		h.Send(user, tt.channelID, "r!help "+tt.invoke)
+		h.ExpectNoErrors()
+		h.ExpectEmbedTitle(fmt.Sprintf("No command or group was found with given invoke `%s`.", tt.invoke))
+	}
+
+	h.Reset()
+	h.Send(user, "10", "r!help guild")
+	h.ExpectNoErrors()
+	h.ExpectEmbedTitle("Command Description")
+
+	// neither are they suggested for unknown commands, thus nothing is close to `secre`.
+	h.Reset()
+	h.Send(user, "10", "r!secre")
+	h.ExpectNoResponse()
+	h.Reset()
+	h.Send(user, "10", "r!pin")
+	require.Len(t, h.Sent(), 1)
+	assert.Contains(t, h.Sent()[0].Embed().Description, "r!ping")
+}
@@ -1,8 +1,11 @@
 package rosetta
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/Iridaceae/iridaceae/pkg/helpers"
 )
 
@@ -40,3 +43,28 @@ func TestDefaultHelpCommand_Exec(t *testing.T) {
 	r.Register(help)
 	r.(*router).trigger(ctx.session, msg)
 }
+
+func TestSplitHelpEntries(t *testing.T) {
+	assert.Empty(t, splitHelpEntries(nil, 10))
+	assert.Equal(t, []string{"abc\ndef", "ghi"}, splitHelpEntries([]string{"abc", "def", "ghi"}, 7))
+	assert.Equal(t, []string{"abc", "defghi…"}, splitHelpEntries([]string{"abc", "defghijklmn"}, 9))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abc", 3))
+	assert.Equal(t, "a…", truncate("abcdef", 4))
+	// multi byte runes are not split.
+	assert.Equal(t, "…", truncate(strings.Repeat("↳", 3), 5))
+}
+
+func TestBuildCommandHelp_Schema(t *testing.T) {
+	r := NewRouter(makeTestConfig())
+	ctx := makeTestCtx(false, false)
+	ctx.args = ParseArguments("ping")
+
+	// the schema replaces the free-form usage of a command.
+	cmd := &TestSchemaCmd{schema: NewSchema().Int("amount", "how often")}
+	embed := buildCommandHelp(r, ctx, cmd)
+	assert.Equal(t, "`ping <amount>`\n`amount` (*number*) - how often", embed.Fields[5].Value)
+	assert.Equal(t, cmd.GetUsage(), buildCommandHelp(r, ctx, &TestCmd{}).Fields[5].Value)
+}
@@ -0,0 +1,459 @@
+package rosetta
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// discordgo doesn't ship application commands yet, thus we define the minimal
+// subset of the interactions API rosetta needs here.
+
+const (
+	// SlashArgumentsOptionName is the name of the free-form option which is generated for commands
+	// that don't define their own options. Its value is parsed the same way as a message would be.
+	SlashArgumentsOptionName = "arguments"
+
+	eventInteractionCreate = "INTERACTION_CREATE"
+)
+
+// SlashCommandNameRegex defines regex application command and option names should match.
+var SlashCommandNameRegex = regexp.MustCompile(`^[\w-]{1,32}$`)
+
+var (
+	// EndpointApplicationGlobalCommands returns endpoint for global application commands.
+	EndpointApplicationGlobalCommands = func(aID string) string {
+		return discordgo.EndpointAPI + "applications/" + aID + "/commands"
+	}
+
+	// EndpointApplicationGuildCommands returns endpoint for application commands of a guild.
+	EndpointApplicationGuildCommands = func(aID, gID string) string {
+		return discordgo.EndpointAPI + "applications/" + aID + "/guilds/" + gID + "/commands"
+	}
+
+	// EndpointInteractionResponse returns endpoint for the initial interaction response.
+	EndpointInteractionResponse = func(iID, token string) string {
+		return discordgo.EndpointAPI + "interactions/" + iID + "/" + token + "/callback"
+	}
+
+	// EndpointInteractionOriginal returns endpoint for editing or deleting the initial interaction response.
+	EndpointInteractionOriginal = func(aID, token string) string {
+		return discordgo.EndpointWebhookToken(aID, token) + "/messages/@original"
+	}
+)
+
+// InteractionType defines type of an interaction.
+type InteractionType int
+
+const (
+	InteractionPing InteractionType = iota + 1
+	InteractionApplicationCommand
+)
+
+// ApplicationCommandOptionType defines type of an application command option.
+type ApplicationCommandOptionType int
+
+const (
+	OptionTypeSubCommand ApplicationCommandOptionType = iota + 1
+	OptionTypeSubCommandGroup
+	OptionTypeString
+	OptionTypeInteger
+	OptionTypeBoolean
+	OptionTypeUser
+	OptionTypeChannel
+	OptionTypeRole
+)
+
+// InteractionResponseType defines how we respond to an interaction.
+type InteractionResponseType int
+
+const (
+	InteractionResponsePong                             InteractionResponseType = 1
+	InteractionResponseChannelMessageWithSource         InteractionResponseType = 4
+	InteractionResponseDeferredChannelMessageWithSource InteractionResponseType = 5
+)
+
+// Interaction represents an INTERACTION_CREATE payload.
+type Interaction struct {
+	ID            string                 `json:"id"`
+	ApplicationID string                 `json:"application_id"`
+	Type          InteractionType        `json:"type"`
+	Data          InteractionCommandData `json:"data"`
+	GuildID       string                 `json:"guild_id"`
+	ChannelID     string                 `json:"channel_id"`
+	Member        *discordgo.Member      `json:"member"`
+	User          *discordgo.User        `json:"user"`
+	Token         string                 `json:"token"`
+	Version       int                    `json:"version"`
+}
+
+// InteractionCommandData contains the invoked application command and its options.
+type InteractionCommandData struct {
+	ID      string                      `json:"id"`
+	Name    string                      `json:"name"`
+	Options []*InteractionCommandOption `json:"options"`
+}
+
+// InteractionCommandOption is a single option value sent with an interaction.
+type InteractionCommandOption struct {
+	Name    string                       `json:"name"`
+	Type    ApplicationCommandOptionType `json:"type"`
+	Value   interface{}                  `json:"value,omitempty"`
+	Options []*InteractionCommandOption  `json:"options,omitempty"`
+}
+
+// ApplicationCommand defines a command registered to discord.
+type ApplicationCommand struct {
+	ID            string                      `json:"id,omitempty"`
+	ApplicationID string                      `json:"application_id,omitempty"`
+	Name          string                      `json:"name"`
+	Description   string                      `json:"description"`
+	Options       []*ApplicationCommandOption `json:"options,omitempty"`
+}
+
+// ApplicationCommandOption defines an option of a ApplicationCommand.
+type ApplicationCommandOption struct {
+	Type        ApplicationCommandOptionType      `json:"type"`
+	Name        string                            `json:"name"`
+	Description string                            `json:"description"`
+	Required    bool                              `json:"required,omitempty"`
+	Choices     []*ApplicationCommandOptionChoice `json:"choices,omitempty"`
+	Options     []*ApplicationCommandOption       `json:"options,omitempty"`
+}
+
+// ApplicationCommandOptionChoice defines a predefined value of an option.
+type ApplicationCommandOptionChoice struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// InteractionResponse is sent as callback of an interaction.
+type InteractionResponse struct {
+	Type InteractionResponseType  `json:"type"`
+	Data *InteractionResponseData `json:"data,omitempty"`
+}
+
+// InteractionResponseData defines message content of an interaction response or followup.
+type InteractionResponseData struct {
+	Content         string                            `json:"content,omitempty"`
+	Embeds          []*discordgo.MessageEmbed         `json:"embeds,omitempty"`
+	AllowedMentions *discordgo.MessageAllowedMentions `json:"allowed_mentions,omitempty"`
+	Flags           int                               `json:"flags,omitempty"`
+}
+
+// SlashCommand can be implemented by a Command to define its own application command options.
+// Otherwise a single optional SlashArgumentsOptionName string option will be generated.
+type SlashCommand interface {
+
+	// GetApplicationCommandOptions returns options of given command.
+	GetApplicationCommandOptions() []*ApplicationCommandOption
+}
+
+// ApplicationCommandBulkOverwrite replaces all application commands of given application. If guildID is
+// empty, global commands are overwritten.
+func ApplicationCommandBulkOverwrite(s *discordgo.Session, appID, guildID string, cmds []*ApplicationCommand) ([]*ApplicationCommand, error) {
+	endpoint := EndpointApplicationGlobalCommands(appID)
+	if guildID != "" {
+		endpoint = EndpointApplicationGuildCommands(appID, guildID)
+	}
+	body, err := s.RequestWithBucketID("PUT", endpoint, cmds, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var res []*ApplicationCommand
+	err = json.Unmarshal(body, &res)
+	return res, err
+}
+
+// InteractionRespond sends the initial response of an interaction.
+func InteractionRespond(s *discordgo.Session, i *Interaction, resp *InteractionResponse) error {
+	endpoint := EndpointInteractionResponse(i.ID, i.Token)
+	_, err := s.RequestWithBucketID("POST", endpoint, resp, endpoint)
+	return err
+}
+
+// InteractionResponseEdit edits the initial response of an interaction.
+func InteractionResponseEdit(s *discordgo.Session, i *Interaction, data *InteractionResponseData) (*discordgo.Message, error) {
+	endpoint := EndpointInteractionOriginal(i.ApplicationID, i.Token)
+	body, err := s.RequestWithBucketID("PATCH", endpoint, data, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	msg := new(discordgo.Message)
+	err = json.Unmarshal(body, msg)
+	return msg, err
+}
+
+// InteractionResponseDelete deletes the initial response of an interaction.
+func InteractionResponseDelete(s *discordgo.Session, i *Interaction) error {
+	endpoint := EndpointInteractionOriginal(i.ApplicationID, i.Token)
+	_, err := s.RequestWithBucketID("DELETE", endpoint, nil, endpoint)
+	return err
+}
+
+// FollowupMessageCreate sends an additional message to an interaction.
+func FollowupMessageCreate(s *discordgo.Session, i *Interaction, data *InteractionResponseData) (*discordgo.Message, error) {
+	endpoint := discordgo.EndpointWebhookToken(i.ApplicationID, i.Token)
+	body, err := s.RequestWithBucketID("POST", endpoint+"?wait=true", data, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	msg := new(discordgo.Message)
+	err = json.Unmarshal(body, msg)
+	return msg, err
+}
+
+// GetApplicationCommands builds application commands from given command instances.
+// Commands which invoker can't be used as a slash command name are skipped.
+func GetApplicationCommands(cmds []Command) []*ApplicationCommand {
+	res := make([]*ApplicationCommand, 0, len(cmds))
+	for _, c := range cmds {
+		name := getSlashName(c)
+		if name == "" {
+			continue
+		}
+		res = append(res, &ApplicationCommand{
+			Name:        name,
+			Description: getSlashDescription(c),
+			Options:     getApplicationCommandOptions(c, 0),
+		})
+	}
+	return res
+}
+
+// getApplicationCommandOptions maps our command tree to sub command options. Discord only
+// allows sub command groups to hold sub commands, thus deeper children are skipped.
+func getApplicationCommandOptions(cmd Command, level int) []*ApplicationCommandOption {
+	if sc, ok := cmd.(SlashCommand); ok {
+		return sc.GetApplicationCommandOptions()
+	}
+	if sc, ok := cmd.(SchemaCommand); ok {
+		return getSchemaOptions(sc.GetSchema())
+	}
+	if p, ok := cmd.(ParentCommand); ok && len(p.GetSubCommands()) > 0 && level < 2 {
+		opts := make([]*ApplicationCommandOption, 0)
+		for _, sub := range p.GetSubCommands() {
+			name := getSlashName(sub)
+			if name == "" {
+				continue
+			}
+			opt := &ApplicationCommandOption{
+				Type:        OptionTypeSubCommand,
+				Name:        name,
+				Description: getSlashDescription(sub),
+				Options:     getApplicationCommandOptions(sub, level+1),
+			}
+			if len(opt.Options) > 0 && opt.Options[0].Type == OptionTypeSubCommand {
+				opt.Type = OptionTypeSubCommandGroup
+			}
+			opts = append(opts, opt)
+		}
+		return opts
+	}
+	return []*ApplicationCommandOption{{
+		Type:        OptionTypeString,
+		Name:        SlashArgumentsOptionName,
+		Description: "command arguments",
+	}}
+}
+
+// getSchemaOptions maps parameters to typed options. Types which discord doesn't know are sent as text.
+func getSchemaOptions(schema *Schema) []*ApplicationCommandOption {
+	opts := make([]*ApplicationCommandOption, 0, len(schema.Params()))
+	for _, p := range schema.Params() {
+		opt := &ApplicationCommandOption{
+			Type:        OptionTypeString,
+			Name:        strings.ToLower(p.Name),
+			Description: truncateSlashDescription(p.Description),
+			Required:    !p.Optional,
+		}
+		if opt.Description == "" {
+			opt.Description = p.Type.String()
+		}
+		if !p.Rest {
+			switch p.Type {
+			case ParamInt:
+				opt.Type = OptionTypeInteger
+			case ParamBool:
+				opt.Type = OptionTypeBoolean
+			case ParamUser:
+				opt.Type = OptionTypeUser
+			case ParamRole:
+				opt.Type = OptionTypeRole
+			case ParamChannel:
+				opt.Type = OptionTypeChannel
+			case ParamEnum:
+				for _, c := range p.Choices {
+					opt.Choices = append(opt.Choices, &ApplicationCommandOptionChoice{Name: c, Value: c})
+				}
+			default:
+			}
+		}
+		opts = append(opts, opt)
+	}
+	return opts
+}
+
+func getSlashName(cmd Command) string {
+	for _, i := range cmd.GetInvokers() {
+		i = strings.ToLower(i)
+		if SlashCommandNameRegex.MatchString(i) {
+			return i
+		}
+	}
+	return ""
+}
+
+func getSlashDescription(cmd Command) string {
+	desc := cmd.GetDescription()
+	if desc == "" {
+		desc = "no description"
+	}
+	return truncateSlashDescription(desc)
+}
+
+// truncateSlashDescription shortens given description of a command or option to the limit of discord.
+func truncateSlashDescription(desc string) string {
+	// descriptions are limited to 100 characters, not bytes.
+	if runes := []rune(desc); len(runes) > 100 {
+		desc = string(runes[:97]) + "..."
+	}
+	return desc
+}
+
+// getInteractionArguments flattens invoked command, sub commands and option values into Arguments,
+// so that they can be resolved the same way as a message.
+func getInteractionArguments(data *InteractionCommandData) []Argument {
+	args := []Argument{Argument(data.Name)}
+	opts := data.Options
+	for len(opts) == 1 && (opts[0].Type == OptionTypeSubCommand || opts[0].Type == OptionTypeSubCommandGroup) {
+		args = append(args, Argument(opts[0].Name))
+		opts = opts[0].Options
+	}
+	for _, o := range opts {
+		if o.Name == SlashArgumentsOptionName && o.Type == OptionTypeString {
+			v, _ := o.Value.(string)
+			args = append(args, ParseArguments(v).Args()...)
+			continue
+		}
+		args = append(args, o.argument())
+	}
+	return args
+}
+
+// argument converts option value to its message representation, e.g. a user ID becomes a mention.
+func (o *InteractionCommandOption) argument() Argument {
+	var v string
+	switch t := o.Value.(type) {
+	case string:
+		v = t
+	case float64:
+		v = strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		v = strconv.FormatBool(t)
+	}
+	switch o.Type {
+	case OptionTypeUser:
+		v = "<@" + v + ">"
+	case OptionTypeRole:
+		v = "<@&" + v + ">"
+	case OptionTypeChannel:
+		v = "<#" + v + ">"
+	default:
+	}
+	return Argument(v)
+}
+
+// registerSlashCommands overwrites application commands with our registered command instances.
+func (r *router) registerSlashCommands(s *discordgo.Session, e *discordgo.Ready) {
+	if _, err := ApplicationCommandBulkOverwrite(s, e.User.ID, r.config.SlashCommandsGuildID, GetApplicationCommands(r.GetCommandInstances())); err != nil {
+		r.onError(nil, ErrTypeRegisterSlashCommands, err)
+	}
+}
+
+// triggerInteraction builds a context from given INTERACTION_CREATE payload and dispatches it
+// through the same path a message would take.
+func (r *router) triggerInteraction(s *discordgo.Session, raw []byte) {
+	i := new(Interaction)
+	if err := json.Unmarshal(raw, i); err != nil || i.Type != InteractionApplicationCommand {
+		return
+	}
+
+	user := i.User
+	if i.Member != nil {
+		i.Member.GuildID = i.GuildID
+		user = i.Member.User
+	}
+	if user == nil || (!r.config.AllowBots && user.Bot) {
+		return
+	}
+
+	args := getInteractionArguments(&i.Data)
+
+	ctx := r.acquireContext(s)
+	ctx.interaction = i
+	ctx.member = i.Member
+	ctx.message = &discordgo.Message{
+		ID:        i.ID,
+		ChannelID: i.ChannelID,
+		GuildID:   i.GuildID,
+		Author:    user,
+		Member:    i.Member,
+		Content:   "/" + strings.Join(argsToStrings(args), " "),
+	}
+
+	// we acknowledge right away, since discord only gives us 3 seconds to respond.
+	if err := InteractionRespond(s, i, &InteractionResponse{Type: InteractionResponseDeferredChannelMessageWithSource}); err != nil {
+		r.onError(ctx, ErrTypeInteractionRespond, err)
+		r.releaseContext(ctx)
+		return
+	}
+
+	err := r.dispatcher.submit(r.getSerializeKey(i.GuildID, i.ChannelID), func() { r.handleInteraction(ctx, args) })
+	if err != nil {
+		if errors.Is(err, ErrQueueFull) {
+			r.onError(ctx, ErrTypeQueueFull, err)
+		}
+		if err = InteractionResponseDelete(s, i); err != nil {
+			r.onError(ctx, ErrTypeInteractionRespond, err)
+		}
+		r.releaseContext(ctx)
+	}
+}
+
+// handleInteraction resolves and executes the command of an acknowledged interaction.
+func (r *router) handleInteraction(ctx *context, args []Argument) {
+	s, i := ctx.session, ctx.interaction
+	defer r.releaseContext(ctx)
+
+	// remove pending response if nothing was sent back.
+	defer func() {
+		if ctx.responded {
+			return
+		}
+		if err := InteractionResponseDelete(s, i); err != nil {
+			r.onError(ctx, ErrTypeInteractionRespond, err)
+		}
+	}()
+	defer r.recoverPanic(ctx)
+
+	if !r.fillEnvironment(ctx, i.ChannelID, i.GuildID) {
+		return
+	}
+
+	cmd, depth, ok := r.ResolveCommand(args)
+	if !ok {
+		ctx.args = FromArguments(args)
+		r.onError(ctx, ErrTypeCommandNotFound, ErrCommandNotFound)
+		return
+	}
+	ctx.root, _ = r.GetCommand(args[0].String())
+	ctx.args = FromArguments(args[depth:])
+	ctx.invoke = "/" + strings.Join(argsToStrings(args[:depth]), " ")
+
+	r.dispatch(cmd, ctx)
+}
@@ -0,0 +1,136 @@
+package rosetta
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetApplicationCommands(t *testing.T) {
+	set := &TestSubCmd{invokers: []string{"set"}}
+	prefix := &TestSubCmd{invokers: []string{"prefix"}, subs: []Command{set}}
+	config := &TestSubCmd{invokers: []string{"config"}, subs: []Command{prefix, &TestSubCmd{invokers: []string{"?"}}}}
+
+	cmds := GetApplicationCommands([]Command{&TestCmd{}, config, &TestSubCmd{invokers: []string{"?", "!"}}})
+	assert.Len(t, cmds, 2)
+
+	ping := cmds[0]
+	assert.Equal(t, "ping", ping.Name)
+	assert.Equal(t, "ping pong ding dong", ping.Description)
+	assert.Len(t, ping.Options, 1)
+	assert.Equal(t, SlashArgumentsOptionName, ping.Options[0].Name)
+	assert.Equal(t, OptionTypeString, ping.Options[0].Type)
+
+	cfg := cmds[1]
+	assert.Equal(t, "config", cfg.Name)
+	assert.Len(t, cfg.Options, 1)
+	assert.Equal(t, OptionTypeSubCommandGroup, cfg.Options[0].Type)
+	assert.Equal(t, "prefix", cfg.Options[0].Name)
+	assert.Equal(t, OptionTypeSubCommand, cfg.Options[0].Options[0].Type)
+	assert.Equal(t, "set", cfg.Options[0].Options[0].Name)
+	assert.Equal(t, SlashArgumentsOptionName, cfg.Options[0].Options[0].Options[0].Name)
+}
+
+func TestGetInteractionArguments(t *testing.T) {
+	raw := `{
+		"name": "config",
+		"options": [{
+			"name": "prefix",
+			"type": 2,
+			"options": [{
+				"name": "set",
+				"type": 1,
+				"options": [
+					{"name": "user", "type": 6, "value": "123"},
+					{"name": "role", "type": 8, "value": "456"},
+					{"name": "channel", "type": 7, "value": "789"},
+					{"name": "count", "type": 4, "value": 25},
+					{"name": "flag", "type": 5, "value": true},
+					{"name": "arguments", "type": 3, "value": "a \"b c\""}
+				]
+			}]
+		}]
+	}`
+	data := new(InteractionCommandData)
+	assert.Nil(t, json.Unmarshal([]byte(raw), data))
+
+	args := getInteractionArguments(data)
+	assert.Equal(t, []Argument{"config", "prefix", "set", "<@123>", "<@&456>", "<#789>", "25", "true", "a", "b c"}, args)
+	assert.Equal(t, "123", args[3].AsUserMentionID())
+
+	r := NewRouter(makeTestConfig())
+	r.Register(&TestSubCmd{invokers: []string{"config"}, subs: []Command{&TestSubCmd{invokers: []string{"prefix"}, subs: []Command{&TestSubCmd{invokers: []string{"set"}}}}}})
+	cmd, depth, ok := r.ResolveCommand(args)
+	assert.True(t, ok)
+	assert.Equal(t, 3, depth)
+	assert.Equal(t, "set", cmd.GetInvokers()[0])
+}
+
+func TestGetSlashDescription(t *testing.T) {
+	long := &TestDescCmd{desc: string(make([]byte, 150))}
+	assert.Len(t, getSlashDescription(long), 100)
+	assert.Equal(t, "no description", getSlashDescription(&TestDescCmd{}))
+
+	// multi-byte characters are counted as one and never cut.
+	umlauts := getSlashDescription(&TestDescCmd{desc: strings.Repeat("ä", 150)})
+	assert.True(t, utf8.ValidString(umlauts))
+	assert.Equal(t, strings.Repeat("ä", 97)+"...", umlauts)
+	short := strings.Repeat("ü", 60)
+	assert.Equal(t, short, getSlashDescription(&TestDescCmd{desc: short}))
+}
+
+type TestDescCmd struct {
+	TestCmd
+	desc string
+}
+
+func (t *TestDescCmd) GetDescription() string {
+	return t.desc
+}
+
+type TestTransport func(req *http.Request) *http.Response
+
+func (t TestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t(req), nil
+}
+
+func TestContext_RespondInteraction(t *testing.T) {
+	var methods []string
+	status := http.StatusBadRequest
+	s, _ := discordgo.New()
+	s.Client = &http.Client{Transport: TestTransport(func(req *http.Request) *http.Response {
+		methods = append(methods, req.Method)
+		return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(`{"id": "1"}`)), Header: http.Header{}}
+	})}
+	ctx := makeTestCtx(false, false)
+	ctx.session = s
+	ctx.interaction = &Interaction{ID: "1", ApplicationID: "2", Token: "token"}
+
+	// a failed edit leaves the deferred response pending, so it's edited again.
+	_, err := ctx.respondInteraction(&InteractionResponseData{Content: "a"})
+	assert.Error(t, err)
+	assert.False(t, ctx.responded)
+
+	status = http.StatusOK
+	_, err = ctx.respondInteraction(&InteractionResponseData{Content: "a"})
+	assert.NoError(t, err)
+	assert.True(t, ctx.responded)
+	_, err = ctx.respondInteraction(&InteractionResponseData{Content: "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PATCH", "PATCH", "POST"}, methods)
+}
+
+func TestGetSchemaOptions(t *testing.T) {
+	opts := getSchemaOptions(NewSchema().Int("amount", strings.Repeat("ä", 150)).String("text", "").Optional())
+	assert.Len(t, opts, 2)
+	assert.Equal(t, strings.Repeat("ä", 97)+"...", opts[0].Description)
+	assert.True(t, opts[0].Required)
+	assert.Equal(t, "text", opts[1].Description)
+	assert.False(t, opts[1].Required)
+}
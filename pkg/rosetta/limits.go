@@ -0,0 +1,326 @@
+package rosetta
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Limits of Discord messages and embeds in characters.
+const (
+	LimitContent          = 2000
+	LimitEmbedTitle       = 256
+	LimitEmbedDescription = 4096
+	LimitEmbedFields      = 25
+	LimitEmbedFieldName   = 256
+	LimitEmbedFieldValue  = 1024
+	LimitEmbedFooter      = 2048
+	LimitEmbedAuthor      = 256
+	LimitEmbedTotal       = 6000
+)
+
+// continuationFieldName is the name of fields continuing an oversize field. Discord requires
+// field names, so a zero width space is used.
+const continuationFieldName = "\u200b"
+
+// LimitError is returned when a message or embed exceeds a limit of Discord which can't be
+// fixed by splitting it, e.g. an oversize embed title.
+type LimitError struct {
+	// Field is the exceeding part, e.g. `embed.title` or `embed.fields[2].name`.
+	Field  string
+	Length int
+	Limit  int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s has %d characters, exceeding the limit of %d", e.Field, e.Length, e.Limit)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// ValidateEmbed returns a LimitError if given embed exceeds any limit of Discord.
+func ValidateEmbed(embed *discordgo.MessageEmbed) error {
+	if err := validateEmbedParts(embed); err != nil {
+		return err
+	}
+	if err := checkLimit("embed.description", embed.Description, LimitEmbedDescription); err != nil {
+		return err
+	}
+	for i, f := range embed.Fields {
+		if err := checkLimit(fmt.Sprintf("embed.fields[%d].value", i), f.Value, LimitEmbedFieldValue); err != nil {
+			return err
+		}
+	}
+	if n := len(embed.Fields); n > LimitEmbedFields {
+		return &LimitError{Field: "embed.fields", Length: n, Limit: LimitEmbedFields}
+	}
+	if n := embedLength(embed); n > LimitEmbedTotal {
+		return &LimitError{Field: "embed", Length: n, Limit: LimitEmbedTotal}
+	}
+	return nil
+}
+
+// SplitEmbed returns given embed if it is within the limits of Discord. Otherwise its
+// description and fields are split into continuation embeds, which only keep the color.
+// The footer and timestamp are moved to the last embed. A LimitError is returned if the
+// title, author, footer or a field name exceeds its limit.
+func SplitEmbed(embed *discordgo.MessageEmbed) ([]*discordgo.MessageEmbed, error) {
+	if err := validateEmbedParts(embed); err != nil {
+		return nil, err
+	}
+	if ValidateEmbed(embed) == nil {
+		return []*discordgo.MessageEmbed{embed}, nil
+	}
+
+	head := *embed
+	head.Description, head.Fields, head.Footer, head.Timestamp = "", nil, nil, ""
+	var footer int
+	if embed.Footer != nil {
+		footer = runeLen(embed.Footer.Text)
+	}
+
+	res := make([]*discordgo.MessageEmbed, 0, 2)
+	cur := &head
+	next := func() {
+		res = append(res, cur)
+		cur = &discordgo.MessageEmbed{Type: embed.Type, Color: embed.Color}
+	}
+
+	if embed.Description != "" {
+		max := LimitEmbedTotal - embedLength(&head) - footer
+		if max > LimitEmbedDescription {
+			max = LimitEmbedDescription
+		}
+		for i, chunk := range SplitText(embed.Description, max) {
+			if i > 0 {
+				next()
+			}
+			cur.Description = chunk
+		}
+	}
+
+	for _, f := range splitFields(embed.Fields) {
+		if len(cur.Fields) == LimitEmbedFields || embedLength(cur)+runeLen(f.Name)+runeLen(f.Value)+footer > LimitEmbedTotal {
+			next()
+		}
+		cur.Fields = append(cur.Fields, f)
+	}
+
+	cur.Footer, cur.Timestamp = embed.Footer, embed.Timestamp
+	return append(res, cur), nil
+}
+
+// SplitMessage returns given message if it is within the limits of Discord. Otherwise its
+// content is split by SplitText and its embed by SplitEmbed into multiple messages. The
+// first embed is sent with the last content chunk, files with the last message and the
+// reference with the first one.
+func SplitMessage(data *discordgo.MessageSend) ([]*discordgo.MessageSend, error) {
+	var embeds []*discordgo.MessageEmbed
+	if data.Embed != nil {
+		var err error
+		if embeds, err = SplitEmbed(data.Embed); err != nil {
+			return nil, err
+		}
+	}
+	contents := []string{data.Content}
+	if data.Content != "" {
+		contents = SplitText(data.Content, LimitContent)
+	}
+	if len(contents) == 1 && len(embeds) < 2 {
+		return []*discordgo.MessageSend{data}, nil
+	}
+
+	res := make([]*discordgo.MessageSend, 0, len(contents)+len(embeds))
+	for _, c := range contents {
+		res = append(res, &discordgo.MessageSend{Content: c, AllowedMentions: data.AllowedMentions})
+	}
+	for i, e := range embeds {
+		if i == 0 {
+			res[len(res)-1].Embed = e
+			continue
+		}
+		res = append(res, &discordgo.MessageSend{Embed: e, AllowedMentions: data.AllowedMentions})
+	}
+	res[0].TTS, res[0].Reference = data.TTS, data.Reference
+	res[len(res)-1].Files, res[len(res)-1].File = data.Files, data.File
+	return res, nil
+}
+
+// SplitText splits given content into chunks of at most max characters. Content is split
+// at line breaks, lines exceeding max at whitespace. Code blocks spanning multiple chunks
+// are closed at the end of a chunk and reopened with their language in the next one.
+func SplitText(content string, max int) []string {
+	if runeLen(content) <= max {
+		return []string{content}
+	}
+	s := &textSplitter{max: max}
+	for _, line := range strings.Split(content, "\n") {
+		s.add(line)
+	}
+	s.flush()
+	return s.chunks
+}
+
+// textSplitter collects lines into chunks.
+type textSplitter struct {
+	max    int
+	chunks []string
+	buf    strings.Builder
+	length int
+
+	// hasContent is false if buf holds nothing but a reopened code block fence.
+	hasContent bool
+
+	// fence is the opening fence of the code block buf ends in, e.g. "```go".
+	fence string
+}
+
+func (s *textSplitter) add(line string) {
+	for {
+		avail := s.max - s.length
+		if s.length > 0 {
+			avail--
+		}
+		if s.fence != "" {
+			avail -= len("\n```")
+		}
+		if runeLen(line) <= avail {
+			s.write(line)
+			return
+		}
+		if s.hasContent {
+			s.flush()
+			continue
+		}
+		if avail < 1 {
+			avail = 1
+		}
+		head, rest := splitLine(line, avail)
+		s.write(head)
+		s.flush()
+		line = rest
+	}
+}
+
+func (s *textSplitter) write(line string) {
+	if s.length > 0 {
+		s.buf.WriteByte('\n')
+		s.length++
+	}
+	s.buf.WriteString(line)
+	s.length += runeLen(line)
+	s.hasContent = true
+
+	if strings.Count(line, "```")%2 == 1 {
+		if s.fence != "" {
+			s.fence = ""
+		} else {
+			s.fence = strings.Fields(line[strings.LastIndex(line, "```"):])[0]
+		}
+	}
+}
+
+func (s *textSplitter) flush() {
+	if !s.hasContent {
+		return
+	}
+	if s.fence != "" {
+		s.buf.WriteString("\n```")
+	}
+	s.chunks = append(s.chunks, s.buf.String())
+	s.buf.Reset()
+	s.length = 0
+	s.hasContent = false
+	if s.fence != "" {
+		s.buf.WriteString(s.fence)
+		s.length = runeLen(s.fence)
+	}
+}
+
+// splitLine splits given line after at most n characters, preferably at whitespace.
+func splitLine(line string, n int) (head, rest string) {
+	cut := len(line)
+	for i := range line {
+		if n == 0 {
+			cut = i
+			break
+		}
+		n--
+	}
+	if sp := strings.LastIndexAny(line[:cut], " \t"); sp > cut/2 {
+		return line[:sp], line[sp+1:]
+	}
+	return line[:cut], line[cut:]
+}
+
+// splitFields splits fields whose value exceeds LimitEmbedFieldValue into continuation fields.
+func splitFields(fields []*discordgo.MessageEmbedField) []*discordgo.MessageEmbedField {
+	res := make([]*discordgo.MessageEmbedField, 0, len(fields))
+	for _, f := range fields {
+		if runeLen(f.Value) <= LimitEmbedFieldValue {
+			res = append(res, f)
+			continue
+		}
+		for i, chunk := range SplitText(f.Value, LimitEmbedFieldValue) {
+			name := f.Name
+			if i > 0 {
+				name = continuationFieldName
+			}
+			res = append(res, &discordgo.MessageEmbedField{Name: name, Value: chunk, Inline: f.Inline})
+		}
+	}
+	return res
+}
+
+// validateEmbedParts checks the limits of embed parts which can't be split.
+func validateEmbedParts(embed *discordgo.MessageEmbed) error {
+	if err := checkLimit("embed.title", embed.Title, LimitEmbedTitle); err != nil {
+		return err
+	}
+	if embed.Author != nil {
+		if err := checkLimit("embed.author.name", embed.Author.Name, LimitEmbedAuthor); err != nil {
+			return err
+		}
+	}
+	if embed.Footer != nil {
+		if err := checkLimit("embed.footer.text", embed.Footer.Text, LimitEmbedFooter); err != nil {
+			return err
+		}
+	}
+	for i, f := range embed.Fields {
+		if err := checkLimit(fmt.Sprintf("embed.fields[%d].name", i), f.Name, LimitEmbedFieldName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkLimit(field, s string, limit int) error {
+	if n := runeLen(s); n > limit {
+		return &LimitError{Field: field, Length: n, Limit: limit}
+	}
+	return nil
+}
+
+// embedLength returns the characters of given embed counting towards LimitEmbedTotal.
+func embedLength(embed *discordgo.MessageEmbed) int {
+	n := runeLen(embed.Title) + runeLen(embed.Description)
+	for _, f := range embed.Fields {
+		n += runeLen(f.Name) + runeLen(f.Value)
+	}
+	if embed.Footer != nil {
+		n += runeLen(embed.Footer.Text)
+	}
+	if embed.Author != nil {
+		n += runeLen(embed.Author.Name)
+	}
+	return n
+}
+
+func runeLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,46 @@
+package rosetta_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/rosettatest"
+)
+
+func TestResponse_Limits(t *testing.T) {
+	h, user := makeHarness(t, nil)
+	cmd := &testCmd{invoke: "respond"}
+	h.Router.Register(cmd)
+
+	cmd.exec = func(ctx rosetta.Context) error {
+		_, err := ctx.RespondText(strings.Repeat("a line of text\n", 300))
+		return err
+	}
+	h.Send(user, "10", "r!respond")
+	h.ExpectNoErrors()
+	sent := h.Sent()
+	require.Len(t, sent, 3)
+	for _, a := range sent {
+		assert.LessOrEqual(t, len(a.Content), rosetta.LimitContent)
+	}
+
+	h.Reset()
+	cmd.exec = func(ctx rosetta.Context) error {
+		_, err := ctx.RespondEmbed(&discordgo.MessageEmbed{Title: strings.Repeat("a", 300)})
+		return err
+	}
+	h.Send(user, "10", "r!respond")
+	h.ExpectNoResponse()
+	err := h.ExpectError(rosetta.ErrTypeCommandExec)
+	assert.ErrorIs(t, err, rosetta.ErrLimitExceeded)
+
+	// the unit test context validates like the router.
+	ctx := rosettatest.NewContext(user, &discordgo.Channel{ID: "10"}, "r!respond")
+	assert.ErrorIs(t, cmd.exec(ctx), rosetta.ErrLimitExceeded)
+	assert.Empty(t, ctx.Responses)
+}
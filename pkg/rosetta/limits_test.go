@@ -0,0 +1,145 @@
+package rosetta
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		max      int
+		expected []string
+	}{
+		{"fits", "a\nb", 3, []string{"a\nb"}},
+		{"lines", "aaaa\nbbbb\ncc", 9, []string{"aaaa\nbbbb", "cc"}},
+		{"long line at whitespace", "aaaa bbbb cccc", 10, []string{"aaaa bbbb", "cccc"}},
+		{"long word", "aaaaaaaaaa", 4, []string{"aaaa", "aaaa", "aa"}},
+		{"runes", "ääää\nöö", 5, []string{"ääää", "öö"}},
+		{"code block", "text\n```go\nline1\nline2\n```\nafter", 20, []string{"text\n```go\nline1\n```", "```go\nline2\n```", "after"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := SplitText(tt.content, tt.max)
+			assert.Equal(t, tt.expected, chunks)
+			for _, c := range chunks {
+				assert.LessOrEqual(t, runeLen(c), tt.max)
+			}
+		})
+	}
+}
+
+func TestValidateEmbed(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	tests := []struct {
+		name  string
+		embed *discordgo.MessageEmbed
+		field string
+	}{
+		{"valid", &discordgo.MessageEmbed{Title: "title", Description: "desc"}, ""},
+		{"title", &discordgo.MessageEmbed{Title: long}, "embed.title"},
+		{"author", &discordgo.MessageEmbed{Author: &discordgo.MessageEmbedAuthor{Name: long}}, "embed.author.name"},
+		{"field name", &discordgo.MessageEmbed{Fields: []*discordgo.MessageEmbedField{{Name: "a", Value: "b"}, {Name: long, Value: "b"}}}, "embed.fields[1].name"},
+		{"field value", &discordgo.MessageEmbed{Fields: []*discordgo.MessageEmbedField{{Name: "a", Value: strings.Repeat("a", 1025)}}}, "embed.fields[0].value"},
+		{"description", &discordgo.MessageEmbed{Description: strings.Repeat("a", 4097)}, "embed.description"},
+		{"total", &discordgo.MessageEmbed{Description: strings.Repeat("a", 4000), Footer: &discordgo.MessageEmbedFooter{Text: strings.Repeat("a", 2001)}}, "embed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEmbed(tt.embed)
+			if tt.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var limitErr *LimitError
+			require.True(t, errors.As(err, &limitErr))
+			assert.Equal(t, tt.field, limitErr.Field)
+			assert.ErrorIs(t, err, ErrLimitExceeded)
+		})
+	}
+}
+
+func TestSplitEmbed(t *testing.T) {
+	embed := &discordgo.MessageEmbed{Title: "title"}
+	embeds, err := SplitEmbed(embed)
+	require.NoError(t, err)
+	assert.Same(t, embed, embeds[0])
+
+	_, err = SplitEmbed(&discordgo.MessageEmbed{Title: strings.Repeat("a", 257), Description: strings.Repeat("a", 5000)})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+
+	fields := make([]*discordgo.MessageEmbedField, 30)
+	for i := range fields {
+		fields[i] = &discordgo.MessageEmbedField{Name: "name", Value: "value", Inline: true}
+	}
+	fields[0].Value = strings.Repeat("line\n", 300)
+	embed = &discordgo.MessageEmbed{
+		Title:       "title",
+		Description: strings.Repeat("word ", 1000),
+		Color:       EmbedColorError,
+		Fields:      fields,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "footer"},
+		Timestamp:   "now",
+	}
+	embeds, err = SplitEmbed(embed)
+	require.NoError(t, err)
+	require.Len(t, embeds, 3)
+
+	var fieldCount int
+	for i, e := range embeds {
+		assert.NoError(t, ValidateEmbed(e))
+		assert.Equal(t, EmbedColorError, e.Color)
+		fieldCount += len(e.Fields)
+		if i < len(embeds)-1 {
+			assert.Nil(t, e.Footer)
+		}
+	}
+	assert.Equal(t, "title", embeds[0].Title)
+	assert.Empty(t, embeds[1].Title)
+	assert.Equal(t, "footer", embeds[2].Footer.Text)
+	assert.Equal(t, "now", embeds[2].Timestamp)
+	assert.Equal(t, 31, fieldCount)
+	assert.Equal(t, "name", embeds[1].Fields[0].Name)
+	assert.Equal(t, continuationFieldName, embeds[1].Fields[1].Name)
+	assert.True(t, embeds[1].Fields[1].Inline)
+}
+
+func TestSplitMessage(t *testing.T) {
+	data := &discordgo.MessageSend{Content: "short"}
+	parts, err := SplitMessage(data)
+	require.NoError(t, err)
+	assert.Equal(t, []*discordgo.MessageSend{data}, parts)
+
+	mentions := DefaultAllowedMentions()
+	ref := &discordgo.MessageReference{MessageID: "1"}
+	files := []*discordgo.File{{Name: "a.txt"}}
+	data = &discordgo.MessageSend{
+		Content:         strings.Repeat("line\n", 500),
+		Embed:           &discordgo.MessageEmbed{Description: strings.Repeat("word ", 1000)},
+		Files:           files,
+		Reference:       ref,
+		AllowedMentions: mentions,
+	}
+	parts, err = SplitMessage(data)
+	require.NoError(t, err)
+	require.Len(t, parts, 3)
+	assert.Equal(t, ref, parts[0].Reference)
+	assert.Nil(t, parts[0].Embed)
+	assert.NotNil(t, parts[1].Embed)
+	assert.NotEmpty(t, parts[1].Content)
+	assert.Empty(t, parts[2].Content)
+	assert.Equal(t, files, parts[2].Files)
+	for _, p := range parts {
+		assert.LessOrEqual(t, len(p.Content), LimitContent)
+		assert.Equal(t, mentions, p.AllowedMentions)
+	}
+
+	_, err = SplitMessage(&discordgo.MessageSend{Embed: &discordgo.MessageEmbed{Title: strings.Repeat("a", 257)}})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
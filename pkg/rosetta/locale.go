@@ -0,0 +1,344 @@
+package rosetta
+
+import (
+	"embed"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/i18n"
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// defaultTranslations is used for keys missing in Config.Translations.
+var defaultTranslations = NewTranslations()
+
+// NewTranslations returns a bundle containing the catalogs of our responses. Catalogs of
+// further locales or commands can be added to it, e.g. by LoadDir.
+func NewTranslations() *i18n.Bundle {
+	b := i18n.NewBundle(i18n.DefaultLocale)
+	if err := b.LoadFS(localeFS, "locales"); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// LocaleProvider stores the locale of guilds and users. The locale of a user overrides
+// the one of the guild. This can be implemented to persist locales into a database.
+type LocaleProvider interface {
+
+	// GetGuildLocale returns the locale of given guild, empty if not set.
+	GetGuildLocale(guildID string) (string, error)
+
+	// SetGuildLocale sets the locale of given guild. An empty locale resets it.
+	SetGuildLocale(guildID, locale string) error
+
+	// GetUserLocale returns the locale of given user, empty if not set.
+	GetUserLocale(userID string) (string, error)
+
+	// SetUserLocale sets the locale of given user. An empty locale resets it.
+	SetUserLocale(userID, locale string) error
+}
+
+type memoryLocaleProvider struct {
+	mu     sync.RWMutex
+	guilds map[string]string
+	users  map[string]string
+}
+
+// NewMemoryLocaleProvider returns a LocaleProvider which keeps locales in memory.
+func NewMemoryLocaleProvider() LocaleProvider {
+	return &memoryLocaleProvider{guilds: make(map[string]string), users: make(map[string]string)}
+}
+
+func (m *memoryLocaleProvider) GetGuildLocale(guildID string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.guilds[guildID], nil
+}
+
+func (m *memoryLocaleProvider) SetGuildLocale(guildID, locale string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	setOrDelete(m.guilds, guildID, locale)
+	return nil
+}
+
+func (m *memoryLocaleProvider) GetUserLocale(userID string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.users[userID], nil
+}
+
+func (m *memoryLocaleProvider) SetUserLocale(userID, locale string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	setOrDelete(m.users, userID, locale)
+	return nil
+}
+
+func setOrDelete(m map[string]string, key, value string) {
+	if value == "" {
+		delete(m, key)
+		return
+	}
+	m[key] = value
+}
+
+// ResolveLocale returns the locale of given user in given guild. The locale of the user
+// overrides the one of the guild. If neither is set, fallback is returned.
+func ResolveLocale(p LocaleProvider, guildID, userID, fallback string) (string, error) {
+	if userID != "" {
+		if l, err := p.GetUserLocale(userID); err != nil || l != "" {
+			return orDefault(l, fallback), err
+		}
+	}
+	if guildID != "" {
+		if l, err := p.GetGuildLocale(guildID); err != nil || l != "" {
+			return orDefault(l, fallback), err
+		}
+	}
+	return fallback, nil
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// CommandDescription returns the description of given command in the locale of ctx, looked
+// up by key `<domain>.description`. It falls back to Command.GetDescription.
+func CommandDescription(ctx Context, cmd Command) string {
+	key := cmd.GetDomain() + ".description"
+	if s := ctx.T(key); s != key {
+		return s
+	}
+	return cmd.GetDescription()
+}
+
+// CommandUsage returns the usage of given command in the locale of ctx, looked up by key
+// `<domain>.usage`. It falls back to Command.GetUsage.
+func CommandUsage(ctx Context, cmd Command) string {
+	key := cmd.GetDomain() + ".usage"
+	if s := ctx.T(key); s != key {
+		return s
+	}
+	return cmd.GetUsage()
+}
+
+func (c *context) GetLocale() string {
+	if c.locale == "" {
+		c.locale = c.resolveLocale()
+	}
+	return c.locale
+}
+
+func (c *context) T(key string, args ...interface{}) string {
+	if c.router != nil {
+		if s, ok := c.router.GetConfig().Translations.Lookup(c.GetLocale(), key, args...); ok {
+			return s
+		}
+	}
+	return defaultTranslations.T(c.GetLocale(), key, args...)
+}
+
+// resolveLocale returns the locale of our user and guild, passing errors of the
+// LocaleProvider to OnError.
+func (c *context) resolveLocale() string {
+	if c.router == nil {
+		return i18n.DefaultLocale
+	}
+	cfg := c.router.GetConfig()
+	fallback := cfg.Translations.Fallback()
+
+	var guildID, userID string
+	if c.guild != nil && !c.isDM {
+		guildID = c.guild.ID
+	}
+	if c.message != nil && c.message.Author != nil {
+		userID = c.message.Author.ID
+	}
+	locale, err := ResolveLocale(cfg.LocaleProvider, guildID, userID, fallback)
+	if err != nil {
+		cfg.OnError(c, ErrTypeGetLocale, WrapError(ErrTypeGetLocale, c.domain(), err))
+	}
+	return locale
+}
+
+// DefaultLocaleCommand lets users choose their language and guild admins the one of their guild.
+type DefaultLocaleCommand struct{}
+
+func (d *DefaultLocaleCommand) GetInvokers() []string {
+	return []string{"language", "lang", "locale"}
+}
+
+func (d *DefaultLocaleCommand) GetDescription() string {
+	return "view and change the language of responses"
+}
+
+func (d *DefaultLocaleCommand) GetUsage() string {
+	return "`language` - show languages\n" +
+		"`language set <language>` - set your language, e.g. `language set de`\n" +
+		"`language reset` - use the language of the guild again\n" +
+		"`language guild <language>` - set the language of this guild"
+}
+
+func (d *DefaultLocaleCommand) GetGroup() string {
+	return GroupEtc
+}
+
+func (d *DefaultLocaleCommand) GetDomain() string {
+	return "rs.etc.locale"
+}
+
+func (d *DefaultLocaleCommand) GetSubPermissionRules() []SubPermission {
+	return nil
+}
+
+func (d *DefaultLocaleCommand) IsExecutableInDM() bool {
+	return true
+}
+
+func (d *DefaultLocaleCommand) GetSubCommands() []Command {
+	return []Command{
+		&localeCommand{
+			invokers: []string{"set"}, description: "set your language", domain: "rs.etc.locale.set", dm: true,
+			update: func(ctx Context, p LocaleProvider, locale string) error {
+				return p.SetUserLocale(ctx.GetUser().ID, locale)
+			},
+		},
+		&localeCommand{
+			invokers: []string{"reset"}, description: "use the language of the guild again", domain: "rs.etc.locale.reset", dm: true,
+			update: func(ctx Context, p LocaleProvider, _ string) error {
+				return p.SetUserLocale(ctx.GetUser().ID, "")
+			},
+		},
+		&localeCommand{
+			invokers: []string{"guild"}, description: "set the language of this guild", domain: "rs.guild.config.locale",
+			manage: true,
+			update: func(ctx Context, p LocaleProvider, locale string) error {
+				return p.SetGuildLocale(ctx.GetGuild().ID, locale)
+			},
+		},
+	}
+}
+
+func (d *DefaultLocaleCommand) Exec(ctx Context) error {
+	rr, _ := ctx.GetObject(ObjectMapKeyRouter).(Router)
+	cfg := rr.GetConfig()
+
+	user, err := cfg.LocaleProvider.GetUserLocale(ctx.GetUser().ID)
+	if err != nil {
+		return err
+	}
+	fields := []*discordgo.MessageEmbedField{
+		{Name: ctx.T("rosetta.locale.current"), Value: "`" + ctx.GetLocale() + "`", Inline: true},
+		{Name: ctx.T("rosetta.locale.user"), Value: formatLocale(ctx, user), Inline: true},
+	}
+	if guild := ctx.GetGuild(); guild != nil && !ctx.IsDM() {
+		l, err := cfg.LocaleProvider.GetGuildLocale(guild.ID)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{Name: ctx.T("rosetta.locale.guild"), Value: formatLocale(ctx, l), Inline: true})
+	}
+	locales := cfg.Translations.Locales()
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:  ctx.T("rosetta.locale.available", len(locales)),
+		Value: "`" + strings.Join(locales, "` `") + "`",
+	})
+
+	_, err = ctx.RespondEmbed(&discordgo.MessageEmbed{Title: ctx.T("rosetta.locale.title"), Color: EmbedColorDefault, Fields: fields})
+	return err
+}
+
+func formatLocale(ctx Context, locale string) string {
+	if locale == "" {
+		return ctx.T("rosetta.locale.not_set")
+	}
+	return "`" + locale + "`"
+}
+
+type localeCommand struct {
+	invokers    []string
+	description string
+	domain      string
+	dm          bool
+	manage      bool
+	update      func(ctx Context, p LocaleProvider, locale string) error
+}
+
+func (l *localeCommand) GetInvokers() []string {
+	return l.invokers
+}
+
+func (l *localeCommand) GetDescription() string {
+	return l.description
+}
+
+func (l *localeCommand) GetUsage() string {
+	return ""
+}
+
+func (l *localeCommand) GetGroup() string {
+	if l.dm {
+		return GroupEtc
+	}
+	return GroupGuildConfig
+}
+
+func (l *localeCommand) GetDomain() string {
+	return l.domain
+}
+
+func (l *localeCommand) GetSubPermissionRules() []SubPermission {
+	return nil
+}
+
+func (l *localeCommand) IsExecutableInDM() bool {
+	return l.dm
+}
+
+func (l *localeCommand) GetSchema() *Schema {
+	if l.invokers[0] == "reset" {
+		return NewSchema()
+	}
+	return NewSchema().String("language", "language code, e.g. `en` or `pt-br`")
+}
+
+func (l *localeCommand) Exec(ctx Context) error {
+	// the guild language is restricted to members managing the guild, languages of users
+	// can be set by everyone.
+	if l.manage {
+		if ok, err := RequireGuildManager(ctx); !ok {
+			return err
+		}
+	}
+
+	rr, _ := ctx.GetObject(ObjectMapKeyRouter).(Router)
+	cfg := rr.GetConfig()
+
+	var locale string
+	if params := ctx.GetParams(); params != nil && params.Has("language") {
+		locale = i18n.NormalizeLocale(params.String("language"))
+		if !cfg.Translations.Has(locale) {
+			_, err := ctx.RespondEmbedError(ctx.T("rosetta.locale.unknown", locale), ErrInvalidArgument)
+			return err
+		}
+	}
+	if err := l.update(ctx, cfg.LocaleProvider, locale); err != nil {
+		return err
+	}
+
+	msg := ctx.T("rosetta.locale.reset")
+	if locale != "" {
+		msg = ctx.T("rosetta.locale.updated", locale)
+	}
+	_, err := ctx.RespondEmbed(&discordgo.MessageEmbed{Title: ctx.T("rosetta.locale.title"), Description: msg, Color: EmbedColorDefault})
+	return err
+}
@@ -0,0 +1,79 @@
+package rosetta_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/i18n"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+func TestDefaultLocaleCommand(t *testing.T) {
+	cfg := rosetta.NewDefaultConfig()
+	cfg.OnError = nil
+	cfg.UseDefaultLocaleCommand = true
+	cfg.UseDefaultPrefixCommand = true
+	h, user := makeHarness(t, cfg)
+	manager := addManager(h)
+	h.Router.GetConfig().Translations.Add("de", i18n.Catalog{
+		"rosetta.help.list_title_guild": {i18n.PluralOther: "Befehlsliste für %s"},
+		"rosetta.locale.updated":        {i18n.PluralOther: "Sprache ist jetzt `%s`."},
+		"rosetta.prefix.title":          {i18n.PluralOther: "Präfixe"},
+		"test.ping.description":         {i18n.PluralOther: "Ping Pong auf Deutsch"},
+	})
+
+	helpTitle := func() string {
+		h.Reset()
+		h.Send(user, "10", "r!help")
+		sent := h.Sent()
+		require.Len(t, sent, 1)
+		return sent[0].Embed().Title
+	}
+
+	// the language of the guild can only be set by members managing it.
+	h.Send(user, "10", "r!language guild de")
+	h.ExpectNoErrors()
+	assert.Contains(t, h.Last().Embed().Description, "Manage Server")
+	assert.Equal(t, "Command List for guild", helpTitle())
+
+	h.Reset()
+	h.Send(manager, "10", "r!language guild DE")
+	h.ExpectNoErrors()
+	a := h.ExpectEmbedTitle("Language")
+	require.NotNil(t, a)
+	assert.Equal(t, "Sprache ist jetzt `de`.", a.Embed().Description)
+	assert.Equal(t, "Befehlsliste für guild", helpTitle())
+
+	h.Reset()
+	h.Send(user, "10", "r!help ping")
+	sent := h.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "Ping Pong auf Deutsch", sent[0].Embed().Fields[4].Value)
+
+	// responses of the guild config commands are translated as well.
+	h.Reset()
+	h.Send(manager, "10", "r!prefix")
+	h.ExpectEmbedTitle("Präfixe")
+
+	// the language of a user overrides the one of the guild, everyone can set it.
+	h.Send(user, "10", "r!language set en")
+	assert.Equal(t, "Command List for guild", helpTitle())
+	h.Send(user, "10", "r!language reset")
+	assert.Equal(t, "Befehlsliste für guild", helpTitle())
+
+	h.Reset()
+	h.Send(user, "10", "r!language set xx")
+	h.ExpectEmbedTitle("Unknown language `xx`.")
+
+	h.Reset()
+	h.Send(user, "10", "r!language")
+	a = h.ExpectEmbedTitle("Language")
+	require.NotNil(t, a)
+	fields := a.Embed().Fields
+	assert.Equal(t, "`de`", fields[0].Value)
+	assert.Equal(t, "`not set`", fields[1].Value)
+	assert.Equal(t, "`de`", fields[2].Value)
+	assert.Equal(t, "2 available languages", fields[3].Name)
+}
@@ -0,0 +1,62 @@
+package rosetta
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingLocaleProvider struct {
+	LocaleProvider
+}
+
+func (f *failingLocaleProvider) GetUserLocale(string) (string, error) {
+	return "", errors.New("unavailable")
+}
+
+func TestResolveLocale(t *testing.T) {
+	p := NewMemoryLocaleProvider()
+	require.NoError(t, p.SetGuildLocale("1", "de"))
+	require.NoError(t, p.SetUserLocale("2", "fr"))
+
+	tests := []struct {
+		name     string
+		guildID  string
+		userID   string
+		expected string
+	}{
+		{"user overrides guild", "1", "2", "fr"},
+		{"guild", "1", "3", "de"},
+		{"dm", "", "3", "en"},
+		{"fallback", "4", "3", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := ResolveLocale(p, tt.guildID, tt.userID, "en")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, l)
+		})
+	}
+
+	require.NoError(t, p.SetUserLocale("2", ""))
+	l, _ := ResolveLocale(p, "1", "2", "en")
+	assert.Equal(t, "de", l)
+
+	l, err := ResolveLocale(&failingLocaleProvider{p}, "1", "2", "en")
+	assert.Error(t, err)
+	assert.Equal(t, "en", l)
+}
+
+func TestTranslations(t *testing.T) {
+	b := NewTranslations()
+	assert.Contains(t, b.Locales(), "en")
+	assert.Equal(t, "1 available language", b.T("en", "rosetta.locale.available", 1))
+	assert.Equal(t, "Page 2/3", b.T("de", "rosetta.help.page", 2, 3))
+
+	// a context without router uses the built-in catalogs.
+	c := &context{}
+	assert.Equal(t, "en", c.GetLocale())
+	assert.Equal(t, "Invalid arguments", c.T("rosetta.invalid_arguments.title"))
+}
@@ -0,0 +1,54 @@
+package rosetta
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// GuildPermissions returns the guild wide permissions of a member with given user ID and
+// roles, which are the combined permissions of its roles and the @everyone role. The guild
+// owner and administrators have all permissions.
+func GuildPermissions(guild *discordgo.Guild, userID string, roles []string) int64 {
+	if guild.OwnerID == userID {
+		return discordgo.PermissionAll
+	}
+
+	// @everyone role shares its ID with the guild and isn't part of member roles.
+	var perms int64
+	for _, r := range guild.Roles {
+		if r.ID == guild.ID || arrayContains(roles, r.ID, false) {
+			perms |= r.Permissions
+		}
+	}
+	if perms&discordgo.PermissionAdministrator != 0 {
+		return discordgo.PermissionAll
+	}
+	return perms
+}
+
+// IsGuildManager returns true if the invoking member of ctx has the Manage Server permission.
+// It's always false outside of guilds.
+func IsGuildManager(ctx Context) bool {
+	guild := ctx.GetGuild()
+	if guild == nil || ctx.IsDM() {
+		return false
+	}
+	var roles []string
+	if m := ctx.GetMember(); m != nil {
+		roles = m.Roles
+	}
+	return GuildPermissions(guild, ctx.GetUser().ID, roles)&discordgo.PermissionManageServer != 0
+}
+
+// RequireGuildManager returns true if the invoking member of ctx manages the guild. Otherwise
+// the member is told so and false is returned. Commands changing the configuration of a guild
+// call it before applying changes.
+func RequireGuildManager(ctx Context) (bool, error) {
+	if IsGuildManager(ctx) {
+		return true, nil
+	}
+	_, err := ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Description: ctx.T("rosetta.guild_manager.required"),
+		Color:       EmbedColorError,
+	})
+	return false, err
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultBuckets are upper bounds in seconds of latency histograms.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+
+// histogram counts observations into buckets. It is not safe for concurrent use.
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	v := d.Seconds()
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+func (h *histogram) snapshot() Histogram {
+	res := Histogram{Buckets: h.buckets, Counts: make([]uint64, len(h.counts)), Count: h.count, Sum: h.sum}
+	var cumulative uint64
+	for i, c := range h.counts {
+		cumulative += c
+		res.Counts[i] = cumulative
+	}
+	return res
+}
+
+// Histogram is a snapshot of a latency histogram.
+type Histogram struct {
+	// Buckets are upper bounds in seconds.
+	Buckets []float64
+
+	// Counts are the cumulative counts of observations less or equal to their bucket.
+	Counts []uint64
+
+	// Count is the total amount of observations, Sum their total in seconds.
+	Count uint64
+	Sum   float64
+}
+
+// Mean returns the average observed duration.
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return time.Duration(h.Sum / float64(h.Count) * float64(time.Second))
+}
+
+// Quantile estimates the duration below which given fraction of observations fall,
+// e.g. 0.95. Observations above the last bucket are reported as the last bucket.
+func (h Histogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 || len(h.Buckets) == 0 {
+		return 0
+	}
+	rank := q * float64(h.Count)
+	var lower float64
+	var prev uint64
+	for i, c := range h.Counts {
+		if float64(c) >= rank {
+			upper := h.Buckets[i]
+			inBucket := c - prev
+			if inBucket == 0 {
+				return toDuration(upper)
+			}
+			// interpolate linearly within the bucket.
+			return toDuration(lower + (upper-lower)*(rank-float64(prev))/float64(inBucket))
+		}
+		lower, prev = h.Buckets[i], c
+	}
+	return toDuration(h.Buckets[len(h.Buckets)-1])
+}
+
+func toDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
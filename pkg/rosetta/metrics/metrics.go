@@ -0,0 +1,247 @@
+// Package metrics records command execution metrics of a rosetta router, e.g. invocation
+// counts, latencies and errors, which can be queried in process.
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/internal/components"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+// matchedTTL defines how long invoking messages are remembered, so chained commands
+// count their message as matched once.
+const matchedTTL = time.Minute
+
+type commandMetrics struct {
+	invocations int64
+	errors      int64
+	rateLimited int64
+	latency     *histogram
+}
+
+// Registry records metrics of commands and messages. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.Mutex
+	buckets  []float64
+	commands map[string]*commandMetrics
+	errors   map[rosetta.ErrorType]int64
+	matched  map[string]time.Time
+	sweep    time.Time
+	started  time.Time
+
+	messagesScanned int64
+	messagesMatched int64
+}
+
+// New returns a Registry with latency histograms using given buckets in seconds.
+// If no buckets are given, DefaultBuckets are used.
+func New(buckets ...float64) *Registry {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	return &Registry{
+		buckets:  buckets,
+		commands: make(map[string]*commandMetrics),
+		errors:   make(map[rosetta.ErrorType]int64),
+		matched:  make(map[string]time.Time),
+		started:  time.Now(),
+	}
+}
+
+// Register adds our middleware and message handler to given router and counts errors
+// passed to its OnError.
+func (r *Registry) Register(router rosetta.Router) {
+	router.RegisterMiddlewareFunc(r.Middleware())
+	router.RegisterEventHandler(r)
+
+	cfg := router.GetConfig()
+	cfg.OnError = r.OnError(cfg.OnError)
+}
+
+// Middleware returns a MiddlewareFunc recording invocations, latency and errors per command domain.
+func (r *Registry) Middleware() rosetta.MiddlewareFunc {
+	return func(next rosetta.HandlerFunc) rosetta.HandlerFunc {
+		return func(cmd rosetta.Command, ctx rosetta.Context) (err error) {
+			start := time.Now()
+			defer func() {
+				if v := recover(); v != nil {
+					r.ObserveCommand(cmd.GetDomain(), time.Since(start), rosetta.ErrCommandPanic)
+					panic(v)
+				}
+				r.ObserveCommand(cmd.GetDomain(), time.Since(start), err)
+			}()
+			if msg := ctx.GetMessage(); msg != nil && ctx.GetInteraction() == nil {
+				r.ObserveMatched(msg.ID)
+			}
+			return next(cmd, ctx)
+		}
+	}
+}
+
+// OnError returns an OnError func counting errors by type before calling next.
+func (r *Registry) OnError(next func(ctx rosetta.Context, errType rosetta.ErrorType, err error)) func(ctx rosetta.Context, errType rosetta.ErrorType, err error) {
+	return func(ctx rosetta.Context, errType rosetta.ErrorType, err error) {
+		r.ObserveError(errType)
+		if next != nil {
+			next(ctx, errType, err)
+		}
+	}
+}
+
+// GetEvents lets Registry count scanned messages as rosetta.EventHandler.
+func (r *Registry) GetEvents() []interface{} {
+	return []interface{}{&discordgo.MessageCreate{}}
+}
+
+// HandleEvent counts messages of users which were scanned for commands.
+func (r *Registry) HandleEvent(_ rosetta.EventContext, event interface{}) error {
+	if e, ok := event.(*discordgo.MessageCreate); ok && e.Author != nil && !e.Author.Bot {
+		r.ObserveScanned()
+	}
+	return nil
+}
+
+// ObserveCommand records an invocation of the command with given domain.
+func (r *Registry) ObserveCommand(domain string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.command(domain)
+	c.invocations++
+	c.latency.observe(latency)
+	if err != nil {
+		c.errors++
+	}
+	atomic.AddInt64(&components.StatsCommandsExecuted, 1)
+}
+
+// ObserveRateLimited records a rejected invocation. It can be passed to ratelimit.RateLimiter.OnLimited.
+func (r *Registry) ObserveRateLimited(cmd rosetta.Command, _ rosetta.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.command(cmd.GetDomain()).rateLimited++
+}
+
+// ObserveError records an error passed to OnError.
+func (r *Registry) ObserveError(errType rosetta.ErrorType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors[errType]++
+}
+
+// ObserveScanned records a message which was scanned for commands.
+func (r *Registry) ObserveScanned() {
+	atomic.AddInt64(&r.messagesScanned, 1)
+	atomic.AddInt64(&components.StatsMessageAnalyzed, 1)
+}
+
+// ObserveMatched records a message with given ID which invoked a command. Messages
+// invoking multiple commands are counted once.
+func (r *Registry) ObserveMatched(msgID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.After(r.sweep) {
+		for id, expires := range r.matched {
+			if now.After(expires) {
+				delete(r.matched, id)
+			}
+		}
+		r.sweep = now.Add(matchedTTL)
+	}
+	if _, ok := r.matched[msgID]; ok {
+		return
+	}
+	r.matched[msgID] = now.Add(matchedTTL)
+	atomic.AddInt64(&r.messagesMatched, 1)
+}
+
+// command returns metrics of given domain. r.mu must be held.
+func (r *Registry) command(domain string) *commandMetrics {
+	c, ok := r.commands[domain]
+	if !ok {
+		c = &commandMetrics{latency: newHistogram(r.buckets)}
+		r.commands[domain] = c
+	}
+	return c
+}
+
+// CommandStats are metrics of a single command domain.
+type CommandStats struct {
+	Domain      string
+	Invocations int64
+	Errors      int64
+	RateLimited int64
+	Latency     Histogram
+}
+
+// Snapshot is a point in time copy of all metrics of a Registry.
+type Snapshot struct {
+	Uptime          time.Duration
+	MessagesScanned int64
+	MessagesMatched int64
+
+	// Commands are sorted by invocations, most invoked first.
+	Commands []CommandStats
+	Errors   map[rosetta.ErrorType]int64
+}
+
+// CommandsExecuted returns the total amount of invocations.
+func (s *Snapshot) CommandsExecuted() int64 {
+	var n int64
+	for _, c := range s.Commands {
+		n += c.Invocations
+	}
+	return n
+}
+
+// RateLimited returns the total amount of rejected invocations.
+func (s *Snapshot) RateLimited() int64 {
+	var n int64
+	for _, c := range s.Commands {
+		n += c.RateLimited
+	}
+	return n
+}
+
+// Snapshot returns a copy of all recorded metrics.
+func (r *Registry) Snapshot() *Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &Snapshot{
+		Uptime:          time.Since(r.started),
+		MessagesScanned: atomic.LoadInt64(&r.messagesScanned),
+		MessagesMatched: atomic.LoadInt64(&r.messagesMatched),
+		Commands:        make([]CommandStats, 0, len(r.commands)),
+		Errors:          make(map[rosetta.ErrorType]int64, len(r.errors)),
+	}
+	for domain, c := range r.commands {
+		s.Commands = append(s.Commands, CommandStats{
+			Domain:      domain,
+			Invocations: c.invocations,
+			Errors:      c.errors,
+			RateLimited: c.rateLimited,
+			Latency:     c.latency.snapshot(),
+		})
+	}
+	sort.Slice(s.Commands, func(i, j int) bool {
+		if s.Commands[i].Invocations != s.Commands[j].Invocations {
+			return s.Commands[i].Invocations > s.Commands[j].Invocations
+		}
+		return s.Commands[i].Domain < s.Commands[j].Domain
+	})
+	for t, n := range r.errors {
+		s.Errors[t] = n
+	}
+	return s
+}
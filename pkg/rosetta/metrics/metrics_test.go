@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/rosettatest"
+)
+
+type testCmd struct {
+	invoke string
+	err    error
+}
+
+func (t *testCmd) GetInvokers() []string {
+	return []string{t.invoke}
+}
+
+func (t *testCmd) GetDescription() string {
+	return "test"
+}
+
+func (t *testCmd) GetUsage() string {
+	return ""
+}
+
+func (t *testCmd) GetGroup() string {
+	return rosetta.GroupFun
+}
+
+func (t *testCmd) GetDomain() string {
+	return "test." + t.invoke
+}
+
+func (t *testCmd) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (t *testCmd) IsExecutableInDM() bool {
+	return true
+}
+
+func (t *testCmd) Exec(_ rosetta.Context) error {
+	return t.err
+}
+
+func TestRegistry(t *testing.T) {
+	cfg := rosetta.NewDefaultConfig()
+	cfg.OnError = nil
+	cfg.AllowChaining = true
+	h := rosettatest.New(t, cfg)
+	reg := New()
+	reg.Register(h.Router)
+	h.Router.Register(&testCmd{invoke: "ok"})
+	h.Router.Register(&testCmd{invoke: "fail", err: errors.New("test error")})
+	h.Router.Register(&StatsCommand{Registry: reg})
+
+	h.AddGuild(&discordgo.Guild{ID: "1"})
+	h.AddChannel(&discordgo.Channel{ID: "10", GuildID: "1", Type: discordgo.ChannelTypeGuildText})
+	user := &discordgo.User{ID: "2"}
+
+	h.Send(user, "10", "hello")
+	h.Send(user, "10", "r!ok; r!ok")
+	h.Send(user, "10", "r!fail")
+	h.Send(&discordgo.User{ID: "3", Bot: true}, "10", "r!ok")
+	reg.ObserveRateLimited(&testCmd{invoke: "ok"}, nil)
+
+	s := reg.Snapshot()
+	assert.Equal(t, int64(3), s.MessagesScanned)
+	assert.Equal(t, int64(2), s.MessagesMatched)
+	assert.Equal(t, int64(3), s.CommandsExecuted())
+	assert.Equal(t, int64(1), s.RateLimited())
+	require.Len(t, s.Commands, 2)
+	assert.Equal(t, "test.ok", s.Commands[0].Domain)
+	assert.Equal(t, int64(2), s.Commands[0].Invocations)
+	assert.Equal(t, uint64(2), s.Commands[0].Latency.Count)
+	assert.Equal(t, int64(1), s.Commands[1].Errors)
+	assert.Equal(t, map[rosetta.ErrorType]int64{rosetta.ErrTypeCommandExec: 1}, s.Errors)
+
+	h.Send(user, "10", "r!stats")
+	a := h.ExpectEmbedTitle("Statistics")
+	require.NotNil(t, a)
+	assert.Contains(t, a.Embed().Fields[4].Value, "`test.ok` - 2 (0 errors)")
+	assert.Contains(t, a.Embed().Fields[5].Value, "command failed to execute - 1")
+}
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]float64{0.1, 1})
+	assert.Equal(t, time.Duration(0), h.snapshot().Quantile(0.5))
+
+	h.observe(50 * time.Millisecond)
+	h.observe(500 * time.Millisecond)
+	h.observe(500 * time.Millisecond)
+	h.observe(5 * time.Second)
+
+	s := h.snapshot()
+	assert.Equal(t, []uint64{1, 3}, s.Counts)
+	assert.Equal(t, uint64(4), s.Count)
+	assert.InDelta(t, 6.05, s.Sum, 1e-9)
+	assert.Equal(t, 1512500*time.Microsecond, s.Mean())
+	assert.Equal(t, 100*time.Millisecond, s.Quantile(0.25))
+	assert.Equal(t, 550*time.Millisecond, s.Quantile(0.5))
+	assert.Equal(t, time.Second, s.Quantile(0.99))
+}
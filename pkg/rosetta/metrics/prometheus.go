@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// contentType is the content type of the Prometheus text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// GaugeFunc returns the current value of a gauge.
+type GaugeFunc func() float64
+
+type gauge struct {
+	name  string
+	help  string
+	value GaugeFunc
+}
+
+// Exporter serves metrics of a Registry and additional gauges in the Prometheus text
+// exposition format. Gauges of the go runtime are always exported.
+type Exporter struct {
+	Registry *Registry
+
+	mu     sync.Mutex
+	gauges []gauge
+}
+
+// NewExporter returns an Exporter of given Registry.
+func NewExporter(r *Registry) *Exporter {
+	return &Exporter{Registry: r}
+}
+
+// Gauge adds a gauge with given name, which is read on every scrape.
+func (e *Exporter) Gauge(name, help string, value GaugeFunc) *Exporter {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.gauges = append(e.gauges, gauge{name: name, help: help, value: value})
+	return e
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_ = e.Write(w)
+}
+
+// Serve listens on given address and serves metrics at given path in the background.
+// The returned server can be stopped by Close or Shutdown.
+func (e *Exporter) Serve(addr, path string) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, e)
+	srv := &http.Server{Addr: ln.Addr().String(), Handler: mux}
+	go func() { _ = srv.Serve(ln) }()
+	return srv, nil
+}
+
+// Write writes all metrics in the text exposition format to w.
+func (e *Exporter) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	p := &printer{w: bw}
+
+	s := e.Registry.Snapshot()
+	p.header("rosetta_uptime_seconds", "gauge", "Seconds since the registry was created.")
+	p.sample("rosetta_uptime_seconds", nil, s.Uptime.Seconds())
+	p.header("rosetta_messages_scanned_total", "counter", "Messages of users scanned for commands.")
+	p.sample("rosetta_messages_scanned_total", nil, float64(s.MessagesScanned))
+	p.header("rosetta_messages_matched_total", "counter", "Messages which invoked a command.")
+	p.sample("rosetta_messages_matched_total", nil, float64(s.MessagesMatched))
+
+	// sort by domain, so series keep their order between scrapes.
+	commands := append([]CommandStats{}, s.Commands...)
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Domain < commands[j].Domain })
+
+	p.header("rosetta_command_invocations_total", "counter", "Command invocations by domain.")
+	for _, c := range commands {
+		p.sample("rosetta_command_invocations_total", []string{"domain", c.Domain}, float64(c.Invocations))
+	}
+	p.header("rosetta_command_errors_total", "counter", "Command invocations returning an error by domain.")
+	for _, c := range commands {
+		p.sample("rosetta_command_errors_total", []string{"domain", c.Domain}, float64(c.Errors))
+	}
+	p.header("rosetta_command_rate_limited_total", "counter", "Command invocations rejected by the rate limiter by domain.")
+	for _, c := range commands {
+		p.sample("rosetta_command_rate_limited_total", []string{"domain", c.Domain}, float64(c.RateLimited))
+	}
+	p.header("rosetta_command_duration_seconds", "histogram", "Command execution latency by domain.")
+	for _, c := range commands {
+		for i, b := range c.Latency.Buckets {
+			p.sample("rosetta_command_duration_seconds_bucket", []string{"domain", c.Domain, "le", formatFloat(b)}, float64(c.Latency.Counts[i]))
+		}
+		p.sample("rosetta_command_duration_seconds_bucket", []string{"domain", c.Domain, "le", "+Inf"}, float64(c.Latency.Count))
+		p.sample("rosetta_command_duration_seconds_sum", []string{"domain", c.Domain}, c.Latency.Sum)
+		p.sample("rosetta_command_duration_seconds_count", []string{"domain", c.Domain}, float64(c.Latency.Count))
+	}
+
+	errs := make([]string, 0, len(s.Errors))
+	errCounts := make(map[string]int64, len(s.Errors))
+	for t, n := range s.Errors {
+		errs = append(errs, t.String())
+		errCounts[t.String()] = n
+	}
+	sort.Strings(errs)
+	p.header("rosetta_errors_total", "counter", "Errors passed to OnError by type.")
+	for _, t := range errs {
+		p.sample("rosetta_errors_total", []string{"type", t}, float64(errCounts[t]))
+	}
+
+	e.mu.Lock()
+	gauges := append([]gauge{}, e.gauges...)
+	e.mu.Unlock()
+	for _, g := range gauges {
+		p.header(g.name, "gauge", g.help)
+		p.sample(g.name, nil, g.value())
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	p.header("go_goroutines", "gauge", "Number of goroutines that currently exist.")
+	p.sample("go_goroutines", nil, float64(runtime.NumGoroutine()))
+	p.header("go_memstats_alloc_bytes", "gauge", "Number of bytes allocated and still in use.")
+	p.sample("go_memstats_alloc_bytes", nil, float64(mem.Alloc))
+	p.header("go_memstats_heap_inuse_bytes", "gauge", "Number of heap bytes that are in use.")
+	p.sample("go_memstats_heap_inuse_bytes", nil, float64(mem.HeapInuse))
+	p.header("go_memstats_sys_bytes", "gauge", "Number of bytes obtained from system.")
+	p.sample("go_memstats_sys_bytes", nil, float64(mem.Sys))
+	p.header("go_gc_cycles_total", "counter", "Number of completed GC cycles.")
+	p.sample("go_gc_cycles_total", nil, float64(mem.NumGC))
+
+	if p.err != nil {
+		return p.err
+	}
+	return bw.Flush()
+}
+
+// printer writes lines of the exposition format, keeping the first error.
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) header(name, typ, help string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, typ)
+}
+
+// sample writes a sample with given label name and value pairs.
+func (p *printer) sample(name string, labels []string, v float64) {
+	if len(labels) == 0 {
+		p.printf("%s %s\n", name, formatFloat(v))
+		return
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labels[i], escapeLabel(labels[i+1])))
+	}
+	p.printf("%s{%s} %s\n", name, strings.Join(pairs, ","), formatFloat(v))
+}
+
+func (p *printer) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, args...)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
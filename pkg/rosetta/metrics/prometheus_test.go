@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+func TestExporter(t *testing.T) {
+	reg := New(0.1, 1)
+	reg.ObserveCommand("test.ok", 50*time.Millisecond, nil)
+	reg.ObserveCommand("test.ok", 500*time.Millisecond, nil)
+	reg.ObserveCommand(`test."quoted"`, time.Millisecond, errors.New("test error"))
+	reg.ObserveError(rosetta.ErrTypeCommandExec)
+	reg.ObserveScanned()
+
+	exp := NewExporter(reg).Gauge("iris_guilds", "Guilds of the bot.", func() float64 { return 3 })
+
+	t.Run("handler", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		exp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, contentType, rec.Header().Get("Content-Type"))
+
+		body := rec.Body.String()
+		assert.Contains(t, body, "# TYPE rosetta_command_duration_seconds histogram\n")
+		assert.Contains(t, body, "rosetta_messages_scanned_total 1\n")
+		assert.Contains(t, body, `rosetta_command_invocations_total{domain="test.ok"} 2`+"\n")
+		assert.Contains(t, body, `rosetta_command_errors_total{domain="test.\"quoted\""} 1`+"\n")
+		assert.Contains(t, body, `rosetta_command_duration_seconds_bucket{domain="test.ok",le="0.1"} 1`+"\n")
+		assert.Contains(t, body, `rosetta_command_duration_seconds_bucket{domain="test.ok",le="1"} 2`+"\n")
+		assert.Contains(t, body, `rosetta_command_duration_seconds_bucket{domain="test.ok",le="+Inf"} 2`+"\n")
+		assert.Contains(t, body, `rosetta_command_duration_seconds_sum{domain="test.ok"} 0.55`+"\n")
+		assert.Contains(t, body, `rosetta_command_duration_seconds_count{domain="test.ok"} 2`+"\n")
+		assert.Contains(t, body, `rosetta_errors_total{type="command failed to execute"} 1`+"\n")
+		assert.Contains(t, body, "# HELP iris_guilds Guilds of the bot.\n# TYPE iris_guilds gauge\niris_guilds 3\n")
+		assert.Contains(t, body, "\ngo_goroutines ")
+		assert.Contains(t, body, "\ngo_memstats_alloc_bytes ")
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		exp.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+
+	t.Run("serve", func(t *testing.T) {
+		srv, err := exp.Serve("127.0.0.1:0", "/metrics")
+		require.NoError(t, err)
+		defer srv.Close()
+
+		resp, err := http.Get("http://" + srv.Addr + "/metrics")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(body), "iris_guilds 3\n")
+	})
+}
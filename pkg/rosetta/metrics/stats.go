@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+// statsTopCommands defines how many commands are listed by StatsEmbed.
+const statsTopCommands = 5
+
+// StatsCommand responds with the metrics of its Registry.
+type StatsCommand struct {
+	Registry *Registry
+}
+
+func (s *StatsCommand) GetInvokers() []string {
+	return []string{"stats", "metrics"}
+}
+
+func (s *StatsCommand) GetDescription() string {
+	return "show command and message statistics"
+}
+
+func (s *StatsCommand) GetUsage() string {
+	return "`stats` - show statistics"
+}
+
+func (s *StatsCommand) GetGroup() string {
+	return rosetta.GroupGeneral
+}
+
+func (s *StatsCommand) GetDomain() string {
+	return "rs.general.stats"
+}
+
+func (s *StatsCommand) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (s *StatsCommand) IsExecutableInDM() bool {
+	return true
+}
+
+func (s *StatsCommand) Exec(ctx rosetta.Context) error {
+	_, err := ctx.RespondEmbed(StatsEmbed(s.Registry.Snapshot()))
+	return err
+}
+
+// StatsEmbed builds an embed of given snapshot listing totals, the most invoked commands and errors.
+func StatsEmbed(s *Snapshot) *discordgo.MessageEmbed {
+	top := "`no commands executed`"
+	if len(s.Commands) > 0 {
+		lines := make([]string, 0, statsTopCommands)
+		for i, c := range s.Commands {
+			if i == statsTopCommands {
+				break
+			}
+			lines = append(lines, fmt.Sprintf("`%s` - %d (%d errors), avg %s, p95 %s",
+				c.Domain, c.Invocations, c.Errors, round(c.Latency.Mean()), round(c.Latency.Quantile(0.95))))
+		}
+		top = strings.Join(lines, "\n")
+	}
+
+	errs := "`no errors`"
+	if len(s.Errors) > 0 {
+		types := make([]rosetta.ErrorType, 0, len(s.Errors))
+		for t := range s.Errors {
+			types = append(types, t)
+		}
+		sort.Slice(types, func(i, j int) bool { return s.Errors[types[i]] > s.Errors[types[j]] })
+		lines := make([]string, 0, len(types))
+		for _, t := range types {
+			lines = append(lines, fmt.Sprintf("%s - %d", t, s.Errors[t]))
+		}
+		errs = strings.Join(lines, "\n")
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: "Statistics",
+		Color: rosetta.EmbedColorDefault,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Uptime", Value: round(s.Uptime).String(), Inline: true},
+			{Name: "Commands executed", Value: fmt.Sprint(s.CommandsExecuted()), Inline: true},
+			{Name: "Rate limited", Value: fmt.Sprint(s.RateLimited()), Inline: true},
+			{Name: "Messages", Value: fmt.Sprintf("%d scanned, %d matched", s.MessagesScanned, s.MessagesMatched)},
+			{Name: "Top commands", Value: top},
+			{Name: "Errors", Value: errs},
+		},
+	}
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d > time.Second:
+		return d.Round(time.Second)
+	case d > time.Millisecond:
+		return d.Round(time.Millisecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
@@ -1,5 +1,10 @@
 package rosetta
 
+import (
+	"fmt"
+	"runtime/debug"
+)
+
 // MiddlewareLayer defines layer in which middleware should live and executed during
 // command parsing and handling.
 type MiddlewareLayer int
@@ -25,3 +30,58 @@ type Middleware interface {
 	// the middleware at different point.
 	GetLayer() MiddlewareLayer
 }
+
+// PermissionChecker is implemented by Middleware which restricts who can execute commands,
+// so commands can be checked without executing them, e.g. to hide them in help.
+type PermissionChecker interface {
+
+	// CanExecute returns whether the invoking user of given context is permitted to execute cmd.
+	CanExecute(cmd Command, ctx Context) (bool, error)
+}
+
+// HandlerFunc executes given command.
+type HandlerFunc func(cmd Command, ctx Context) error
+
+// MiddlewareFunc wraps the execution of a command, e.g. to time it, run it inside a
+// transaction or recover from panics. It must call next to execute the command.
+// MiddlewareFunc are executed after LayerBeforeCommand and before LayerAfterCommand
+// middleware, the first registered one is the outermost.
+type MiddlewareFunc func(next HandlerFunc) HandlerFunc
+
+// PanicError is returned by Recover when a command panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: %v\n%s", ErrCommandPanic, e.Value, e.Stack)
+}
+
+func (e *PanicError) Unwrap() error {
+	return ErrCommandPanic
+}
+
+// Recover returns a MiddlewareFunc which recovers panics of a command and returns them
+// as *PanicError, which router passes to OnError with ErrTypeCommandPanic.
+func Recover() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(cmd Command, ctx Context) (err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					err = &PanicError{Value: v, Stack: debug.Stack()}
+				}
+			}()
+			return next(cmd, ctx)
+		}
+	}
+}
+
+// recoverPanic passes a panic while handling given context to OnError as *PanicError with
+// ErrTypeCommandPanic, so a panicking middleware doesn't take down a worker. It has to be
+// deferred directly by the func handling an invocation.
+func (r *router) recoverPanic(ctx *context) {
+	if v := recover(); v != nil {
+		r.onError(ctx, ErrTypeCommandPanic, &PanicError{Value: v, Stack: debug.Stack()})
+	}
+}
@@ -0,0 +1,497 @@
+package rosetta
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ParamType defines the type an argument is parsed into.
+type ParamType int
+
+const (
+	ParamString ParamType = iota
+	ParamInt
+	ParamBool
+	ParamDuration
+	ParamUser
+	ParamRole
+	ParamChannel
+	ParamEnum
+)
+
+var paramTypeNames = map[ParamType]string{
+	ParamString:   "text",
+	ParamInt:      "number",
+	ParamBool:     "yes/no",
+	ParamDuration: "duration",
+	ParamUser:     "user",
+	ParamRole:     "role",
+	ParamChannel:  "channel",
+	ParamEnum:     "choice",
+}
+
+func (t ParamType) String() string {
+	return paramTypeNames[t]
+}
+
+// SchemaCommand can be implemented by a Command to declare its parameters. Router then
+// validates and binds arguments before Exec, which can be retrieved with Context.GetParams.
+type SchemaCommand interface {
+
+	// GetSchema returns parameters of given command, built with either NewSchema or SchemaFromStruct.
+	GetSchema() *Schema
+}
+
+// Param defines a single command parameter.
+type Param struct {
+	Name        string
+	Description string
+	Type        ParamType
+	Optional    bool
+
+	// Rest defines a variadic parameter which consumes all remaining arguments.
+	Rest bool
+
+	// Choices defines valid values of a ParamEnum.
+	Choices []string
+}
+
+// Schema is an ordered list of parameters. It can be built by chaining, e.g.
+//
+//	NewSchema().User("target", "user to kick").String("reason", "why").Optional().Rest()
+type Schema struct {
+	params []*Param
+}
+
+// NewSchema returns an empty Schema.
+func NewSchema() *Schema {
+	return &Schema{params: make([]*Param, 0)}
+}
+
+// Params returns declared parameters.
+func (s *Schema) Params() []*Param {
+	return s.params
+}
+
+// Add appends given parameter.
+func (s *Schema) Add(p *Param) *Schema {
+	s.params = append(s.params, p)
+	return s
+}
+
+// String appends a text parameter.
+func (s *Schema) String(name, desc string) *Schema {
+	return s.Add(&Param{Name: name, Description: desc, Type: ParamString})
+}
+
+// Int appends a number parameter.
+func (s *Schema) Int(name, desc string) *Schema {
+	return s.Add(&Param{Name: name, Description: desc, Type: ParamInt})
+}
+
+// Bool appends a boolean parameter.
+func (s *Schema) Bool(name, desc string) *Schema {
+	return s.Add(&Param{Name: name, Description: desc, Type: ParamBool})
+}
+
+// Duration appends a duration parameter, e.g. `1h30m`.
+func (s *Schema) Duration(name, desc string) *Schema {
+	return s.Add(&Param{Name: name, Description: desc, Type: ParamDuration})
+}
+
+// User appends a user parameter which accepts mentions and IDs.
+func (s *Schema) User(name, desc string) *Schema {
+	return s.Add(&Param{Name: name, Description: desc, Type: ParamUser})
+}
+
+// Role appends a role parameter which accepts mentions, IDs and names.
+func (s *Schema) Role(name, desc string) *Schema {
+	return s.Add(&Param{Name: name, Description: desc, Type: ParamRole})
+}
+
+// Channel appends a channel parameter which accepts mentions and IDs.
+func (s *Schema) Channel(name, desc string) *Schema {
+	return s.Add(&Param{Name: name, Description: desc, Type: ParamChannel})
+}
+
+// Enum appends a parameter which only accepts one of given choices.
+func (s *Schema) Enum(name, desc string, choices ...string) *Schema {
+	return s.Add(&Param{Name: name, Description: desc, Type: ParamEnum, Choices: choices})
+}
+
+// Optional marks the last added parameter as optional.
+func (s *Schema) Optional() *Schema {
+	if len(s.params) > 0 {
+		s.params[len(s.params)-1].Optional = true
+	}
+	return s
+}
+
+// Rest marks the last added parameter as variadic.
+func (s *Schema) Rest() *Schema {
+	if len(s.params) > 0 {
+		s.params[len(s.params)-1].Rest = true
+	}
+	return s
+}
+
+// Validate returns an error if a required parameter follows an optional one. Such a
+// parameter can't be told apart from a skipped optional one and is rejected by discord.
+func (s *Schema) Validate() error {
+	for i := 1; i < len(s.params); i++ {
+		if s.params[i-1].Optional && !s.params[i].Optional {
+			return fmt.Errorf("%w: required parameter %s follows optional parameter %s", ErrInvalidSchema, s.params[i].Name, s.params[i-1].Name)
+		}
+	}
+	return nil
+}
+
+// Usage returns a generated usage of given invoke, e.g. `kick <target> [reason...]`,
+// followed by a line for each parameter.
+func (s *Schema) Usage(invoke string) string {
+	var sig, lines strings.Builder
+	sig.WriteString(invoke)
+	for _, p := range s.params {
+		name := p.Name
+		if p.Rest {
+			name += "..."
+		}
+		if p.Optional {
+			sig.WriteString(" [" + name + "]")
+		} else {
+			sig.WriteString(" <" + name + ">")
+		}
+
+		lines.WriteString(fmt.Sprintf("\n`%s` (*%s*)", p.Name, p.Type))
+		if p.Description != "" {
+			lines.WriteString(" - " + p.Description)
+		}
+		if len(p.Choices) > 0 {
+			lines.WriteString(fmt.Sprintf(" - one of `%s`", strings.Join(p.Choices, "`, `")))
+		}
+	}
+	return "`" + sig.String() + "`" + lines.String()
+}
+
+// SchemaFromStruct builds a Schema from fields of given struct tagged with `param:"name[,optional]"`.
+// Types are inferred from field types: string, int, bool, time.Duration, *discordgo.User,
+// *discordgo.Role and *discordgo.Channel. A slice field is treated as a variadic parameter.
+// A `desc` tag sets description and a `choices:"a|b"` tag turns a string field into an enum.
+//
+// panics if a tagged field has an unsupported type or a required field follows an optional one.
+func SchemaFromStruct(v interface{}) *Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	s := NewSchema()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("param")
+		if !ok {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		p := &Param{Name: opts[0], Description: f.Tag.Get("desc")}
+		if p.Name == "" {
+			p.Name = strings.ToLower(f.Name)
+		}
+		p.Optional = arrayContains(opts[1:], "optional", true)
+
+		ft := f.Type
+		if ft.Kind() == reflect.Slice {
+			p.Rest = true
+			ft = ft.Elem()
+		}
+		pt, ok := getParamType(ft)
+		if !ok {
+			panic(fmt.Sprintf("field %s has unsupported param type %s", f.Name, f.Type))
+		}
+		p.Type = pt
+		if choices := f.Tag.Get("choices"); choices != "" && pt == ParamString {
+			p.Type = ParamEnum
+			p.Choices = strings.Split(choices, "|")
+		}
+		s.Add(p)
+	}
+	if err := s.Validate(); err != nil {
+		panic(err.Error())
+	}
+	return s
+}
+
+func getParamType(t reflect.Type) (ParamType, bool) {
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		return ParamDuration, true
+	case reflect.TypeOf(&discordgo.User{}):
+		return ParamUser, true
+	case reflect.TypeOf(&discordgo.Role{}):
+		return ParamRole, true
+	case reflect.TypeOf(&discordgo.Channel{}):
+		return ParamChannel, true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return ParamString, true
+	case reflect.Int, reflect.Int64:
+		return ParamInt, true
+	case reflect.Bool:
+		return ParamBool, true
+	default:
+	}
+	return 0, false
+}
+
+// Params holds arguments bound to a Schema.
+type Params struct {
+	values map[string]interface{}
+}
+
+// Get returns bound value of given parameter, nil if not given. A variadic parameter
+// is bound as []interface{}.
+func (p *Params) Get(name string) interface{} {
+	return p.values[name]
+}
+
+// Has returns true when given parameter has a value.
+func (p *Params) Has(name string) bool {
+	_, ok := p.values[name]
+	return ok
+}
+
+// String returns given text or choice parameter. Variadic values are joined by spaces.
+func (p *Params) String(name string) string {
+	switch v := p.values[name].(type) {
+	case string:
+		return v
+	case []interface{}:
+		s := make([]string, len(v))
+		for i, e := range v {
+			s[i] = fmt.Sprint(e)
+		}
+		return strings.Join(s, " ")
+	}
+	return ""
+}
+
+// Int returns given number parameter.
+func (p *Params) Int(name string) int {
+	v, _ := p.values[name].(int)
+	return v
+}
+
+// Bool returns given boolean parameter.
+func (p *Params) Bool(name string) bool {
+	v, _ := p.values[name].(bool)
+	return v
+}
+
+// Duration returns given duration parameter.
+func (p *Params) Duration(name string) time.Duration {
+	v, _ := p.values[name].(time.Duration)
+	return v
+}
+
+// User returns given user parameter.
+func (p *Params) User(name string) *discordgo.User {
+	v, _ := p.values[name].(*discordgo.User)
+	return v
+}
+
+// Role returns given role parameter.
+func (p *Params) Role(name string) *discordgo.Role {
+	v, _ := p.values[name].(*discordgo.Role)
+	return v
+}
+
+// Channel returns given channel parameter.
+func (p *Params) Channel(name string) *discordgo.Channel {
+	v, _ := p.values[name].(*discordgo.Channel)
+	return v
+}
+
+// Bind copies bound values into fields of given struct pointer tagged as described in SchemaFromStruct.
+func (p *Params) Bind(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: bind target must be a struct pointer", ErrInvalidArgument)
+	}
+	rv = rv.Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		tag, ok := f.Tag.Lookup("param")
+		if !ok {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		val, ok := p.values[name]
+		if !ok {
+			continue
+		}
+
+		field := rv.Field(i)
+		if rest, ok := val.([]interface{}); ok && field.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(field.Type(), len(rest), len(rest))
+			for j, e := range rest {
+				slice.Index(j).Set(reflect.ValueOf(e).Convert(field.Type().Elem()))
+			}
+			field.Set(slice)
+			continue
+		}
+		if field.Kind() == reflect.String {
+			field.SetString(p.String(name))
+			continue
+		}
+		field.Set(reflect.ValueOf(val).Convert(field.Type()))
+	}
+	return nil
+}
+
+// BindArguments validates given arguments against the schema and parses them into Params.
+// Users, roles and channels are resolved through the context.
+func (s *Schema) BindArguments(ctx Context, args []Argument) (*Params, error) {
+	params := &Params{values: make(map[string]interface{})}
+	i := 0
+	for _, p := range s.params {
+		if p.Rest {
+			rest := make([]interface{}, 0, len(args)-i)
+			for ; i < len(args); i++ {
+				v, err := p.parse(ctx, args[i])
+				if err != nil {
+					return nil, err
+				}
+				rest = append(rest, v)
+			}
+			if len(rest) == 0 {
+				if p.Optional {
+					continue
+				}
+				return nil, fmt.Errorf("%w: `%s` (*%s*)", ErrMissingArgument, p.Name, p.Type)
+			}
+			params.values[p.Name] = rest
+			continue
+		}
+
+		if i >= len(args) {
+			if p.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("%w: `%s` (*%s*)", ErrMissingArgument, p.Name, p.Type)
+		}
+		v, err := p.parse(ctx, args[i])
+		if err != nil {
+			// optional parameters are skipped when argument doesn't fit, so it can be bound to the next one.
+			if p.Optional {
+				continue
+			}
+			return nil, err
+		}
+		params.values[p.Name] = v
+		i++
+	}
+	if i < len(args) {
+		return nil, fmt.Errorf("%w: `%s`", ErrTooManyArguments, strings.Join(argsToStrings(args[i:]), " "))
+	}
+	return params, nil
+}
+
+func (p *Param) parse(ctx Context, arg Argument) (v interface{}, err error) {
+	switch p.Type {
+	case ParamString:
+		v = arg.String()
+	case ParamInt:
+		v, err = arg.AsInt()
+	case ParamBool:
+		v, err = arg.AsBool()
+	case ParamDuration:
+		v, err = arg.AsDuration()
+	case ParamUser:
+		v, err = resolveUser(ctx, arg)
+	case ParamRole:
+		v, err = resolveRole(ctx, arg)
+	case ParamChannel:
+		v, err = resolveChannel(ctx, arg)
+	case ParamEnum:
+		for _, c := range p.Choices {
+			if strings.EqualFold(c, arg.String()) {
+				return c, nil
+			}
+		}
+		err = ErrInvalidArgument
+	}
+	if err != nil || v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return nil, fmt.Errorf("%w: `%s` is not a valid %s for `%s`", ErrInvalidArgument, arg, p.Type, p.Name)
+	}
+	return v, nil
+}
+
+// getMentionID returns the ID of given mention or the argument itself if it's a snowflake.
+func getMentionID(arg Argument, mention func() string) string {
+	if id := mention(); id != "" {
+		return id
+	}
+	if _, err := arg.AsInt64(); err == nil {
+		return arg.String()
+	}
+	return ""
+}
+
+func resolveUser(ctx Context, arg Argument) (*discordgo.User, error) {
+	id := getMentionID(arg, arg.AsUserMentionID)
+	if id == "" {
+		return nil, ErrInvalidArgument
+	}
+	if msg := ctx.GetMessage(); msg != nil {
+		for _, u := range msg.Mentions {
+			if u.ID == id {
+				return u, nil
+			}
+		}
+	}
+	s := ctx.GetSession()
+	if s == nil {
+		return nil, ErrInvalidArgument
+	}
+	if g := ctx.GetGuild(); g != nil && s.State != nil {
+		if m, err := s.State.Member(g.ID, id); err == nil && m.User != nil {
+			return m.User, nil
+		}
+	}
+	return s.User(id)
+}
+
+func resolveRole(ctx Context, arg Argument) (*discordgo.Role, error) {
+	g := ctx.GetGuild()
+	if g == nil {
+		return nil, ErrInvalidArgument
+	}
+	id := getMentionID(arg, arg.AsRoleMentionID)
+	for _, r := range g.Roles {
+		if r.ID == id || (id == "" && strings.EqualFold(r.Name, arg.String())) {
+			return r, nil
+		}
+	}
+	return nil, ErrInvalidArgument
+}
+
+func resolveChannel(ctx Context, arg Argument) (*discordgo.Channel, error) {
+	id := getMentionID(arg, arg.AsChannelMentionID)
+	s := ctx.GetSession()
+	if id == "" || s == nil {
+		return nil, ErrInvalidArgument
+	}
+	if s.State != nil {
+		if c, err := s.State.Channel(id); err == nil {
+			return c, nil
+		}
+	}
+	return s.Channel(id)
+}
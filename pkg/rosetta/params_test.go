@@ -0,0 +1,129 @@
+package rosetta
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type kickParams struct {
+	Target  *discordgo.User `param:"target" desc:"user to kick"`
+	Mode    string          `param:"mode,optional" choices:"soft|hard"`
+	Reason  []string        `param:"reason,optional"`
+	ignored string
+}
+
+func makeParamsCtx() *context {
+	return &context{
+		message: &discordgo.Message{Mentions: []*discordgo.User{{ID: "200", Username: "target"}}},
+		guild: &discordgo.Guild{ID: "100", Roles: []*discordgo.Role{
+			{ID: "101", Name: "Admin"},
+			{ID: "102", Name: "Muted"},
+		}},
+	}
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	s := SchemaFromStruct(&kickParams{})
+	require.Len(t, s.Params(), 3)
+
+	assert.Equal(t, &Param{Name: "target", Description: "user to kick", Type: ParamUser}, s.Params()[0])
+	assert.Equal(t, &Param{Name: "mode", Type: ParamEnum, Optional: true, Choices: []string{"soft", "hard"}}, s.Params()[1])
+	assert.Equal(t, &Param{Name: "reason", Type: ParamString, Optional: true, Rest: true}, s.Params()[2])
+
+	assert.Panics(t, func() {
+		SchemaFromStruct(struct {
+			F float64 `param:"f"`
+		}{})
+	})
+}
+
+func TestSchema_Usage(t *testing.T) {
+	s := NewSchema().User("target", "user to kick").Enum("mode", "", "soft", "hard").Optional().String("reason", "").Optional().Rest()
+	assert.Equal(t, "`!kick <target> [mode] [reason...]`\n"+
+		"`target` (*user*) - user to kick\n"+
+		"`mode` (*choice*) - one of `soft`, `hard`\n"+
+		"`reason` (*text*)", s.Usage("!kick"))
+}
+
+func TestSchema_BindArguments(t *testing.T) {
+	s := NewSchema().Int("amount", "").Duration("after", "").Optional().Bool("silent", "").Optional().String("text", "").Optional().Rest()
+
+	tests := []struct {
+		name     string
+		args     string
+		expected map[string]interface{}
+		err      error
+	}{
+		{"all", "3 1m true hello world", map[string]interface{}{"amount": 3, "after": time.Minute, "silent": true, "text": []interface{}{"hello", "world"}}, nil},
+		{"skipOptional", "3 true hi", map[string]interface{}{"amount": 3, "silent": true, "text": []interface{}{"hi"}}, nil},
+		{"onlyRequired", "3", map[string]interface{}{"amount": 3}, nil},
+		{"missing", "", nil, ErrMissingArgument},
+		{"invalid", "three", nil, ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := s.BindArguments(makeParamsCtx(), ParseArguments(tt.args).Args())
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err), err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, params.values)
+		})
+	}
+
+	_, err := NewSchema().Int("a", "").BindArguments(makeParamsCtx(), ParseArguments("1 2").Args())
+	assert.True(t, errors.Is(err, ErrTooManyArguments))
+}
+
+func TestSchema_BindArgumentsResolve(t *testing.T) {
+	s := NewSchema().User("user", "").Role("role", "").Enum("mode", "", "soft", "hard")
+
+	params, err := s.BindArguments(makeParamsCtx(), ParseArguments("<@!200> muted HARD").Args())
+	require.NoError(t, err)
+	assert.Equal(t, "target", params.User("user").Username)
+	assert.Equal(t, "102", params.Role("role").ID)
+	assert.Equal(t, "hard", params.String("mode"))
+
+	_, err = s.BindArguments(makeParamsCtx(), ParseArguments("<@!200> <@&999> soft").Args())
+	assert.True(t, errors.Is(err, ErrInvalidArgument))
+
+	_, err = s.BindArguments(makeParamsCtx(), ParseArguments("<@!200> admin medium").Args())
+	assert.True(t, errors.Is(err, ErrInvalidArgument))
+}
+
+func TestParams_Bind(t *testing.T) {
+	s := SchemaFromStruct(&kickParams{})
+	params, err := s.BindArguments(makeParamsCtx(), ParseArguments("<@200> spamming links").Args())
+	require.NoError(t, err)
+
+	var p kickParams
+	require.NoError(t, params.Bind(&p))
+	assert.Equal(t, "200", p.Target.ID)
+	assert.Equal(t, "", p.Mode)
+	assert.Equal(t, []string{"spamming", "links"}, p.Reason)
+	assert.Equal(t, "spamming links", params.String("reason"))
+
+	assert.Error(t, params.Bind(p))
+}
+
+func TestSchema_Validate(t *testing.T) {
+	assert.NoError(t, NewSchema().Int("a", "").String("b", "").Optional().Rest().Validate())
+	err := NewSchema().Int("a", "").Optional().String("b", "").Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSchema), err)
+
+	assert.Panics(t, func() {
+		SchemaFromStruct(struct {
+			A int    `param:"a,optional"`
+			B string `param:"b"`
+		}{})
+	})
+	assert.Panics(t, func() {
+		NewRouter(makeTestConfig()).Register(&TestSchemaCmd{schema: NewSchema().Int("a", "").Optional().Int("b", "")})
+	})
+}
@@ -0,0 +1,100 @@
+package permissions
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RuleRegex defines regex a permission rule should match, e.g. `+rs.etc.*` or `-rs.moderation.kick`.
+var RuleRegex = regexp.MustCompile(`^[+-]?(\*|[\w-]+(\.[\w-]+)*(\.\*)?)$`)
+
+// Array is a list of permission rules. Each rule is a domain prefixed with
+// either `+` (allow) or `-` (deny). Domains can end with a `*` wildcard.
+type Array []string
+
+// ParseRule validates given rule and returns it with an explicit sign.
+// A rule without sign is treated as allow.
+func ParseRule(rule string) (string, error) {
+	rule = strings.ToLower(strings.TrimSpace(rule))
+	if !RuleRegex.MatchString(rule) {
+		return "", ErrInvalidRule
+	}
+	if rule[0] != '+' && rule[0] != '-' {
+		rule = "+" + rule
+	}
+	return rule, nil
+}
+
+// Update adds given rule to the array. An already existing rule for the same domain is replaced.
+// Returns false if given rule is already part of the array.
+func (a Array) Update(rule string) (Array, bool) {
+	for i, r := range a {
+		if r == rule {
+			return a, false
+		}
+		if r[1:] == rule[1:] {
+			a[i] = rule
+			return a, true
+		}
+	}
+	return append(a, rule), true
+}
+
+// Remove removes the rule for given domain regardless of its sign.
+// Returns false if no rule for given domain exists.
+func (a Array) Remove(rule string) (Array, bool) {
+	domain := strings.TrimLeft(rule, "+-")
+	for i, r := range a {
+		if r[1:] == domain {
+			return append(a[:i], a[i+1:]...), true
+		}
+	}
+	return a, false
+}
+
+// Merge returns a new array where rules of o override rules of a for the same domain.
+func (a Array) Merge(o Array) Array {
+	res := make(Array, len(a), len(a)+len(o))
+	copy(res, a)
+	for _, r := range o {
+		res, _ = res.Update(r)
+	}
+	return res
+}
+
+// Has returns whether given domain is allowed by the array. The most specific matching rule
+// wins, deny wins when equally specific.
+//
+// If explicit is true, only a rule matching the exact domain applies, thus wildcards
+// are not taken into account.
+func (a Array) Has(domain string, explicit bool) bool {
+	domain = strings.ToLower(domain)
+
+	allowed, best := false, -1
+	for _, r := range a {
+		match, weight := matchRule(r[1:], domain)
+		if !match || (explicit && r[1:] != domain) {
+			continue
+		}
+		deny := r[0] == '-'
+		if weight > best || (weight == best && deny) {
+			best = weight
+			allowed = !deny
+		}
+	}
+	return allowed
+}
+
+// matchRule checks if given rule domain covers domain and returns the specificity of the match.
+func matchRule(rule, domain string) (bool, int) {
+	if rule == domain {
+		return true, len(strings.Split(domain, ".")) + 1
+	}
+	if rule == "*" {
+		return true, 0
+	}
+	if strings.HasSuffix(rule, ".*") && strings.HasPrefix(domain, rule[:len(rule)-1]) {
+		return true, len(strings.Split(rule, ".")) - 1
+	}
+	return false, -1
+}
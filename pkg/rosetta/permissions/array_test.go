@@ -0,0 +1,90 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		rule     string
+		expected string
+		valid    bool
+	}{
+		{"rs.etc.*", "+rs.etc.*", true},
+		{"+RS.Etc.Help", "+rs.etc.help", true},
+		{"-rs.moderation.kick", "-rs.moderation.kick", true},
+		{"*", "+*", true},
+		{"rs..etc", "", false},
+		{"rs.*.etc", "", false},
+		{"+", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			out, err := ParseRule(tt.rule)
+			assert.Equal(t, tt.expected, out)
+			if tt.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidRule)
+			}
+		})
+	}
+}
+
+func TestArray_UpdateRemove(t *testing.T) {
+	a := Array{"+rs.etc.*"}
+
+	a, ok := a.Update("+rs.etc.*")
+	assert.False(t, ok)
+
+	a, ok = a.Update("-rs.etc.*")
+	assert.True(t, ok)
+	assert.Equal(t, Array{"-rs.etc.*"}, a)
+
+	a, ok = a.Update("+rs.fun.*")
+	assert.True(t, ok)
+	assert.Len(t, a, 2)
+
+	a, ok = a.Remove("+rs.etc.*")
+	assert.True(t, ok)
+	assert.Equal(t, Array{"+rs.fun.*"}, a)
+
+	_, ok = a.Remove("rs.chat.*")
+	assert.False(t, ok)
+}
+
+func TestArray_Merge(t *testing.T) {
+	base := Array{"+rs.etc.*", "+rs.fun.*"}
+	merged := base.Merge(Array{"-rs.fun.*", "+rs.moderation.kick"})
+	assert.Equal(t, Array{"+rs.etc.*", "-rs.fun.*", "+rs.moderation.kick"}, merged)
+	assert.Equal(t, Array{"+rs.etc.*", "+rs.fun.*"}, base)
+}
+
+func TestArray_Has(t *testing.T) {
+	a := Array{"+rs.*", "-rs.moderation.*", "+rs.moderation.kick", "-rs.etc.help"}
+
+	tests := []struct {
+		name     string
+		domain   string
+		explicit bool
+		expected bool
+	}{
+		{"wildcard", "rs.fun.meme", false, true},
+		{"more specific deny", "rs.moderation.ban", false, false},
+		{"exact allow overrides deny", "rs.moderation.kick", false, true},
+		{"exact deny overrides allow", "rs.etc.help", false, false},
+		{"not covered", "other.fun", false, false},
+		{"explicit ignores wildcard", "rs.fun.meme", true, false},
+		{"explicit exact", "rs.moderation.kick", true, true},
+		{"case insensitive", "RS.Moderation.Kick", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, a.Has(tt.domain, tt.explicit))
+		})
+	}
+	assert.True(t, Array{"+*"}.Has("anything.at.all", false))
+	assert.False(t, Array{"+*", "-*"}.Has("anything", false))
+}
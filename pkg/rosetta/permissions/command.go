@@ -0,0 +1,324 @@
+package permissions
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+var (
+	// ErrInvalidTarget is thrown when given argument is neither a role nor a user.
+	ErrInvalidTarget = errors.New("invalid role or user")
+
+	// ErrInvalidUsage is thrown when command arguments are missing.
+	ErrInvalidUsage = errors.New("invalid arguments")
+)
+
+// Command returns admin command to grant, revoke and list rules stored in p.
+func (p *Permissions) Command() rosetta.Command {
+	return &permsCommand{
+		p: p,
+		subs: []rosetta.Command{
+			&grantCommand{p: p},
+			&revokeCommand{p: p},
+			&listCommand{p: p},
+		},
+	}
+}
+
+type permsCommand struct {
+	p    *Permissions
+	subs []rosetta.Command
+}
+
+func (c *permsCommand) GetInvokers() []string {
+	return []string{"perms", "permissions", "perm"}
+}
+
+func (c *permsCommand) GetDescription() string {
+	return "manage permission rules of roles and users"
+}
+
+func (c *permsCommand) GetUsage() string {
+	return "`perms grant <+|-rule> <@role|@user>...` - allow or deny given rule\n" +
+		"`perms revoke <rule> <@role|@user>...` - remove given rule\n" +
+		"`perms list [@role|@user]` - list rules"
+}
+
+func (c *permsCommand) GetGroup() string {
+	return rosetta.GroupGuildAdmin
+}
+
+func (c *permsCommand) GetDomain() string {
+	return "rs.guild.config.perms"
+}
+
+func (c *permsCommand) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (c *permsCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (c *permsCommand) GetSubCommands() []rosetta.Command {
+	return c.subs
+}
+
+func (c *permsCommand) Exec(ctx rosetta.Context) error {
+	_, err := ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title:       ctx.T("rosetta.permissions.title"),
+		Description: c.GetUsage(),
+		Color:       rosetta.EmbedColorDefault,
+	})
+	return err
+}
+
+type grantCommand struct {
+	p *Permissions
+}
+
+func (c *grantCommand) GetInvokers() []string {
+	return []string{"grant", "set", "add"}
+}
+
+func (c *grantCommand) GetDescription() string {
+	return "allow or deny a rule for roles or users"
+}
+
+func (c *grantCommand) GetUsage() string {
+	return "`perms grant <+|-rule> <@role|@user>...` - e.g. `perms grant -rs.fun.* @muted`"
+}
+
+func (c *grantCommand) GetGroup() string {
+	return rosetta.GroupGuildAdmin
+}
+
+func (c *grantCommand) GetDomain() string {
+	return "rs.guild.config.perms.grant"
+}
+
+func (c *grantCommand) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (c *grantCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (c *grantCommand) Exec(ctx rosetta.Context) error {
+	return updateRules(ctx, c.p, c.GetUsage(), func(perms Array, rule string) (Array, bool) {
+		return perms.Update(rule)
+	})
+}
+
+type revokeCommand struct {
+	p *Permissions
+}
+
+func (c *revokeCommand) GetInvokers() []string {
+	return []string{"revoke", "remove", "rm"}
+}
+
+func (c *revokeCommand) GetDescription() string {
+	return "remove a rule from roles or users"
+}
+
+func (c *revokeCommand) GetUsage() string {
+	return "`perms revoke <rule> <@role|@user>...` - e.g. `perms revoke rs.fun.* @muted`"
+}
+
+func (c *revokeCommand) GetGroup() string {
+	return rosetta.GroupGuildAdmin
+}
+
+func (c *revokeCommand) GetDomain() string {
+	return "rs.guild.config.perms.revoke"
+}
+
+func (c *revokeCommand) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (c *revokeCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (c *revokeCommand) Exec(ctx rosetta.Context) error {
+	return updateRules(ctx, c.p, c.GetUsage(), func(perms Array, rule string) (Array, bool) {
+		return perms.Remove(rule)
+	})
+}
+
+type listCommand struct {
+	p *Permissions
+}
+
+func (c *listCommand) GetInvokers() []string {
+	return []string{"list", "ls"}
+}
+
+func (c *listCommand) GetDescription() string {
+	return "list rules of all roles or of a given role or user"
+}
+
+func (c *listCommand) GetUsage() string {
+	return "`perms list` - list rules of all roles\n" + "`perms list <@role|@user>` - list rules of given role or user"
+}
+
+func (c *listCommand) GetGroup() string {
+	return rosetta.GroupGuildAdmin
+}
+
+func (c *listCommand) GetDomain() string {
+	return "rs.guild.config.perms.list"
+}
+
+func (c *listCommand) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (c *listCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (c *listCommand) Exec(ctx rosetta.Context) error {
+	guild := ctx.GetGuild()
+	embed := &discordgo.MessageEmbed{
+		Title:  ctx.T("rosetta.permissions.rules_title"),
+		Color:  rosetta.EmbedColorDefault,
+		Fields: make([]*discordgo.MessageEmbedField, 0),
+	}
+
+	if ctx.GetArguments().Len() > 0 {
+		t, err := getTarget(guild, ctx.GetArguments().Get(0))
+		if err != nil {
+			_, err = ctx.RespondEmbedError(c.GetUsage(), err)
+			return err
+		}
+		perms, err := t.get(c.p.p, guild.ID)
+		if err != nil {
+			return err
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: t.name, Value: formatRules(perms)})
+		_, err = ctx.RespondEmbed(embed)
+		return err
+	}
+
+	ids := make([]string, len(guild.Roles))
+	for i, r := range guild.Roles {
+		ids[i] = r.ID
+	}
+	rolePerms, err := c.p.p.GetRolePermissions(guild.ID, ids)
+	if err != nil {
+		return err
+	}
+	for _, r := range getMemberRoles(guild, ids) {
+		if perms, ok := rolePerms[r.ID]; ok && len(perms) > 0 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: r.Name, Value: formatRules(perms)})
+		}
+	}
+	embed.Description = ctx.T("rosetta.permissions.defaults", formatRules(c.p.defaults))
+	_, err = ctx.RespondEmbed(embed)
+	return err
+}
+
+// target is a role or user rules can be assigned to.
+type target struct {
+	id     string
+	name   string
+	isRole bool
+}
+
+func (t *target) get(p Provider, guildID string) (Array, error) {
+	if t.isRole {
+		perms, err := p.GetRolePermissions(guildID, []string{t.id})
+		return perms[t.id], err
+	}
+	return p.GetUserPermissions(guildID, t.id)
+}
+
+func (t *target) set(p Provider, guildID string, perms Array) error {
+	if t.isRole {
+		return p.SetRolePermissions(guildID, t.id, perms)
+	}
+	return p.SetUserPermissions(guildID, t.id, perms)
+}
+
+// getTarget resolves given argument to a role or user of the guild. Accepts mentions,
+// raw IDs and `everyone`.
+func getTarget(guild *discordgo.Guild, arg rosetta.Argument) (*target, error) {
+	id := arg.AsRoleMentionID()
+	if strings.TrimPrefix(arg.String(), "@") == "everyone" {
+		id = guild.ID
+	}
+	if id == "" && arg.AsUserMentionID() == "" {
+		id = arg.String()
+	}
+	for _, r := range guild.Roles {
+		if r.ID == id {
+			return &target{id: r.ID, name: r.Name, isRole: true}, nil
+		}
+	}
+
+	if uid := arg.AsUserMentionID(); uid != "" {
+		return &target{id: uid, name: arg.String()}, nil
+	}
+	if _, err := arg.AsInt64(); err == nil {
+		return &target{id: arg.String(), name: "<@" + arg.String() + ">"}, nil
+	}
+	return nil, ErrInvalidTarget
+}
+
+// updateRules applies f with the rule from first argument to all following targets.
+func updateRules(ctx rosetta.Context, p *Permissions, usage string, f func(perms Array, rule string) (Array, bool)) error {
+	args := ctx.GetArguments()
+	if args.Len() < 2 {
+		_, err := ctx.RespondEmbedError(usage, ErrInvalidUsage)
+		return err
+	}
+	rule, err := ParseRule(args.Get(0).String())
+	if err != nil {
+		_, err = ctx.RespondEmbedError(usage, err)
+		return err
+	}
+
+	guild := ctx.GetGuild()
+	updated := make([]string, 0)
+	for _, arg := range args.Args()[1:] {
+		t, err := getTarget(guild, arg)
+		if err != nil {
+			_, err = ctx.RespondEmbedError(ctx.T("rosetta.permissions.invalid_target", arg), err)
+			return err
+		}
+		perms, err := t.get(p.p, guild.ID)
+		if err != nil {
+			return err
+		}
+		perms, changed := f(perms, rule)
+		if !changed {
+			continue
+		}
+		if err = t.set(p.p, guild.ID, perms); err != nil {
+			return err
+		}
+		updated = append(updated, t.name)
+	}
+
+	desc := ctx.T("rosetta.permissions.unchanged")
+	if len(updated) > 0 {
+		desc = ctx.T("rosetta.permissions.updated", rule, strings.Join(updated, ", "))
+	}
+	_, err = ctx.RespondEmbed(&discordgo.MessageEmbed{Title: ctx.T("rosetta.permissions.title"), Description: desc, Color: rosetta.EmbedColorDefault})
+	return err
+}
+
+func formatRules(perms Array) string {
+	if len(perms) == 0 {
+		return "`no rules`"
+	}
+	return "`" + strings.Join(perms, "`\n`") + "`"
+}
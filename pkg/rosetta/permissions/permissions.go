@@ -0,0 +1,169 @@
+// Package permissions provides a domain-based permission middleware for rosetta router.
+// Rules are defined per guild for roles and users, e.g. `+rs.etc.*` or `-rs.moderation.kick`,
+// and are checked against Command.GetDomain and its SubPermission rules.
+package permissions
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+// ObjectMapKeyPermissions is the key Permissions is stored under in a context object map.
+const ObjectMapKeyPermissions = "rosetta_permissions"
+
+var (
+	// ErrNotPermitted is thrown when user lacks permission to execute a command.
+	ErrNotPermitted = errors.New("missing permission")
+
+	// ErrInvalidRule is thrown when a permission rule has an invalid format.
+	ErrInvalidRule = errors.New("invalid permission rule")
+
+	// DefaultRules defines rules every user has unless overridden.
+	DefaultRules = Array{"+rs.etc.*", "+rs.chat.*", "+rs.fun.*"}
+)
+
+// Permissions implements a middleware which checks whether the invoking user is permitted
+// to run a command. It also acts as resolver for sub permissions inside Command.Exec.
+type Permissions struct {
+	p        Provider
+	defaults Array
+}
+
+// New returns a new instance of Permissions. If no provider is given, rules are kept in memory.
+// If no default rules are given, DefaultRules will be used.
+func New(p Provider, defaults ...string) *Permissions {
+	if p == nil {
+		p = NewMemoryProvider()
+	}
+	d := DefaultRules
+	if len(defaults) > 0 {
+		d = Array(defaults)
+	}
+	return &Permissions{p: p, defaults: d}
+}
+
+// GetProvider returns the Provider rules are stored in.
+func (p *Permissions) GetProvider() Provider {
+	return p.p
+}
+
+func (p *Permissions) Handle(cmd rosetta.Command, ctx rosetta.Context, layer rosetta.MiddlewareLayer) (bool, error) {
+	ctx.SetObject(ObjectMapKeyPermissions, p)
+
+	ok, err := p.HasPermission(ctx, cmd.GetDomain(), false)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		_, err = ctx.RespondEmbedError(ctx.T("rosetta.permissions.denied"), ErrNotPermitted)
+		return false, err
+	}
+	return true, nil
+}
+
+// CanExecute lets Permissions act as rosetta.PermissionChecker, so help hides commands
+// the invoking user isn't permitted to.
+func (p *Permissions) CanExecute(cmd rosetta.Command, ctx rosetta.Context) (bool, error) {
+	return p.HasPermission(ctx, cmd.GetDomain(), false)
+}
+
+func (p *Permissions) GetLayer() rosetta.MiddlewareLayer {
+	return rosetta.LayerBeforeCommand
+}
+
+// GetPermissions returns the resolved permission rules of the invoking user. Rules of higher roles
+// override lower ones and user rules override role rules. override is true when the user is
+// guild owner, thus all permissions including explicit ones are granted.
+func (p *Permissions) GetPermissions(ctx rosetta.Context) (perms Array, override bool, err error) {
+	perms = append(Array{}, p.defaults...)
+
+	guild, member := ctx.GetGuild(), ctx.GetMember()
+	if ctx.IsDM() || guild == nil || member == nil {
+		return perms, false, nil
+	}
+	if guild.OwnerID == ctx.GetUser().ID {
+		return perms, true, nil
+	}
+
+	// @everyone role shares its ID with the guild and isn't part of member roles.
+	roles := getMemberRoles(guild, append([]string{guild.ID}, member.Roles...))
+	for _, r := range roles {
+		if r.Permissions&discordgo.PermissionAdministrator != 0 {
+			perms, _ = perms.Update("+*")
+			break
+		}
+	}
+
+	ids := make([]string, len(roles))
+	for i, r := range roles {
+		ids[i] = r.ID
+	}
+	rolePerms, err := p.p.GetRolePermissions(guild.ID, ids)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, id := range ids {
+		perms = perms.Merge(rolePerms[id])
+	}
+
+	userPerms, err := p.p.GetUserPermissions(guild.ID, ctx.GetUser().ID)
+	if err != nil {
+		return nil, false, err
+	}
+	return perms.Merge(userPerms), false, nil
+}
+
+// HasPermission returns whether the invoking user is permitted to given domain.
+// If explicit is true, wildcard rules will not grant the permission.
+func (p *Permissions) HasPermission(ctx rosetta.Context, domain string, explicit bool) (bool, error) {
+	if domain == "" {
+		return true, nil
+	}
+	perms, override, err := p.GetPermissions(ctx)
+	if err != nil {
+		return false, err
+	}
+	return override || perms.Has(domain, explicit), nil
+}
+
+// CheckSubPerm returns whether the invoking user is permitted to given sub permission term of cmd.
+// Whether the term is explicit is defined by cmd.GetSubPermissionRules.
+func (p *Permissions) CheckSubPerm(ctx rosetta.Context, cmd rosetta.Command, term string) (bool, error) {
+	explicit := false
+	for _, rule := range cmd.GetSubPermissionRules() {
+		if rule.Term == term {
+			explicit = rule.Explicit
+			break
+		}
+	}
+	return p.HasPermission(ctx, rosetta.GetTermAssembly(cmd, term), explicit)
+}
+
+// CheckSubPerm is a shorthand to check a sub permission inside Command.Exec with the Permissions
+// instance set by the middleware. Returns true if no Permissions middleware is registered.
+func CheckSubPerm(ctx rosetta.Context, cmd rosetta.Command, term string) (bool, error) {
+	p, ok := ctx.GetObject(ObjectMapKeyPermissions).(*Permissions)
+	if !ok {
+		return true, nil
+	}
+	return p.CheckSubPerm(ctx, cmd, term)
+}
+
+// getMemberRoles returns roles of given guild with given IDs sorted ascending by position.
+func getMemberRoles(guild *discordgo.Guild, ids []string) []*discordgo.Role {
+	roles := make([]*discordgo.Role, 0, len(ids))
+	for _, r := range guild.Roles {
+		for _, id := range ids {
+			if r.ID == id {
+				roles = append(roles, r)
+				break
+			}
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Position < roles[j].Position })
+	return roles
+}
@@ -0,0 +1,264 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+const (
+	testGuild  = "100"
+	testUser   = "uid"
+	roleMod    = "101"
+	roleMuted  = "102"
+	roleAdmin  = "103"
+	testDomain = "rs.moderation.kick"
+)
+
+func TestPermissions_HasPermission(t *testing.T) {
+	p := New(nil)
+	pr := p.GetProvider()
+	assert.Nil(t, pr.SetRolePermissions(testGuild, roleMod, Array{"+rs.moderation.*"}))
+	assert.Nil(t, pr.SetRolePermissions(testGuild, roleMuted, Array{"-rs.moderation.*", "-rs.fun.*"}))
+
+	tests := []struct {
+		name     string
+		ctx      *testContext
+		domain   string
+		explicit bool
+		expected bool
+	}{
+		{"default rules", newTestContext(), "rs.etc.help", false, true},
+		{"default rules deny", newTestContext(), testDomain, false, false},
+		{"empty domain", newTestContext(), "", false, true},
+		{"role grants", newTestContext(roleMod), testDomain, false, true},
+		{"higher role overrides", newTestContext(roleMod, roleMuted), testDomain, false, false},
+		{"higher role denies defaults", newTestContext(roleMuted), "rs.fun.meme", false, false},
+		{"admin", newTestContext(roleAdmin), "rs.guild.config.perms", false, true},
+		{"admin without explicit", newTestContext(roleAdmin), "rs.guild.config.perms", true, false},
+		{"owner explicit", newOwnerContext(), "rs.guild.config.perms", true, true},
+		{"dm uses defaults", newDMContext(), "rs.etc.help", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := p.HasPermission(tt.ctx, tt.domain, tt.explicit)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+
+	t.Run("user rules override roles", func(t *testing.T) {
+		assert.Nil(t, pr.SetUserPermissions(testGuild, testUser, Array{"+rs.moderation.kick"}))
+		defer func() { _ = pr.SetUserPermissions(testGuild, testUser, nil) }()
+		ok, _ := p.HasPermission(newTestContext(roleMuted), testDomain, false)
+		assert.True(t, ok)
+	})
+}
+
+func TestPermissions_CheckSubPerm(t *testing.T) {
+	p := New(nil, "+test.*")
+	cmd := &testCmd{}
+	ctx := newTestContext()
+
+	ok, err := p.CheckSubPerm(ctx, cmd, "nonexplicit")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	ok, _ = p.CheckSubPerm(ctx, cmd, "explicit")
+	assert.False(t, ok)
+
+	assert.Nil(t, p.GetProvider().SetUserPermissions(testGuild, testUser, Array{"+test.cmd.explicit"}))
+	ok, _ = p.CheckSubPerm(ctx, cmd, "explicit")
+	assert.True(t, ok)
+
+	t.Run("shorthand without middleware", func(t *testing.T) {
+		ok, err := CheckSubPerm(newTestContext(), cmd, "explicit")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestPermissions_Handle(t *testing.T) {
+	p := New(nil)
+	assert.Equal(t, rosetta.LayerBeforeCommand, p.GetLayer())
+
+	ctx := newTestContext()
+	ok, err := p.Handle(&testCmd{domain: "rs.etc.ping"}, ctx, p.GetLayer())
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, p, ctx.GetObject(ObjectMapKeyPermissions))
+
+	ok, err = p.Handle(&testCmd{domain: testDomain}, ctx, p.GetLayer())
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Len(t, ctx.embeds, 1)
+
+	t.Run("can execute without responding", func(t *testing.T) {
+		ctx := newTestContext()
+		var _ rosetta.PermissionChecker = p
+		ok, err := p.CanExecute(&testCmd{domain: testDomain}, ctx)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.Empty(t, ctx.embeds)
+	})
+}
+
+func TestCommand_Exec(t *testing.T) {
+	p := New(nil)
+	cmd := p.Command()
+	subs := cmd.(rosetta.ParentCommand).GetSubCommands()
+	grant, revoke, list := subs[0], subs[1], subs[2]
+
+	ctx := newTestContext()
+	ctx.args = rosetta.ParseArguments("-rs.fun.* <@&102> <@123>")
+	assert.Nil(t, grant.Exec(ctx))
+
+	perms, _ := p.GetProvider().GetRolePermissions(testGuild, []string{roleMuted})
+	assert.Equal(t, Array{"-rs.fun.*"}, perms[roleMuted])
+	userPerms, _ := p.GetProvider().GetUserPermissions(testGuild, "123")
+	assert.Equal(t, Array{"-rs.fun.*"}, userPerms)
+
+	ctx.args = rosetta.ParseArguments("rs.fun.* 102")
+	assert.Nil(t, revoke.Exec(ctx))
+	perms, _ = p.GetProvider().GetRolePermissions(testGuild, []string{roleMuted})
+	assert.Empty(t, perms[roleMuted])
+
+	ctx.args = rosetta.ParseArguments("<@123>")
+	assert.Nil(t, list.Exec(ctx))
+	assert.Contains(t, ctx.embeds[len(ctx.embeds)-1].Fields[0].Value, "-rs.fun.*")
+
+	ctx.args = rosetta.ParseArguments("rs..fun <@123>")
+	assert.Nil(t, grant.Exec(ctx))
+	assert.Equal(t, rosetta.EmbedColorError, ctx.embeds[len(ctx.embeds)-1].Color)
+
+	ctx.args = rosetta.ParseArguments("rs.fun.* not-a-target")
+	assert.Nil(t, grant.Exec(ctx))
+	assert.Equal(t, rosetta.EmbedColorError, ctx.embeds[len(ctx.embeds)-1].Color)
+}
+
+// testContext implements only what permissions needs out of rosetta.Context.
+type testContext struct {
+	rosetta.Context
+	isDM    bool
+	guild   *discordgo.Guild
+	member  *discordgo.Member
+	args    *rosetta.Arguments
+	objects map[string]interface{}
+	embeds  []*discordgo.MessageEmbed
+}
+
+func newTestContext(roles ...string) *testContext {
+	return &testContext{
+		guild: &discordgo.Guild{
+			ID:      testGuild,
+			OwnerID: "owner",
+			Roles: []*discordgo.Role{
+				{ID: testGuild, Name: "@everyone", Position: 0},
+				{ID: roleMod, Name: "mod", Position: 1},
+				{ID: roleMuted, Name: "muted", Position: 2},
+				{ID: roleAdmin, Name: "admin", Position: 3, Permissions: discordgo.PermissionAdministrator},
+			},
+		},
+		member:  &discordgo.Member{User: &discordgo.User{ID: testUser}, Roles: roles},
+		objects: make(map[string]interface{}),
+	}
+}
+
+func newOwnerContext() *testContext {
+	ctx := newTestContext()
+	ctx.member.User.ID = "owner"
+	return ctx
+}
+
+func newDMContext() *testContext {
+	ctx := newTestContext()
+	ctx.isDM = true
+	ctx.guild = nil
+	return ctx
+}
+
+func (tc *testContext) GetObject(key string) interface{} {
+	return tc.objects[key]
+}
+
+func (tc *testContext) SetObject(key string, value interface{}) {
+	tc.objects[key] = value
+}
+
+func (tc *testContext) GetArguments() *rosetta.Arguments {
+	return tc.args
+}
+
+func (tc *testContext) GetGuild() *discordgo.Guild {
+	return tc.guild
+}
+
+func (tc *testContext) GetUser() *discordgo.User {
+	return tc.member.User
+}
+
+func (tc *testContext) GetMember() *discordgo.Member {
+	return tc.member
+}
+
+func (tc *testContext) IsDM() bool {
+	return tc.isDM
+}
+
+func (tc *testContext) T(key string, _ ...interface{}) string {
+	return key
+}
+
+func (tc *testContext) RespondEmbed(embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
+	tc.embeds = append(tc.embeds, embed)
+	return &discordgo.Message{}, nil
+}
+
+func (tc *testContext) RespondEmbedError(title string, err error) (*discordgo.Message, error) {
+	return tc.RespondEmbed(&discordgo.MessageEmbed{Title: title, Description: err.Error(), Color: rosetta.EmbedColorError})
+}
+
+type testCmd struct {
+	domain string
+}
+
+func (t *testCmd) GetInvokers() []string {
+	return []string{"cmd"}
+}
+
+func (t *testCmd) GetDescription() string {
+	return ""
+}
+
+func (t *testCmd) GetUsage() string {
+	return ""
+}
+
+func (t *testCmd) GetGroup() string {
+	return rosetta.GroupEtc
+}
+
+func (t *testCmd) GetDomain() string {
+	if t.domain == "" {
+		return "test.cmd"
+	}
+	return t.domain
+}
+
+func (t *testCmd) GetSubPermissionRules() []rosetta.SubPermission {
+	return []rosetta.SubPermission{
+		{Term: "explicit", Explicit: true},
+		{Term: "nonexplicit"},
+	}
+}
+
+func (t *testCmd) IsExecutableInDM() bool {
+	return true
+}
+
+func (t *testCmd) Exec(_ rosetta.Context) error {
+	return nil
+}
@@ -0,0 +1,70 @@
+package permissions
+
+import "sync"
+
+// Provider stores permission rules of roles and users per guild.
+// This can be implemented to persist rules into a database.
+type Provider interface {
+
+	// GetRolePermissions returns permission rules of given roles mapped by role ID.
+	GetRolePermissions(guildID string, roleIDs []string) (map[string]Array, error)
+
+	// GetUserPermissions returns permission rules of given user.
+	GetUserPermissions(guildID, userID string) (Array, error)
+
+	// SetRolePermissions replaces permission rules of given role.
+	SetRolePermissions(guildID, roleID string, perms Array) error
+
+	// SetUserPermissions replaces permission rules of given user.
+	SetUserPermissions(guildID, userID string, perms Array) error
+}
+
+type memoryProvider struct {
+	mu    sync.RWMutex
+	roles map[string]Array
+	users map[string]Array
+}
+
+// NewMemoryProvider returns a Provider which keeps rules in memory.
+func NewMemoryProvider() Provider {
+	return &memoryProvider{
+		roles: make(map[string]Array),
+		users: make(map[string]Array),
+	}
+}
+
+func (m *memoryProvider) GetRolePermissions(guildID string, roleIDs []string) (map[string]Array, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := make(map[string]Array)
+	for _, id := range roleIDs {
+		if perms, ok := m.roles[guildID+":"+id]; ok {
+			res[id] = append(Array{}, perms...)
+		}
+	}
+	return res, nil
+}
+
+func (m *memoryProvider) GetUserPermissions(guildID, userID string) (Array, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append(Array{}, m.users[guildID+":"+userID]...), nil
+}
+
+func (m *memoryProvider) SetRolePermissions(guildID, roleID string, perms Array) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.roles[guildID+":"+roleID] = perms
+	return nil
+}
+
+func (m *memoryProvider) SetUserPermissions(guildID, userID string, perms Array) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.users[guildID+":"+userID] = perms
+	return nil
+}
@@ -0,0 +1,240 @@
+package rosetta
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MaxGuildPrefixes defines how many custom prefixes a guild can configure.
+const MaxGuildPrefixes = 5
+
+// PrefixProvider stores custom prefixes per guild.
+// This can be implemented to persist prefixes into a database.
+type PrefixProvider interface {
+
+	// GetPrefixes returns custom prefixes of given guild.
+	GetPrefixes(guildID string) ([]string, error)
+
+	// SetPrefixes replaces custom prefixes of given guild.
+	SetPrefixes(guildID string, prefixes []string) error
+}
+
+type memoryPrefixProvider struct {
+	mu       sync.RWMutex
+	prefixes map[string][]string
+}
+
+// NewMemoryPrefixProvider returns a PrefixProvider which keeps prefixes in memory.
+func NewMemoryPrefixProvider() PrefixProvider {
+	return &memoryPrefixProvider{prefixes: make(map[string][]string)}
+}
+
+func (m *memoryPrefixProvider) GetPrefixes(guildID string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]string{}, m.prefixes[guildID]...), nil
+}
+
+func (m *memoryPrefixProvider) SetPrefixes(guildID string, prefixes []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prefixes[guildID] = prefixes
+	return nil
+}
+
+// getMentionPrefixes returns the mentions of our bot user, which always act as prefix.
+func getMentionPrefixes(s *discordgo.Session) []string {
+	if s == nil || s.State == nil || s.State.User == nil {
+		return nil
+	}
+	return []string{"<@" + s.State.User.ID + ">", "<@!" + s.State.User.ID + ">"}
+}
+
+// getPrefixes returns all prefixes commands of given guild can be invoked with.
+func (r *router) getPrefixes(s *discordgo.Session, guildID string) ([]string, error) {
+	prefixes := append(getMentionPrefixes(s), r.config.GeneralPrefix)
+	if guildID == "" {
+		return prefixes, nil
+	}
+
+	guildPrefix, err := r.config.GuildPrefixGetter(guildID)
+	if err != nil {
+		return prefixes, err
+	}
+	custom, err := r.config.PrefixProvider.GetPrefixes(guildID)
+	return append(append(prefixes, guildPrefix), custom...), err
+}
+
+// resolvePrefix returns the longest of given prefixes content starts with and the content
+// without it. Thus overlapping prefixes like `r!` and `r!!` are resolved deterministically.
+func resolvePrefix(content string, prefixes []string, ignoreCase bool) (prefix, trimmed string, ok bool) {
+	for _, p := range prefixes {
+		if p == "" || len(p) <= len(prefix) {
+			continue
+		}
+		if t, contains := hasPrefix(content, p, ignoreCase); contains {
+			prefix, trimmed, ok = p, t, true
+		}
+	}
+	return prefix, strings.TrimLeft(trimmed, " \n\t"), ok
+}
+
+// DefaultPrefixCommand lets guild admins view and change the prefixes of their guild.
+type DefaultPrefixCommand struct{}
+
+func (d *DefaultPrefixCommand) GetInvokers() []string {
+	return []string{"prefix", "prefixes"}
+}
+
+func (d *DefaultPrefixCommand) GetDescription() string {
+	return "view and change the command prefixes of this guild"
+}
+
+func (d *DefaultPrefixCommand) GetUsage() string {
+	return "`prefix` - list prefixes\n" +
+		"`prefix add <prefix>` - add a custom prefix\n" +
+		"`prefix remove <prefix>` - remove a custom prefix\n" +
+		"`prefix set <prefix>...` - replace all custom prefixes"
+}
+
+func (d *DefaultPrefixCommand) GetGroup() string {
+	return GroupGuildConfig
+}
+
+func (d *DefaultPrefixCommand) GetDomain() string {
+	return "rs.guild.config.prefix"
+}
+
+func (d *DefaultPrefixCommand) GetSubPermissionRules() []SubPermission {
+	return nil
+}
+
+func (d *DefaultPrefixCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (d *DefaultPrefixCommand) GetSubCommands() []Command {
+	return []Command{
+		&prefixCommand{invokers: []string{"add"}, description: "add a custom prefix", update: addPrefix},
+		&prefixCommand{invokers: []string{"remove", "rm"}, description: "remove a custom prefix", update: removePrefix},
+		&prefixCommand{invokers: []string{"set"}, description: "replace all custom prefixes", update: setPrefixes},
+	}
+}
+
+func (d *DefaultPrefixCommand) Exec(ctx Context) error {
+	rr, _ := ctx.GetObject(ObjectMapKeyRouter).(Router)
+	prefixes, err := rr.GetConfig().PrefixProvider.GetPrefixes(ctx.GetGuild().ID)
+	if err != nil {
+		return err
+	}
+	return respondPrefixes(ctx, rr, prefixes)
+}
+
+type prefixCommand struct {
+	invokers    []string
+	description string
+	update      func(prefixes, args []string) []string
+}
+
+func (p *prefixCommand) GetInvokers() []string {
+	return p.invokers
+}
+
+func (p *prefixCommand) GetDescription() string {
+	return p.description
+}
+
+func (p *prefixCommand) GetUsage() string {
+	return ""
+}
+
+func (p *prefixCommand) GetGroup() string {
+	return GroupGuildConfig
+}
+
+func (p *prefixCommand) GetDomain() string {
+	return "rs.guild.config.prefix." + p.invokers[0]
+}
+
+func (p *prefixCommand) GetSubPermissionRules() []SubPermission {
+	return nil
+}
+
+func (p *prefixCommand) IsExecutableInDM() bool {
+	return false
+}
+
+func (p *prefixCommand) GetSchema() *Schema {
+	return NewSchema().String("prefix", "command prefix, e.g. `r!`").Rest()
+}
+
+func (p *prefixCommand) Exec(ctx Context) error {
+	if ok, err := RequireGuildManager(ctx); !ok {
+		return err
+	}
+
+	rr, _ := ctx.GetObject(ObjectMapKeyRouter).(Router)
+	provider, guildID := rr.GetConfig().PrefixProvider, ctx.GetGuild().ID
+
+	prefixes, err := provider.GetPrefixes(guildID)
+	if err != nil {
+		return err
+	}
+	prefixes = p.update(prefixes, argsToStrings(ctx.GetArguments().Args()))
+	if len(prefixes) > MaxGuildPrefixes {
+		_, err = ctx.RespondEmbedError(ctx.T("rosetta.prefix.limit", MaxGuildPrefixes), ErrInvalidArgument)
+		return err
+	}
+	if err = provider.SetPrefixes(guildID, prefixes); err != nil {
+		return err
+	}
+	return respondPrefixes(ctx, rr, prefixes)
+}
+
+func addPrefix(prefixes, args []string) []string {
+	for _, a := range args {
+		if !arrayContains(prefixes, a, false) {
+			prefixes = append(prefixes, a)
+		}
+	}
+	return prefixes
+}
+
+func removePrefix(prefixes, args []string) []string {
+	res := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		if !arrayContains(args, p, false) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+func setPrefixes(_, args []string) []string {
+	return addPrefix(nil, args)
+}
+
+func respondPrefixes(ctx Context, rr Router, prefixes []string) error {
+	custom := ctx.T("rosetta.prefix.no_custom")
+	if len(prefixes) > 0 {
+		custom = "`" + strings.Join(prefixes, "` `") + "`"
+	}
+	mention := "`@mention`"
+	if s := ctx.GetSession(); s != nil && s.State != nil && s.State.User != nil {
+		mention = s.State.User.Mention()
+	}
+	_, err := ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title: ctx.T("rosetta.prefix.title"),
+		Color: EmbedColorDefault,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: ctx.T("rosetta.prefix.default"), Value: fmt.Sprintf("`%s` %s", rr.GetConfig().GeneralPrefix, mention)},
+			{Name: ctx.T("rosetta.prefix.custom"), Value: custom},
+		},
+	})
+	return err
+}
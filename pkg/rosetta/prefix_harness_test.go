@@ -0,0 +1,34 @@
+package rosetta_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+func TestDefaultPrefixCommand_Update(t *testing.T) {
+	cfg := rosetta.NewDefaultConfig()
+	cfg.OnError = nil
+	cfg.UseDefaultPrefixCommand = true
+	h, user := makeHarness(t, cfg)
+	manager := addManager(h)
+
+	// only members managing the guild can change its prefixes.
+	h.Send(user, "10", "r!prefix add Ⱥ!")
+	h.ExpectNoErrors()
+	assert.Contains(t, h.Last().Embed().Description, "Manage Server")
+	h.Reset()
+	h.Send(user, "10", "ⱥ!ping")
+	h.ExpectNoResponse()
+
+	h.Send(manager, "10", "r!prefix add Ⱥ!")
+	h.ExpectNoErrors()
+	h.ExpectEmbedTitle("Prefixes")
+
+	// prefixes are matched ignoring case, even if folding changes their length.
+	h.Reset()
+	h.Send(user, "10", "ⱥ!ping a")
+	h.ExpectText("pong a")
+}
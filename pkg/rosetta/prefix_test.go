@@ -0,0 +1,61 @@
+package rosetta
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePrefix(t *testing.T) {
+	prefixes := []string{"<@123>", "<@!123>", "r!", "", "r!!", "iris "}
+	tests := []struct {
+		name     string
+		content  string
+		prefix   string
+		trimmed  string
+		expected bool
+	}{
+		{"general", "r!help", "r!", "help", true},
+		{"longest match", "r!!help", "r!!", "help", true},
+		{"ignore case", "R!!Help", "r!!", "Help", true},
+		{"mention", "<@123> help me", "<@123>", "help me", true},
+		{"nick mention", "<@!123>help", "<@!123>", "help", true},
+		{"word prefix", "iris ping", "iris ", "ping", true},
+		{"no prefix", "help", "", "", false},
+		{"other mention", "<@456> help", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, trimmed, ok := resolvePrefix(tt.content, prefixes, true)
+			assert.Equal(t, tt.expected, ok)
+			assert.Equal(t, tt.prefix, prefix)
+			assert.Equal(t, tt.trimmed, trimmed)
+		})
+	}
+}
+
+func TestRouter_GetPrefixes(t *testing.T) {
+	cfg := makeTestConfig()
+	r, _ := NewRouter(cfg).(*router)
+	require.NoError(t, cfg.PrefixProvider.SetPrefixes("1", []string{"?", "iris "}))
+
+	s := &discordgo.Session{State: discordgo.NewState()}
+	s.State.User = &discordgo.User{ID: "123"}
+
+	prefixes, err := r.getPrefixes(s, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"<@123>", "<@!123>", "!", "test!", "?", "iris "}, prefixes)
+
+	prefixes, err = r.getPrefixes(nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"!"}, prefixes)
+}
+
+func TestPrefixUpdates(t *testing.T) {
+	prefixes := addPrefix([]string{"r!"}, []string{"?", "r!", "!"})
+	assert.Equal(t, []string{"r!", "?", "!"}, prefixes)
+	assert.Equal(t, []string{"r!", "!"}, removePrefix(prefixes, []string{"?", "abc"}))
+	assert.Equal(t, []string{"a", "b"}, setPrefixes(prefixes, []string{"a", "b", "a"}))
+}
@@ -3,7 +3,6 @@
 package ratelimit
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -14,9 +13,13 @@ import (
 // RateLimiter implements a managers of rate limiters.
 // This can also be parsed a custom Manager instance if you want to handle limiters differently.
 type RateLimiter struct {
-	m Manager
+	m         Manager
+	onLimited LimitedFunc
 }
 
+// LimitedFunc is called when a command invocation is rejected by the rate limiter.
+type LimitedFunc func(cmd rosetta.Command, ctx rosetta.Context)
+
 // New returns a new instance of Rate Limiter.
 func New(m ...Manager) *RateLimiter {
 	var man Manager
@@ -25,7 +28,13 @@ func New(m ...Manager) *RateLimiter {
 	} else {
 		man = newInternalManager(10 * time.Minute)
 	}
-	return &RateLimiter{man}
+	return &RateLimiter{m: man}
+}
+
+// OnLimited sets a func which is called when an invocation is rejected, e.g. to record metrics.
+func (r *RateLimiter) OnLimited(f LimitedFunc) *RateLimiter {
+	r.onLimited = f
+	return r
 }
 
 func (r *RateLimiter) Handle(cmd rosetta.Command, ctx rosetta.Context, layer rosetta.MiddlewareLayer) (bool, error) {
@@ -46,7 +55,10 @@ func (r *RateLimiter) Handle(cmd rosetta.Command, ctx rosetta.Context, layer ros
 
 	limiter := r.m.GetBucket(cmd, ctx.GetUser().ID, gid)
 	if k, next := limiter.Take(); !k {
-		_, err := ctx.RespondEmbedError(fmt.Sprintf("You are being rate limited.\nWait %s before using this command again.", next.String()), rosetta.ErrRateLimited)
+		if r.onLimited != nil {
+			r.onLimited(cmd, ctx)
+		}
+		_, err := ctx.RespondEmbedError(ctx.T("rosetta.ratelimit.limited", next.String()), rosetta.ErrRateLimited)
 		return false, err
 	}
 	return true, nil
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/rosettatest"
 )
 
 func testLoop(t *testing.T, m ...Manager) *RateLimiter {
@@ -17,11 +18,7 @@ func testLoop(t *testing.T, m ...Manager) *RateLimiter {
 
 	rl := New(m...)
 	cmd := &TestCmd{false, false, false}
-	ctx := &TestContext{
-		chanType: discordgo.ChannelTypeGuildText,
-		gid:      "gid",
-		uid:      "uid",
-	}
+	ctx := makeTestContext(discordgo.ChannelTypeGuildText)
 
 	pass := func() {
 		ok, err := rl.Handle(cmd, ctx, rl.GetLayer())
@@ -32,6 +29,7 @@ func testLoop(t *testing.T, m ...Manager) *RateLimiter {
 		ok, err := rl.Handle(cmd, ctx, rl.GetLayer())
 		assert.Nil(t, err)
 		assert.False(t, ok, "rate limiter passed unexpectedly")
+		assert.True(t, ctx.HasResponse("rate limited"))
 	}
 
 	for i := 0; i < cmd.GetLimiterBurst(); i++ {
@@ -57,14 +55,23 @@ func TestRateLimiter_Handle(t *testing.T) {
 		rl := testLoop(t, cm, newInternalManager(30*time.Minute))
 		assert.Equal(t, rl.m, cm)
 	})
+	t.Run("calls on limited", func(t *testing.T) {
+		limited := 0
+		cmd := &TestCmd{false, false, false}
+		rl := New().OnLimited(func(c rosetta.Command, _ rosetta.Context) {
+			assert.Equal(t, cmd, c)
+			limited++
+		})
+		ctx := makeTestContext(discordgo.ChannelTypeGuildText)
+		for i := 0; i <= cmd.GetLimiterBurst(); i++ {
+			_, _ = rl.Handle(cmd, ctx, rl.GetLayer())
+		}
+		assert.Equal(t, 1, limited)
+	})
 	t.Run("handled a non-implemented commands", func(t *testing.T) {
 		rl := New()
 		cmd := &TestCmdNotImplemented{}
-		ctx := &TestContext{
-			chanType: discordgo.ChannelTypeGuildText,
-			gid:      "gid",
-			uid:      "uid",
-		}
+		ctx := makeTestContext(discordgo.ChannelTypeGuildText)
 		ok, err := rl.Handle(cmd, ctx, rl.GetLayer())
 		assert.True(t, ok)
 		assert.Nil(t, err)
@@ -72,11 +79,7 @@ func TestRateLimiter_Handle(t *testing.T) {
 	t.Run("global dms", func(t *testing.T) {
 		rl := New()
 		cmd := &TestCmd{false, false, true}
-		ctx := &TestContext{
-			chanType: discordgo.ChannelTypeDM,
-			gid:      "gid",
-			uid:      "uid",
-		}
+		ctx := makeTestContext(discordgo.ChannelTypeDM)
 
 		expected := fmt.Sprintf("%s:%s:%s", cmd.GetDomain(), ctx.GetUser().ID, "__global__")
 		_, _ = rl.Handle(cmd, ctx, rl.GetLayer())
@@ -86,11 +89,7 @@ func TestRateLimiter_Handle(t *testing.T) {
 	t.Run("in the dms", func(t *testing.T) {
 		rl := New()
 		cmd := &TestCmd{false, false, false}
-		ctx := &TestContext{
-			chanType: discordgo.ChannelTypeDM,
-			gid:      "gid",
-			uid:      "uid",
-		}
+		ctx := makeTestContext(discordgo.ChannelTypeDM)
 
 		expected := fmt.Sprintf("%s:%s:%s", cmd.GetDomain(), ctx.GetUser().ID, "__dm__")
 		_, _ = rl.Handle(cmd, ctx, rl.GetLayer())
@@ -99,72 +98,8 @@ func TestRateLimiter_Handle(t *testing.T) {
 	})
 }
 
-type TestContext struct {
-	chanType discordgo.ChannelType
-	gid      string
-	uid      string
-}
-
-func (tc *TestContext) GetObject(key string) (value interface{}) {
-	return nil
-}
-
-func (tc *TestContext) SetObject(key string, value interface{}) {}
-
-func (tc *TestContext) GetSession() *discordgo.Session {
-	return nil
-}
-
-func (tc *TestContext) GetArguments() *rosetta.Arguments {
-	return nil
-}
-
-func (tc *TestContext) GetChannel() *discordgo.Channel {
-	return &discordgo.Channel{Type: tc.chanType}
-}
-
-func (tc *TestContext) GetMessage() *discordgo.Message {
-	return nil
-}
-
-func (tc *TestContext) GetGuild() *discordgo.Guild {
-	return &discordgo.Guild{ID: tc.gid}
-}
-
-func (tc *TestContext) GetUser() *discordgo.User {
-	return &discordgo.User{ID: tc.uid}
-}
-
-func (tc *TestContext) GetMember() *discordgo.Member {
-	return nil
-}
-
-func (tc *TestContext) IsDM() bool {
-	return false
-}
-
-func (tc *TestContext) IsEdit() bool {
-	return false
-}
-
-func (tc *TestContext) RespondText(content string) (*discordgo.Message, error) {
-	return nil, nil
-}
-
-func (tc *TestContext) RespondEmbed(embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
-	return nil, nil
-}
-
-func (tc *TestContext) RespondEmbedError(title string, err error) (*discordgo.Message, error) {
-	return nil, nil
-}
-
-func (tc *TestContext) RespondTextEmbed(content string, embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
-	return nil, nil
-}
-
-func (tc *TestContext) RespondTextEmbedError(title, content string, err error) (*discordgo.Message, error) {
-	return nil, nil
+func makeTestContext(chanType discordgo.ChannelType) *rosettatest.Context {
+	return rosettatest.NewContext(&discordgo.User{ID: "uid"}, &discordgo.Channel{ID: "cid", GuildID: "gid", Type: chanType}, "")
 }
 
 type TestCmdNotImplemented struct{}
@@ -0,0 +1,131 @@
+package rosetta
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// trackedReply is a message our bot sent in response to a command.
+type trackedReply struct {
+	ID    string
+	Embed bool
+}
+
+type replyEntry struct {
+	channelID string
+	replies   []trackedReply
+	expires   time.Time
+}
+
+// replyTracker remembers replies of invoking messages for a limited time, so they can be
+// edited when the invoking message is edited and deleted when it is deleted.
+type replyTracker struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*replyEntry
+	nextSweep time.Time
+}
+
+func newReplyTracker(ttl time.Duration) *replyTracker {
+	return &replyTracker{ttl: ttl, entries: make(map[string]*replyEntry)}
+}
+
+// set replaces the replies of given invoking message. Empty replies remove the entry.
+func (t *replyTracker) set(msgID, channelID string, replies []trackedReply) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if now.After(t.nextSweep) {
+		for id, e := range t.entries {
+			if now.After(e.expires) {
+				delete(t.entries, id)
+			}
+		}
+		t.nextSweep = now.Add(t.ttl)
+	}
+
+	if len(replies) == 0 {
+		delete(t.entries, msgID)
+		return
+	}
+	t.entries[msgID] = &replyEntry{channelID: channelID, replies: replies, expires: now.Add(t.ttl)}
+}
+
+// get returns the replies of given invoking message, nil if there are none or they expired.
+func (t *replyTracker) get(msgID string) []trackedReply {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries[msgID]
+	if !ok || time.Now().After(e.expires) {
+		return nil
+	}
+	return append([]trackedReply{}, e.replies...)
+}
+
+// remove forgets the replies of given invoking message and returns them.
+func (t *replyTracker) remove(msgID string) (channelID string, replies []trackedReply) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries[msgID]
+	if !ok {
+		return "", nil
+	}
+	delete(t.entries, msgID)
+	if time.Now().After(e.expires) {
+		return "", nil
+	}
+	return e.channelID, e.replies
+}
+
+// deleteReplies deletes the replies of given invoking message.
+func (r *router) deleteReplies(s *discordgo.Session, msgID string) {
+	if r.replies == nil {
+		return
+	}
+	channelID, replies := r.replies.remove(msgID)
+	for _, reply := range replies {
+		_ = s.ChannelMessageDelete(channelID, reply.ID)
+	}
+}
+
+// finishReplies deletes previous replies which weren't reused by an edited command and
+// remembers the replies of this run.
+func (r *router) finishReplies(ctx *context) {
+	if r.replies == nil || ctx.interaction != nil {
+		return
+	}
+	for _, reply := range ctx.prevReplies {
+		_ = ctx.session.ChannelMessageDelete(ctx.message.ChannelID, reply.ID)
+	}
+	if len(ctx.replies) > 0 || ctx.isEdit {
+		r.replies.set(ctx.message.ID, ctx.message.ChannelID, ctx.replies)
+	}
+}
+
+// reuseReply pops the next reply of the previous run which can be edited to the given
+// kind. A reply of the other kind is deleted, since an embed can't be removed by editing.
+func (c *context) reuseReply(embed bool) (string, bool) {
+	if len(c.prevReplies) == 0 {
+		return "", false
+	}
+	reply := c.prevReplies[0]
+	c.prevReplies = c.prevReplies[1:]
+	if reply.Embed != embed {
+		_ = c.session.ChannelMessageDelete(c.channel.ID, reply.ID)
+		return "", false
+	}
+	return reply.ID, true
+}
+
+// trackReply remembers given sent or edited message as reply of this context.
+func (c *context) trackReply(msg *discordgo.Message, embed bool, err error) (*discordgo.Message, error) {
+	if rr, ok := c.router.(*router); ok && err == nil && msg != nil && rr.replies != nil {
+		c.replies = append(c.replies, trackedReply{ID: msg.ID, Embed: embed})
+	}
+	return msg, err
+}
@@ -0,0 +1,22 @@
+package rosetta_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_EditRemovesCommand(t *testing.T) {
+	h, user := makeHarness(t, nil)
+
+	msg := h.Send(user, "10", "r!ping a")
+	h.ExpectText("pong a")
+	replies := h.Sent()
+	require.Len(t, replies, 1)
+
+	// replies are deleted once the invoking message isn't a command anymore.
+	h.Reset()
+	h.Edit(msg, "ping a")
+	h.ExpectNoResponse()
+	h.ExpectDeleted(replies[0].MessageID)
+}
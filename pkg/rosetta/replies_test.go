@@ -0,0 +1,69 @@
+package rosetta
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplyTracker(t *testing.T) {
+	tr := newReplyTracker(50 * time.Millisecond)
+	replies := []trackedReply{{ID: "10"}, {ID: "11", Embed: true}}
+	tr.set("1", "100", replies)
+	tr.set("2", "100", []trackedReply{{ID: "20"}})
+
+	assert.Equal(t, replies, tr.get("1"))
+	assert.Nil(t, tr.get("3"))
+
+	channelID, removed := tr.remove("2")
+	assert.Equal(t, "100", channelID)
+	assert.Equal(t, []trackedReply{{ID: "20"}}, removed)
+	assert.Nil(t, tr.get("2"))
+
+	tr.set("1", "100", nil)
+	assert.Nil(t, tr.get("1"))
+
+	tr.set("1", "100", replies)
+	time.Sleep(60 * time.Millisecond)
+	assert.Nil(t, tr.get("1"))
+	_, removed = tr.remove("1")
+	assert.Nil(t, removed)
+
+	// expired entries are swept on set.
+	tr.set("4", "100", replies)
+	time.Sleep(60 * time.Millisecond)
+	tr.set("5", "100", replies)
+	assert.Len(t, tr.entries, 1)
+}
+
+func TestContext_Replies(t *testing.T) {
+	cfg := makeTestConfig()
+	cfg.ReplyTTL = time.Minute
+	r, _ := NewRouter(cfg).(*router)
+
+	ctx := r.acquireContext(nil)
+	ctx.message = &discordgo.Message{ID: "1", ChannelID: "100"}
+	ctx.isEdit = true
+	ctx.prevReplies = []trackedReply{{ID: "10", Embed: true}}
+
+	id, ok := ctx.reuseReply(true)
+	assert.True(t, ok)
+	assert.Equal(t, "10", id)
+	_, ok = ctx.reuseReply(true)
+	assert.False(t, ok)
+
+	_, _ = ctx.trackReply(&discordgo.Message{ID: "10"}, true, nil)
+	_, _ = ctx.trackReply(nil, false, assert.AnError)
+	r.finishReplies(ctx)
+	assert.Equal(t, []trackedReply{{ID: "10", Embed: true}}, r.replies.get("1"))
+
+	// an edit without replies forgets previous ones.
+	r.releaseContext(ctx)
+	ctx = r.acquireContext(nil)
+	ctx.message = &discordgo.Message{ID: "1", ChannelID: "100"}
+	ctx.isEdit = true
+	r.finishReplies(ctx)
+	assert.Nil(t, r.replies.get("1"))
+}
@@ -0,0 +1,250 @@
+package rosetta
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultAllowedMentions returns the mentions responses can ping if not configured otherwise.
+// Only users are allowed, so echoed user input can't ping @everyone, @here or roles.
+func DefaultAllowedMentions() *discordgo.MessageAllowedMentions {
+	return &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeUsers}}
+}
+
+// Response builds a message sent in response to a command. It is obtained by Context.Respond
+// and sent by Send, e.g.
+//
+//	ctx.Respond().Reply().Text("here you go").File("report.csv", r).Send()
+type Response struct {
+	send       func(r *Response) (*discordgo.Message, error)
+	content    string
+	embed      *discordgo.MessageEmbed
+	files      []*discordgo.File
+	mentions   *discordgo.MessageAllowedMentions
+	reply      bool
+	dm         bool
+	onFallback func()
+}
+
+// NewResponse returns an empty Response which is sent by given func. This can be used
+// to implement Context.Respond.
+func NewResponse(send func(r *Response) (*discordgo.Message, error)) *Response {
+	return &Response{send: send}
+}
+
+// Text sets the text content of the response.
+func (r *Response) Text(content string) *Response {
+	r.content = content
+	return r
+}
+
+// Embed sets the embed of the response.
+func (r *Response) Embed(embed *discordgo.MessageEmbed) *Response {
+	r.embed = embed
+	return r
+}
+
+// File attaches a file read from given reader. Its content type is derived from the extension of name.
+func (r *Response) File(name string, reader io.Reader) *Response {
+	r.files = append(r.files, &discordgo.File{Name: name, ContentType: mime.TypeByExtension(path.Ext(name)), Reader: reader})
+	return r
+}
+
+// Reply lets the response reference the invoking message.
+func (r *Response) Reply() *Response {
+	r.reply = true
+	return r
+}
+
+// DM sends the response to the DMs of the invoking user. If the user doesn't accept DMs, it
+// is sent to the invoking channel instead after calling the func passed to OnDMFallback.
+func (r *Response) DM() *Response {
+	r.dm = true
+	return r
+}
+
+// OnDMFallback sets a func called before a DM response is sent to the invoking channel
+// instead, e.g. to tell the user why.
+func (r *Response) OnDMFallback(fn func()) *Response {
+	r.onFallback = fn
+	return r
+}
+
+// AllowMentions overrides the mentions this response can ping, see Config.AllowedMentions.
+func (r *Response) AllowMentions(mentions *discordgo.MessageAllowedMentions) *Response {
+	r.mentions = mentions
+	return r
+}
+
+// Send sends the response.
+func (r *Response) Send() (*discordgo.Message, error) {
+	return r.send(r)
+}
+
+// MessageSend returns the content, embed, files and allowed mentions of the response. Allowed
+// mentions are nil if not overridden by AllowMentions.
+func (r *Response) MessageSend() *discordgo.MessageSend {
+	return &discordgo.MessageSend{
+		Content:         r.content,
+		Embed:           r.embed,
+		Files:           r.files,
+		AllowedMentions: r.mentions,
+	}
+}
+
+// IsReply returns true if the response references the invoking message.
+func (r *Response) IsReply() bool {
+	return r.reply
+}
+
+// IsDM returns true if the response is sent to the DMs of the invoking user.
+func (r *Response) IsDM() bool {
+	return r.dm
+}
+
+// Fallback calls the func passed to OnDMFallback, if any.
+func (r *Response) Fallback() {
+	if r.onFallback != nil {
+		r.onFallback()
+	}
+}
+
+func (c *context) Respond() *Response {
+	return NewResponse(c.sendResponse)
+}
+
+// sendResponse sends given response to the DMs of the invoking user or the invoking channel.
+// Responses exceeding the limits of Discord are split into multiple messages, the first
+// one is returned.
+func (c *context) sendResponse(r *Response) (*discordgo.Message, error) {
+	if r.IsDM() && !c.isDM && c.pipeOut == nil {
+		msg, err := c.sendDM(r)
+		if !IsDMBlocked(err) {
+			return msg, err
+		}
+		r.Fallback()
+	}
+
+	data := c.messageSend(r)
+	if c.pipeOut != nil {
+		return c.capture(pipeText(data.Content, data.Embed)), nil
+	}
+	if r.IsReply() && c.interaction == nil && c.message != nil && c.message.ID != "" {
+		data.Reference = c.message.Reference()
+	}
+
+	parts, err := SplitMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	var first *discordgo.Message
+	for _, p := range parts {
+		msg, err := c.sendPart(p)
+		if err != nil {
+			return first, err
+		}
+		if first == nil {
+			first = msg
+		}
+	}
+	return first, nil
+}
+
+// sendPart sends a single message within the limits of Discord to the invoking channel,
+// editing a previous reply if the invoking message was edited.
+func (c *context) sendPart(data *discordgo.MessageSend) (*discordgo.Message, error) {
+	embed := data.Embed != nil
+	if c.interaction != nil && len(data.Files) == 0 {
+		resp := &InteractionResponseData{Content: data.Content, AllowedMentions: data.AllowedMentions}
+		if embed {
+			resp.Embeds = []*discordgo.MessageEmbed{data.Embed}
+		}
+		return c.respondInteraction(resp)
+	}
+
+	if id, ok := c.reuseReply(embed); ok {
+		// attachments can't be edited, so the reply is replaced.
+		if len(data.Files) > 0 {
+			_ = c.session.ChannelMessageDelete(c.channel.ID, id)
+		} else {
+			edit := discordgo.NewMessageEdit(c.channel.ID, id)
+			edit.AllowedMentions = data.AllowedMentions
+			if data.Content != "" || !embed {
+				edit.SetContent(data.Content)
+			}
+			if embed {
+				edit.SetEmbed(data.Embed)
+			}
+			if msg, err := c.session.ChannelMessageEditComplex(edit); err == nil {
+				return c.trackReply(msg, embed, nil)
+			}
+		}
+	}
+	msg, err := c.session.ChannelMessageSendComplex(c.channel.ID, data)
+	return c.trackReply(msg, embed, err)
+}
+
+// sendDM sends given response to the DMs of the invoking user.
+func (c *context) sendDM(r *Response) (*discordgo.Message, error) {
+	parts, err := SplitMessage(c.messageSend(r))
+	if err != nil {
+		return nil, err
+	}
+	channel, err := c.session.UserChannelCreate(c.GetUser().ID)
+	if err != nil {
+		return nil, err
+	}
+	var first *discordgo.Message
+	for _, p := range parts {
+		msg, err := c.session.ChannelMessageSendComplex(channel.ID, p)
+		if err != nil {
+			return first, err
+		}
+		if first == nil {
+			first = msg
+		}
+	}
+	return first, nil
+}
+
+// messageSend returns the message of given response with allowed mentions defaulted.
+func (c *context) messageSend(r *Response) *discordgo.MessageSend {
+	data := r.MessageSend()
+	if data.AllowedMentions == nil {
+		data.AllowedMentions = c.allowedMentions()
+	}
+	return data
+}
+
+// allowedMentions returns the mentions responses can ping by default.
+func (c *context) allowedMentions() *discordgo.MessageAllowedMentions {
+	if c.router != nil {
+		if m := c.router.GetConfig().AllowedMentions; m != nil {
+			return m
+		}
+	}
+	return DefaultAllowedMentions()
+}
+
+// IsDMBlocked returns true if given error was returned because a user doesn't accept DMs.
+func IsDMBlocked(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeCannotSendMessagesToThisUser
+}
+
+// pipeText returns the text of a response piped into the next command.
+func pipeText(content string, embed *discordgo.MessageEmbed) string {
+	if embed == nil {
+		return content
+	}
+	text := strings.TrimSpace(embed.Title + "\n" + embed.Description)
+	if content == "" {
+		return text
+	}
+	return content + "\n" + text
+}
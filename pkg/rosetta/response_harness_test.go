@@ -0,0 +1,85 @@
+package rosetta_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta/rosettatest"
+)
+
+func TestResponse_Send(t *testing.T) {
+	h, user := makeHarness(t, nil)
+	cmd := &testCmd{invoke: "respond"}
+	h.Router.Register(cmd)
+	run := func(exec func(ctx rosetta.Context) error) []rosettatest.Action {
+		h.Reset()
+		cmd.exec = exec
+		msg := h.Send(user, "10", "r!respond")
+		h.ExpectNoErrors()
+		sent := h.Sent()
+		for i := range sent {
+			if sent[i].Reference != nil {
+				assert.Equal(t, msg.ID, sent[i].Reference.MessageID)
+			}
+		}
+		return sent
+	}
+
+	sent := run(func(ctx rosetta.Context) error {
+		_, err := ctx.Respond().Reply().Text("@everyone report").File("report.csv", strings.NewReader("a,b")).Send()
+		return err
+	})
+	require.Len(t, sent, 1)
+	assert.Equal(t, "10", sent[0].ChannelID)
+	assert.Equal(t, "@everyone report", sent[0].Content)
+	assert.Equal(t, []string{"report.csv"}, sent[0].Files)
+	require.NotNil(t, sent[0].Reference)
+	assert.Equal(t, rosetta.DefaultAllowedMentions(), sent[0].AllowedMentions)
+
+	sent = run(func(ctx rosetta.Context) error {
+		_, err := ctx.RespondTextEmbedError("oops", "failed", rosetta.ErrInvalidArgument)
+		return err
+	})
+	require.Len(t, sent, 1)
+	assert.Equal(t, "oops", sent[0].Content)
+	assert.Equal(t, "failed", sent[0].Embed().Title)
+	assert.Nil(t, sent[0].Reference)
+
+	sent = run(func(ctx rosetta.Context) error {
+		_, err := ctx.Respond().Text("<@&1>").AllowMentions(&discordgo.MessageAllowedMentions{Roles: []string{"1"}}).Send()
+		return err
+	})
+	require.Len(t, sent, 1)
+	assert.Equal(t, []string{"1"}, sent[0].AllowedMentions.Roles)
+
+	dm := func(ctx rosetta.Context) error {
+		_, err := ctx.Respond().DM().Text("secret").OnDMFallback(func() {
+			_, _ = ctx.RespondText("check your DMs")
+		}).Send()
+		return err
+	}
+	sent = run(dm)
+	require.Len(t, sent, 1)
+	assert.NotEqual(t, "10", sent[0].ChannelID)
+	assert.Equal(t, "secret", sent[0].Content)
+
+	// users who don't accept DMs get the response in the invoking channel.
+	h.REST.DisableDMs(user.ID)
+	sent = run(dm)
+	require.Len(t, sent, 2)
+	assert.Equal(t, "check your DMs", sent[0].Content)
+	assert.Equal(t, "10", sent[1].ChannelID)
+	assert.Equal(t, "secret", sent[1].Content)
+
+	h.Reset()
+	h.Send(user, "10", "r!help ping")
+	a := h.Last()
+	require.NotNil(t, a)
+	assert.Equal(t, "10", a.ChannelID)
+	assert.Contains(t, a.Embed().Footer.Text, "disabled `receiving DMs from server members`")
+}
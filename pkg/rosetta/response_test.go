@@ -0,0 +1,62 @@
+package rosetta
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponse(t *testing.T) {
+	var sent *Response
+	embed := &discordgo.MessageEmbed{Title: "title"}
+	fallback := false
+	msg, err := NewResponse(func(r *Response) (*discordgo.Message, error) {
+		sent = r
+		return &discordgo.Message{ID: "1"}, nil
+	}).Text("text").Embed(embed).File("report.csv", strings.NewReader("a,b")).Reply().DM().
+		OnDMFallback(func() { fallback = true }).Send()
+	require.NoError(t, err)
+	assert.Equal(t, "1", msg.ID)
+
+	data := sent.MessageSend()
+	assert.Equal(t, "text", data.Content)
+	assert.Equal(t, embed, data.Embed)
+	require.Len(t, data.Files, 1)
+	assert.Equal(t, "report.csv", data.Files[0].Name)
+	assert.Contains(t, data.Files[0].ContentType, "csv")
+	assert.Nil(t, data.AllowedMentions)
+	assert.True(t, sent.IsReply())
+	assert.True(t, sent.IsDM())
+
+	sent.Fallback()
+	assert.True(t, fallback)
+	NewResponse(nil).Fallback()
+
+	mentions := &discordgo.MessageAllowedMentions{}
+	assert.Equal(t, mentions, NewResponse(nil).AllowMentions(mentions).MessageSend().AllowedMentions)
+}
+
+func TestDefaultAllowedMentions(t *testing.T) {
+	m := DefaultAllowedMentions()
+	assert.Equal(t, []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeUsers}, m.Parse)
+	assert.NotSame(t, m, DefaultAllowedMentions())
+}
+
+func TestIsDMBlocked(t *testing.T) {
+	blocked := &discordgo.RESTError{Message: &discordgo.APIErrorMessage{Code: discordgo.ErrCodeCannotSendMessagesToThisUser}}
+	assert.True(t, IsDMBlocked(blocked))
+	assert.False(t, IsDMBlocked(&discordgo.RESTError{}))
+	assert.False(t, IsDMBlocked(errors.New("other")))
+	assert.False(t, IsDMBlocked(nil))
+}
+
+func TestPipeText(t *testing.T) {
+	embed := &discordgo.MessageEmbed{Title: "title", Description: "desc"}
+	assert.Equal(t, "text", pipeText("text", nil))
+	assert.Equal(t, "title\ndesc", pipeText("", embed))
+	assert.Equal(t, "text\ntitle\ndesc", pipeText("text", embed))
+}
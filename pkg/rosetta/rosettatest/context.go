@@ -0,0 +1,226 @@
+package rosettatest
+
+import (
+	gocontext "context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/i18n"
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+var defaultTranslations = rosetta.NewTranslations()
+
+// Context is a rosetta.Context with settable fields to unit test middlewares and
+// commands without a router. Responses are captured instead of being sent.
+type Context struct {
+	Session     *discordgo.Session
+	Ctx         gocontext.Context
+	Arguments   *rosetta.Arguments
+	Params      *rosetta.Params
+	Channel     *discordgo.Channel
+	Message     *discordgo.Message
+	Guild       *discordgo.Guild
+	Member      *discordgo.Member
+	Interaction *rosetta.Interaction
+	Edit        bool
+
+	// PipedInput is returned by GetPipedInput if not nil.
+	PipedInput *string
+
+	// Locale is returned by GetLocale, i18n.DefaultLocale if empty.
+	Locale string
+
+	// Translations are used by T, rosetta.NewTranslations if nil.
+	Translations *i18n.Bundle
+
+	// Texts and Embeds hold the captured responses.
+	Texts  []string
+	Embeds []*discordgo.MessageEmbed
+
+	// Responses holds all captured responses, e.g. to check for replies, files or DMs.
+	Responses []*rosetta.Response
+
+	objects sync.Map
+}
+
+// NewContext returns a Context of a message with given content sent by given user in given
+// channel. Guild is set if given channel belongs to one.
+func NewContext(user *discordgo.User, channel *discordgo.Channel, content string) *Context {
+	ctx := &Context{
+		Ctx:       gocontext.Background(),
+		Arguments: rosetta.ParseArguments(content),
+		Channel:   channel,
+		Message:   &discordgo.Message{ChannelID: channel.ID, GuildID: channel.GuildID, Content: content, Author: user},
+	}
+	if channel.GuildID != "" {
+		ctx.Guild = &discordgo.Guild{ID: channel.GuildID}
+	}
+	return ctx
+}
+
+func (c *Context) GetObject(key string) interface{} {
+	v, _ := c.objects.Load(key)
+	return v
+}
+
+func (c *Context) SetObject(key string, value interface{}) {
+	c.objects.Store(key, value)
+}
+
+func (c *Context) GetSession() *discordgo.Session {
+	return c.Session
+}
+
+func (c *Context) GetContext() gocontext.Context {
+	if c.Ctx == nil {
+		return gocontext.Background()
+	}
+	return c.Ctx
+}
+
+func (c *Context) GetArguments() *rosetta.Arguments {
+	return c.Arguments
+}
+
+func (c *Context) GetParams() *rosetta.Params {
+	return c.Params
+}
+
+func (c *Context) GetChannel() *discordgo.Channel {
+	return c.Channel
+}
+
+func (c *Context) GetMessage() *discordgo.Message {
+	return c.Message
+}
+
+func (c *Context) GetGuild() *discordgo.Guild {
+	return c.Guild
+}
+
+func (c *Context) GetUser() *discordgo.User {
+	if c.Message == nil {
+		return nil
+	}
+	return c.Message.Author
+}
+
+func (c *Context) GetMember() *discordgo.Member {
+	return c.Member
+}
+
+func (c *Context) IsDM() bool {
+	return c.Channel != nil && (c.Channel.Type == discordgo.ChannelTypeDM || c.Channel.Type == discordgo.ChannelTypeGroupDM)
+}
+
+func (c *Context) IsEdit() bool {
+	return c.Edit
+}
+
+func (c *Context) GetInteraction() *rosetta.Interaction {
+	return c.Interaction
+}
+
+func (c *Context) GetPipedInput() (string, bool) {
+	if c.PipedInput == nil {
+		return "", false
+	}
+	return *c.PipedInput, true
+}
+
+func (c *Context) GetLocale() string {
+	if c.Locale == "" {
+		return i18n.DefaultLocale
+	}
+	return c.Locale
+}
+
+func (c *Context) T(key string, args ...interface{}) string {
+	if c.Translations != nil {
+		return c.Translations.T(c.GetLocale(), key, args...)
+	}
+	return defaultTranslations.T(c.GetLocale(), key, args...)
+}
+
+func (c *Context) RespondText(content string) (*discordgo.Message, error) {
+	return c.Respond().Text(content).Send()
+}
+
+func (c *Context) RespondEmbed(embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
+	return c.Respond().Embed(embed).Send()
+}
+
+func (c *Context) RespondTextEmbed(content string, embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
+	return c.Respond().Text(content).Embed(embed).Send()
+}
+
+func (c *Context) RespondEmbedError(title string, err error) (*discordgo.Message, error) {
+	return c.RespondEmbed(errorEmbed(title, err))
+}
+
+func (c *Context) RespondTextEmbedError(content, title string, err error) (*discordgo.Message, error) {
+	return c.RespondTextEmbed(content, errorEmbed(title, err))
+}
+
+func (c *Context) Respond() *rosetta.Response {
+	return rosetta.NewResponse(c.capture)
+}
+
+// capture records given response instead of sending it. Like a real context, a LimitError
+// is returned if the response can't be split into messages within the limits of Discord.
+func (c *Context) capture(r *rosetta.Response) (*discordgo.Message, error) {
+	data := r.MessageSend()
+	if _, err := rosetta.SplitMessage(data); err != nil {
+		return nil, err
+	}
+	c.Responses = append(c.Responses, r)
+	if data.Content != "" || data.Embed == nil {
+		c.Texts = append(c.Texts, data.Content)
+	}
+	msg := c.response(data.Content)
+	if data.Embed != nil {
+		c.Embeds = append(c.Embeds, data.Embed)
+		msg.Embeds = []*discordgo.MessageEmbed{data.Embed}
+	}
+	return msg, nil
+}
+
+func errorEmbed(title string, err error) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{Title: title, Description: fmt.Sprintf("*%s*", err.Error()), Color: rosetta.EmbedColorError}
+}
+
+// EmbedTitles returns titles of all captured embeds.
+func (c *Context) EmbedTitles() []string {
+	titles := make([]string, 0, len(c.Embeds))
+	for _, e := range c.Embeds {
+		titles = append(titles, e.Title)
+	}
+	return titles
+}
+
+// HasResponse returns true if a text or embed response containing given text was captured.
+func (c *Context) HasResponse(text string) bool {
+	for _, t := range c.Texts {
+		if strings.Contains(t, text) {
+			return true
+		}
+	}
+	for _, e := range c.Embeds {
+		if strings.Contains(e.Title, text) || strings.Contains(e.Description, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Context) response(content string) *discordgo.Message {
+	msg := &discordgo.Message{Content: content}
+	if c.Channel != nil {
+		msg.ChannelID = c.Channel.ID
+	}
+	return msg
+}
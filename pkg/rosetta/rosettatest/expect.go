@@ -0,0 +1,85 @@
+package rosettatest
+
+import (
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ExpectEmbedTitle asserts that an embed with given title was sent and returns its action.
+func (h *Harness) ExpectEmbedTitle(title string) *Action {
+	h.T.Helper()
+	titles := make([]string, 0)
+	for _, a := range h.Sent() {
+		for _, e := range a.Embeds {
+			if e.Title == title {
+				return &a
+			}
+			titles = append(titles, e.Title)
+		}
+	}
+	assert.Failf(h.T, "embed not sent", "expected embed titled %q, sent embeds: %q", title, titles)
+	return nil
+}
+
+// ExpectText asserts that a message containing given text was sent and returns its action.
+func (h *Harness) ExpectText(text string) *Action {
+	h.T.Helper()
+	contents := make([]string, 0)
+	for _, a := range h.Sent() {
+		if strings.Contains(a.Content, text) {
+			return &a
+		}
+		contents = append(contents, a.Content)
+	}
+	assert.Failf(h.T, "text not sent", "expected message containing %q, sent messages: %q", text, contents)
+	return nil
+}
+
+// ExpectNoResponse asserts that no message was sent.
+func (h *Harness) ExpectNoResponse() bool {
+	h.T.Helper()
+	return assert.Empty(h.T, h.Sent(), "expected no response")
+}
+
+// ExpectReaction asserts that given emoji was added as reaction to the message with given ID.
+func (h *Harness) ExpectReaction(messageID, emoji string) bool {
+	h.T.Helper()
+	for _, a := range h.Filter(ActionReact) {
+		if a.MessageID == messageID && a.Emoji == emoji {
+			return true
+		}
+	}
+	return assert.Failf(h.T, "reaction not added", "expected reaction %q on message %s", emoji, messageID)
+}
+
+// ExpectDeleted asserts that the message with given ID was deleted.
+func (h *Harness) ExpectDeleted(messageID string) bool {
+	h.T.Helper()
+	for _, a := range h.Filter(ActionDelete) {
+		if a.MessageID == messageID {
+			return true
+		}
+	}
+	return assert.Failf(h.T, "message not deleted", "expected message %s to be deleted", messageID)
+}
+
+// ExpectError asserts that an error of given type was passed to OnError and returns it.
+func (h *Harness) ExpectError(errType ErrorType) error {
+	h.T.Helper()
+	types := make([]ErrorType, 0)
+	for _, e := range h.Errors() {
+		if e.Type == errType {
+			return e.Err
+		}
+		types = append(types, e.Type)
+	}
+	assert.Failf(h.T, "error not reported", "expected error type %d, reported types: %v", errType, types)
+	return nil
+}
+
+// ExpectNoErrors asserts that no error was passed to OnError.
+func (h *Harness) ExpectNoErrors() bool {
+	h.T.Helper()
+	return assert.Empty(h.T, h.Errors(), "expected no errors")
+}
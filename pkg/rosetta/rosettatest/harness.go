@@ -0,0 +1,192 @@
+// Package rosettatest provides an in-process harness to drive a rosetta.Router in tests.
+// It builds a discordgo.Session backed by a fake REST API and a local state, so events
+// can be triggered without a Discord connection and every response can be asserted.
+package rosettatest
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+// BotUserID is the ID of the user our fake session is logged in as.
+const BotUserID = "bot"
+
+// Harness drives a router with a fake session. Guilds, channels and members are
+// injected into its state, messages are triggered as events and outgoing requests
+// are captured for assertions.
+type Harness struct {
+	T       testing.TB
+	Router  rosetta.Router
+	Session *discordgo.Session
+	REST    *REST
+
+	mu     sync.Mutex
+	errors []Error
+}
+
+// Error is an error passed to OnError of the router.
+type Error struct {
+	Type ErrorType
+	Err  error
+}
+
+// ErrorType is an alias of rosetta.ErrorType to keep assertions short.
+type ErrorType = rosetta.ErrorType
+
+// New returns a harness with a router created from given config. If config is nil,
+// rosetta.NewDefaultConfig is used. Commands are executed inline, thus every
+// response is captured once a trigger method returns. Errors passed to OnError are
+// captured as well before the original OnError of given config is called.
+func New(t testing.TB, cfg *rosetta.Config) *Harness {
+	if cfg == nil {
+		cfg = rosetta.NewDefaultConfig()
+	}
+	cfg.Workers = 0
+
+	s, _ := discordgo.New()
+	s.State.User = &discordgo.User{ID: BotUserID, Username: "rosetta", Bot: true}
+	s.State.Ready = discordgo.Ready{User: s.State.User}
+	s.SyncEvents = true
+
+	h := &Harness{T: t, Session: s, REST: NewREST(s.State)}
+	s.Client = &http.Client{Transport: h.REST.Transport()}
+
+	onError := cfg.OnError
+	cfg.OnError = func(ctx rosetta.Context, errType rosetta.ErrorType, err error) {
+		h.mu.Lock()
+		h.errors = append(h.errors, Error{Type: errType, Err: err})
+		h.mu.Unlock()
+		if onError != nil {
+			onError(ctx, errType, err)
+		}
+	}
+	h.Router = rosetta.NewRouter(cfg)
+	return h
+}
+
+// AddGuild adds given guild with its channels, members and roles to our state.
+func (h *Harness) AddGuild(g *discordgo.Guild) *discordgo.Guild {
+	h.T.Helper()
+	if err := h.Session.State.GuildAdd(g); err != nil {
+		h.T.Fatal(err)
+	}
+	return g
+}
+
+// AddChannel adds given channel to our state. Guild channels require their guild to be added first.
+func (h *Harness) AddChannel(c *discordgo.Channel) *discordgo.Channel {
+	h.T.Helper()
+	if err := h.Session.State.ChannelAdd(c); err != nil {
+		h.T.Fatal(err)
+	}
+	return c
+}
+
+// AddMember adds given member to our state. Its guild has to be added first.
+func (h *Harness) AddMember(m *discordgo.Member) *discordgo.Member {
+	h.T.Helper()
+	if err := h.Session.State.MemberAdd(m); err != nil {
+		h.T.Fatal(err)
+	}
+	return m
+}
+
+// AddRole adds given role to the guild with given ID in our state.
+func (h *Harness) AddRole(guildID string, r *discordgo.Role) *discordgo.Role {
+	h.T.Helper()
+	if err := h.Session.State.RoleAdd(guildID, r); err != nil {
+		h.T.Fatal(err)
+	}
+	return r
+}
+
+// Trigger passes given event to the state and router as the gateway would.
+func (h *Harness) Trigger(event interface{}) {
+	_ = h.Session.State.OnInterface(h.Session, event)
+	h.Router.Trigger(h.Session, event)
+}
+
+// Send triggers a message with given content created by given user in given channel.
+// The guild and member of the message are taken from our state.
+func (h *Harness) Send(author *discordgo.User, channelID, content string) *discordgo.Message {
+	msg := &discordgo.Message{
+		ID:        h.REST.NewID(),
+		ChannelID: channelID,
+		Content:   content,
+		Author:    author,
+	}
+	if c, err := h.Session.State.Channel(channelID); err == nil && c.GuildID != "" {
+		msg.GuildID = c.GuildID
+		if m, err := h.Session.State.Member(c.GuildID, author.ID); err == nil {
+			msg.Member = m
+		}
+	}
+	h.Trigger(&discordgo.MessageCreate{Message: msg})
+	return msg
+}
+
+// Edit triggers an update of given message with new content.
+func (h *Harness) Edit(msg *discordgo.Message, content string) *discordgo.Message {
+	edited := *msg
+	edited.Content = content
+	h.Trigger(&discordgo.MessageUpdate{Message: &edited})
+	return &edited
+}
+
+// Delete triggers the deletion of given message.
+func (h *Harness) Delete(msg *discordgo.Message) {
+	h.Trigger(&discordgo.MessageDelete{Message: msg})
+}
+
+// Actions returns all captured requests.
+func (h *Harness) Actions() []Action {
+	return h.REST.Actions()
+}
+
+// Sent returns all captured sent messages, including interaction responses.
+func (h *Harness) Sent() []Action {
+	return h.Filter(ActionSend, ActionInteraction)
+}
+
+// Last returns the last captured action, nil if nothing was captured.
+func (h *Harness) Last() *Action {
+	actions := h.REST.Actions()
+	if len(actions) == 0 {
+		return nil
+	}
+	return &actions[len(actions)-1]
+}
+
+// Errors returns all errors passed to OnError.
+func (h *Harness) Errors() []Error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Error{}, h.errors...)
+}
+
+// Reset forgets all captured actions and errors.
+func (h *Harness) Reset() {
+	h.REST.Reset()
+	h.mu.Lock()
+	h.errors = nil
+	h.mu.Unlock()
+}
+
+// Filter returns captured actions of given kinds.
+func (h *Harness) Filter(kinds ...ActionKind) []Action {
+	res := make([]Action, 0)
+	for _, a := range h.REST.Actions() {
+		for _, k := range kinds {
+			if a.Kind == k {
+				res = append(res, a)
+				break
+			}
+		}
+	}
+	return res
+}
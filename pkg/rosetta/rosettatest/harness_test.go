@@ -0,0 +1,142 @@
+package rosettatest
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Iridaceae/iridaceae/pkg/rosetta"
+)
+
+type pingCmd struct{}
+
+func (p *pingCmd) GetInvokers() []string {
+	return []string{"ping"}
+}
+
+func (p *pingCmd) GetDescription() string {
+	return "ping pong"
+}
+
+func (p *pingCmd) GetUsage() string {
+	return "`ping` - ping"
+}
+
+func (p *pingCmd) GetGroup() string {
+	return rosetta.GroupFun
+}
+
+func (p *pingCmd) GetDomain() string {
+	return "test.fun.ping"
+}
+
+func (p *pingCmd) GetSubPermissionRules() []rosetta.SubPermission {
+	return nil
+}
+
+func (p *pingCmd) IsExecutableInDM() bool {
+	return false
+}
+
+func (p *pingCmd) Exec(ctx rosetta.Context) error {
+	if ctx.GetArguments().Len() > 0 {
+		_, err := ctx.RespondText("pong " + ctx.GetArguments().Raw())
+		return err
+	}
+	msg, err := ctx.RespondEmbed(&discordgo.MessageEmbed{Title: "Pong", Description: ctx.GetMember().User.Username})
+	if err != nil {
+		return err
+	}
+	return ctx.GetSession().MessageReactionAdd(msg.ChannelID, msg.ID, "🏓")
+}
+
+func makeTestHarness(t *testing.T) (*Harness, *discordgo.User) {
+	cfg := rosetta.NewDefaultConfig()
+	cfg.OnError = nil
+	h := New(t, cfg)
+	h.Router.Register(&pingCmd{})
+	h.AddGuild(&discordgo.Guild{ID: "1", Name: "guild"})
+	h.AddChannel(&discordgo.Channel{ID: "10", GuildID: "1", Type: discordgo.ChannelTypeGuildText})
+	user := &discordgo.User{ID: "2", Username: "user"}
+	h.AddMember(&discordgo.Member{GuildID: "1", User: user})
+	return h, user
+}
+
+func TestHarness_Send(t *testing.T) {
+	h, user := makeTestHarness(t)
+
+	h.Send(user, "10", "r!ping")
+	a := h.ExpectEmbedTitle("Pong")
+	require.NotNil(t, a)
+	assert.Equal(t, "10", a.ChannelID)
+	assert.Equal(t, "user", a.Embed().Description)
+	require.Len(t, h.Sent(), 1)
+	h.ExpectReaction(a.MessageID, "🏓")
+	h.ExpectNoErrors()
+
+	// bots and messages without prefix are ignored.
+	h.Reset()
+	h.Send(&discordgo.User{ID: "3", Bot: true}, "10", "r!ping")
+	h.Send(user, "10", "ping")
+	h.ExpectNoResponse()
+
+	h.Send(user, "10", "r!pong")
+	h.ExpectError(rosetta.ErrTypeCommandNotFound)
+	h.ExpectEmbedTitle("Unknown command `pong`")
+}
+
+func TestHarness_EditDelete(t *testing.T) {
+	h, user := makeTestHarness(t)
+
+	msg := h.Send(user, "10", "r!ping a")
+	h.ExpectText("pong a")
+	reply := h.Sent()[0]
+
+	// the reply of the command is edited and deleted with the invoking message.
+	h.Reset()
+	h.Edit(msg, "r!ping b")
+	edits := h.Filter(ActionEdit)
+	require.Len(t, edits, 1)
+	assert.Equal(t, reply.MessageID, edits[0].MessageID)
+	assert.Equal(t, "pong b", edits[0].Content)
+
+	h.Delete(msg)
+	h.ExpectDeleted(reply.MessageID)
+}
+
+func TestHarness_DM(t *testing.T) {
+	h, user := makeTestHarness(t)
+	h.AddChannel(&discordgo.Channel{ID: "20", Type: discordgo.ChannelTypeDM, Recipients: []*discordgo.User{user}})
+
+	h.Send(user, "20", "r!ping")
+	h.ExpectError(rosetta.ErrTypeNotExecutableInDM)
+
+	c, err := h.Session.UserChannelCreate("4")
+	require.NoError(t, err)
+	assert.Equal(t, discordgo.ChannelTypeDM, c.Type)
+	c2, err := h.Session.UserChannelCreate("4")
+	require.NoError(t, err)
+	assert.Equal(t, c.ID, c2.ID)
+}
+
+func TestContext(t *testing.T) {
+	ctx := NewContext(&discordgo.User{ID: "2"}, &discordgo.Channel{ID: "10", GuildID: "1"}, "ping a b")
+	var _ rosetta.Context = ctx
+
+	assert.Equal(t, "1", ctx.GetGuild().ID)
+	assert.False(t, ctx.IsDM())
+	assert.Equal(t, 3, ctx.GetArguments().Len())
+
+	_, _ = ctx.RespondText("pong")
+	_, _ = ctx.RespondEmbedError("Failed", rosetta.ErrCommandExec)
+	assert.Equal(t, []string{"Failed"}, ctx.EmbedTitles())
+	assert.True(t, ctx.HasResponse("pong"))
+	assert.False(t, ctx.HasResponse("ping"))
+
+	_, ok := ctx.GetPipedInput()
+	assert.False(t, ok)
+	ctx.SetObject("key", 1)
+	assert.Equal(t, 1, ctx.GetObject("key"))
+}
@@ -0,0 +1,392 @@
+package rosettatest
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ActionKind defines what a captured request did.
+type ActionKind string
+
+const (
+	ActionSend        ActionKind = "send"
+	ActionEdit        ActionKind = "edit"
+	ActionDelete      ActionKind = "delete"
+	ActionReact       ActionKind = "react"
+	ActionUnreact     ActionKind = "unreact"
+	ActionInteraction ActionKind = "interaction"
+	ActionOther       ActionKind = "other"
+)
+
+// Action is a single request our bot sent to the REST API.
+type Action struct {
+	Kind      ActionKind
+	Method    string
+	Path      string
+	ChannelID string
+
+	// MessageID is the ID of the created, edited, deleted or reacted message.
+	MessageID string
+	Content   string
+	Embeds    []*discordgo.MessageEmbed
+	Files     []string
+	Emoji     string
+
+	// UserID is the user of an added or removed reaction, `@me` for our bot.
+	UserID string
+
+	// Reference is the message a sent message replies to.
+	Reference *discordgo.MessageReference
+
+	// AllowedMentions of a sent or edited message.
+	AllowedMentions *discordgo.MessageAllowedMentions
+}
+
+// Embed returns the first embed of this action, nil if there is none.
+func (a *Action) Embed() *discordgo.MessageEmbed {
+	if len(a.Embeds) == 0 {
+		return nil
+	}
+	return a.Embeds[0]
+}
+
+// messagePayload is the body of a sent or edited message or interaction response.
+type messagePayload struct {
+	Content         *string                           `json:"content"`
+	Embed           *discordgo.MessageEmbed           `json:"embed"`
+	Embeds          []*discordgo.MessageEmbed         `json:"embeds"`
+	Reference       *discordgo.MessageReference       `json:"message_reference"`
+	AllowedMentions *discordgo.MessageAllowedMentions `json:"allowed_mentions"`
+	Data            *messagePayload                   `json:"data"`
+}
+
+// REST is a fake of the Discord REST API backed by a discordgo.State. It answers the
+// endpoints used by our router and records every request as Action. REST is an
+// http.Handler and can be served by a real HTTP server as well.
+type REST struct {
+	State *discordgo.State
+
+	mu       sync.Mutex
+	actions  []Action
+	messages map[string]*discordgo.Message
+	nextID   int64
+
+	// dmsDisabled holds users who don't accept DMs.
+	dmsDisabled map[string]bool
+}
+
+// NewREST returns a REST fake reading guilds, channels and members from given state.
+func NewREST(state *discordgo.State) *REST {
+	return &REST{State: state, messages: make(map[string]*discordgo.Message), nextID: 1000, dmsDisabled: make(map[string]bool)}
+}
+
+// DisableDMs lets messages sent to the DMs of given user fail, like for users who
+// don't accept DMs from server members.
+func (f *REST) DisableDMs(userID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dmsDisabled[userID] = true
+}
+
+// Transport returns a http.RoundTripper serving requests in-process by this fake.
+// It can be set as transport of discordgo.Session.Client.
+func (f *REST) Transport() http.RoundTripper {
+	return roundTripper(func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		f.ServeHTTP(rec, req)
+		return rec.Result(), nil
+	})
+}
+
+type roundTripper func(req *http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// NewID returns a new unique snowflake-like ID.
+func (f *REST) NewID() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	return strconv.FormatInt(f.nextID, 10)
+}
+
+// Actions returns a copy of all recorded actions.
+func (f *REST) Actions() []Action {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Action{}, f.actions...)
+}
+
+// Reset forgets all recorded actions.
+func (f *REST) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = nil
+}
+
+// Message returns a message sent through this fake by ID.
+func (f *REST) Message(id string) (*discordgo.Message, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	msg, ok := f.messages[id]
+	return msg, ok
+}
+
+func (f *REST) record(a Action) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = append(f.actions, a)
+}
+
+func (f *REST) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := strings.Trim(req.URL.Path, "/")
+	if i := strings.Index(path, "api/v"); i >= 0 {
+		path = path[i+len("api/v"):]
+		if j := strings.Index(path, "/"); j >= 0 {
+			path = path[j+1:]
+		}
+	}
+	parts := strings.Split(path, "/")
+	action := Action{Kind: ActionOther, Method: req.Method, Path: path}
+
+	switch {
+	case match(parts, "channels", "*") && req.Method == http.MethodGet:
+		f.getChannel(w, parts[1])
+		return
+	case match(parts, "guilds", "*") && req.Method == http.MethodGet:
+		f.getGuild(w, parts[1])
+		return
+	case match(parts, "guilds", "*", "members", "*") && req.Method == http.MethodGet:
+		f.getMember(w, parts[1], parts[3])
+		return
+	case match(parts, "users", "@me") && req.Method == http.MethodGet:
+		writeJSON(w, f.State.User)
+		return
+	case match(parts, "users", "@me", "channels") && req.Method == http.MethodPost:
+		f.createDM(w, req)
+		return
+	case len(parts) > 2 && parts[0] == "applications" && parts[len(parts)-1] == "commands" && req.Method == http.MethodPut:
+		// application commands are overwritten as they are sent.
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.Copy(w, req.Body)
+		return
+	case match(parts, "channels", "*", "messages") && req.Method == http.MethodPost:
+		if f.isDMDisabled(parts[1]) {
+			writeErrorCode(w, http.StatusForbidden, discordgo.ErrCodeCannotSendMessagesToThisUser, "Cannot send messages to this user")
+			return
+		}
+		action.Kind, action.ChannelID = ActionSend, parts[1]
+	case match(parts, "channels", "*", "messages", "*") && req.Method == http.MethodPatch:
+		action.Kind, action.ChannelID, action.MessageID = ActionEdit, parts[1], parts[3]
+	case match(parts, "channels", "*", "messages", "*") && req.Method == http.MethodDelete:
+		action.Kind, action.ChannelID, action.MessageID = ActionDelete, parts[1], parts[3]
+	case match(parts, "channels", "*", "messages", "*", "reactions", "*", "*") && req.Method == http.MethodPut:
+		action.Kind, action.ChannelID, action.MessageID, action.Emoji, action.UserID = ActionReact, parts[1], parts[3], parts[5], parts[6]
+	case match(parts, "channels", "*", "messages", "*", "reactions", "*", "*") && req.Method == http.MethodDelete:
+		action.Kind, action.ChannelID, action.MessageID, action.Emoji, action.UserID = ActionUnreact, parts[1], parts[3], parts[5], parts[6]
+	case match(parts, "channels", "*", "messages", "*", "reactions") && req.Method == http.MethodDelete:
+		action.Kind, action.ChannelID, action.MessageID = ActionUnreact, parts[1], parts[3]
+	case len(parts) > 0 && (parts[0] == "interactions" || parts[0] == "webhooks"):
+		action.Kind = ActionInteraction
+	}
+
+	var payload messagePayload
+	files, err := readPayload(req, &payload)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if payload.Data != nil {
+		payload = *payload.Data
+	}
+	if payload.Content != nil {
+		action.Content = *payload.Content
+	}
+	if payload.Embed != nil {
+		action.Embeds = []*discordgo.MessageEmbed{payload.Embed}
+	}
+	action.Embeds = append(action.Embeds, payload.Embeds...)
+	action.Files, action.Reference, action.AllowedMentions = files, payload.Reference, payload.AllowedMentions
+	creates := action.Kind == ActionSend ||
+		(action.Kind == ActionInteraction && req.Method != http.MethodDelete && !strings.HasSuffix(path, "/callback"))
+	if creates {
+		action.MessageID = f.NewID()
+	}
+	f.record(action)
+
+	switch {
+	case creates:
+		msg := &discordgo.Message{
+			ID:        action.MessageID,
+			ChannelID: action.ChannelID,
+			Content:   action.Content,
+			Embeds:    action.Embeds,
+			Timestamp: discordgo.Timestamp(time.Now().Format(time.RFC3339)),
+			Author:    f.State.User,
+		}
+		if c, err := f.State.Channel(msg.ChannelID); err == nil {
+			msg.GuildID = c.GuildID
+		}
+		f.mu.Lock()
+		f.messages[msg.ID] = msg
+		f.mu.Unlock()
+		writeJSON(w, msg)
+	case action.Kind == ActionEdit:
+		f.mu.Lock()
+		msg, ok := f.messages[action.MessageID]
+		if !ok {
+			msg = &discordgo.Message{ID: action.MessageID, ChannelID: action.ChannelID, Author: f.State.User}
+			f.messages[msg.ID] = msg
+		}
+		if payload.Content != nil {
+			msg.Content = action.Content
+		}
+		if len(action.Embeds) > 0 {
+			msg.Embeds = action.Embeds
+		}
+		f.mu.Unlock()
+		writeJSON(w, msg)
+	case action.Kind == ActionOther:
+		writeError(w, http.StatusNotFound, "unknown endpoint "+req.Method+" "+path)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *REST) getChannel(w http.ResponseWriter, channelID string) {
+	c, err := f.State.Channel(channelID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "unknown channel")
+		return
+	}
+	writeJSON(w, c)
+}
+
+func (f *REST) getGuild(w http.ResponseWriter, guildID string) {
+	g, err := f.State.Guild(guildID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "unknown guild")
+		return
+	}
+	writeJSON(w, g)
+}
+
+func (f *REST) getMember(w http.ResponseWriter, guildID, userID string) {
+	m, err := f.State.Member(guildID, userID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "unknown member")
+		return
+	}
+	writeJSON(w, m)
+}
+
+// isDMDisabled returns true if given channel is the DM of a user who doesn't accept DMs.
+func (f *REST) isDMDisabled(channelID string) bool {
+	c, err := f.State.Channel(channelID)
+	if err != nil || c.Type != discordgo.ChannelTypeDM || len(c.Recipients) == 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.dmsDisabled[c.Recipients[0].ID]
+}
+
+// createDM returns the DM channel of a user, which is created on first use.
+func (f *REST) createDM(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		RecipientID string `json:"recipient_id"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, c := range f.State.PrivateChannels {
+		if len(c.Recipients) > 0 && c.Recipients[0].ID == body.RecipientID {
+			writeJSON(w, c)
+			return
+		}
+	}
+	c := &discordgo.Channel{
+		ID:         f.NewID(),
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{{ID: body.RecipientID}},
+	}
+	if err := f.State.ChannelAdd(c); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, c)
+}
+
+// readPayload decodes a JSON or multipart body into v and returns names of attached files.
+func readPayload(req *http.Request, v interface{}) ([]string, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil || len(b) == 0 {
+			return nil, err
+		}
+		return nil, json.Unmarshal(b, v)
+	}
+
+	var files []string
+	mr := multipart.NewReader(req.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		if p.FormName() == "payload_json" {
+			if err = json.NewDecoder(p).Decode(v); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		files = append(files, p.FileName())
+	}
+	return files, nil
+}
+
+// match returns true if given path parts equal pattern, where `*` matches any part.
+func match(parts []string, pattern ...string) bool {
+	if len(parts) != len(pattern) {
+		return false
+	}
+	for i, p := range pattern {
+		if p != "*" && p != parts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeErrorCode(w, status, 0, message)
+}
+
+func writeErrorCode(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "message": message})
+}
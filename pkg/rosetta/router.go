@@ -1,12 +1,16 @@
 package rosetta
 
 import (
+	gocontext "context"
+	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/Iridaceae/iridaceae/pkg/log"
+	"github.com/Iridaceae/iridaceae/pkg/i18n"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -37,11 +41,102 @@ type Config struct {
 	UseDefaultHelpCommand bool   `json:"user_default_help_command"`
 	DeleteMessageAfter    bool   `json:"delete_message_after"`
 
+	// UseSlashCommands handles interactions and overwrites the application commands with our
+	// registered commands on every READY, globally unless SlashCommandsGuildID is set. It's
+	// disabled by default, as commands registered otherwise are deleted.
+	UseSlashCommands bool `json:"use_slash_commands"`
+
+	// UseDefaultCommandsCommand registers DefaultCommandsCommand, which lets guild admins
+	// disable commands and groups in their guild.
+	UseDefaultCommandsCommand bool `json:"use_default_commands_command"`
+
+	// UseRecovery registers Recover as outermost MiddlewareFunc, so a panicking command
+	// is passed to OnError and LayerAfterCommand middleware is skipped. Panics outside of
+	// commands, e.g. in middleware, are always recovered when the invocation was handled.
+	UseRecovery bool `json:"use_recovery"`
+
+	// SuggestCommands replies with the closest commands when an unknown command is invoked.
+	SuggestCommands bool `json:"suggest_commands"`
+
+	// SuggestionMaxDistance defines the maximum edit distance of a suggested command invoker.
+	SuggestionMaxDistance int `json:"suggestion_max_distance"`
+
+	// IgnorePrefixCollisions keeps the router silent when an unknown command has no close
+	// suggestion or doesn't start with a letter, e.g. `!!` or another bot sharing our prefix.
+	IgnorePrefixCollisions bool `json:"ignore_prefix_collisions"`
+
+	// CommandTimeout defines the duration after which the context of a command is cancelled.
+	// It can be overridden per command by implementing TimeoutCommand. A zero value disables it.
+	CommandTimeout time.Duration `json:"command_timeout"`
+
+	// ReplyTTL defines how long replies of a command are remembered. Within this time, replies
+	// are edited when the invoking message is edited and deleted when it is deleted.
+	// A zero value disables it.
+	ReplyTTL time.Duration `json:"reply_ttl"`
+
+	// Workers defines the amount of goroutines commands are executed on. If zero, commands
+	// are executed inline on the discordgo event goroutine.
+	Workers int `json:"workers"`
+
+	// QueueSize defines the amount of commands which can wait for a free worker.
+	QueueSize int `json:"queue_size"`
+
+	// QueueOverflow defines whether the event handler blocks or the command is dropped
+	// when the queue is full.
+	QueueOverflow OverflowPolicy `json:"queue_overflow"`
+
+	// Serialize defines whether commands of the same guild or channel are executed in order.
+	Serialize SerializeMode `json:"serialize"`
+
+	// AllowChaining enables running several commands of one message separated by `;`
+	// and piping the text output of a command into the next one with `|`. It's disabled by
+	// default, as arguments can't contain these separators while enabled.
+	AllowChaining bool `json:"allow_chaining"`
+
+	// MaxChainLength defines how many commands a chained message can contain.
+	// Further segments are ignored. A zero value disables the limit.
+	MaxChainLength int `json:"max_chain_length"`
+
+	// SlashCommandsGuildID registers application commands to given guild only instead of globally.
+	// Guild commands are updated instantly, which is useful while developing.
+	SlashCommandsGuildID string `json:"slash_commands_guild_id"`
+
+	// UseDefaultPrefixCommand registers DefaultPrefixCommand, which lets guild admins
+	// view and change the prefixes of their guild.
+	UseDefaultPrefixCommand bool `json:"use_default_prefix_command"`
+
+	// PrefixProvider stores custom prefixes per guild, which are matched besides GeneralPrefix,
+	// GuildPrefixGetter and mentions of the bot. If not given, prefixes are kept in memory.
+	PrefixProvider PrefixProvider `json:"-"`
+
+	// DisabledProvider stores commands and groups disabled per guild. If not given,
+	// entries are kept in memory.
+	DisabledProvider DisabledProvider `json:"-"`
+
+	// UseDefaultLocaleCommand registers DefaultLocaleCommand, which lets users choose their
+	// language and guild admins the one of their guild.
+	UseDefaultLocaleCommand bool `json:"use_default_locale_command"`
+
+	// Translations contains the catalogs responses are translated with. Keys missing in it
+	// fall back to our built-in catalogs. If not given, NewTranslations is used.
+	Translations *i18n.Bundle `json:"-"`
+
+	// LocaleProvider stores the locale per guild and user. If not given, locales are kept in memory.
+	LocaleProvider LocaleProvider `json:"-"`
+
+	// OwnerIDs are the IDs of bot owners, the only users permitted to execute an OwnerOnlyCommand.
+	OwnerIDs []string `json:"owner_ids"`
+
+	// AllowedMentions defines the mentions responses can ping unless overridden by
+	// Response.AllowMentions. If not given, DefaultAllowedMentions is used.
+	AllowedMentions *discordgo.MessageAllowedMentions `json:"-"`
+
 	// ObjectContainer can be passed by user to obtain instances from context.
 	ObjectContainer di.Container `json:"-"`
 
 	// OnError will be called when router failed to execute the command.
 	// OnError will be passed when context failed to run, and return an ErrorType and error objects.
+	// Errors passed by the router are of type *Error carrying a correlation ID, see DefaultOnError.
 	OnError func(ctx Context, errType ErrorType, err error)
 
 	// GuildPrefixGetter is called to get guild prefix.
@@ -49,6 +144,8 @@ type Config struct {
 	// the guild prefix if specified, else it will return
 	// default prefix.
 	// An error will be returned when the retrieving of the guild prefix failed unexpectedly.
+	//
+	// Deprecated: use PrefixProvider, which supports multiple prefixes per guild.
 	GuildPrefixGetter func(gid string) (string, error)
 }
 
@@ -56,34 +153,91 @@ type Config struct {
 type Router interface {
 	ReadOnlyObjectMap
 
-	// Register is shortened for RegisterMiddleware and RegisterCommand
-	// and automatically chooses depending on implementation.
+	// Register is shortened for RegisterMiddleware, RegisterMiddlewareFunc, RegisterEventHandler,
+	// RegisterGuildCommandSource and RegisterCommand and automatically chooses depending on implementation.
 	//
-	// panics if an instance is passed which neither implements Command and Middleware.
+	// panics if an instance is passed which neither implements Command, Middleware, MiddlewareFunc,
+	// EventHandler nor GuildCommandSource.
 	Register(v interface{})
 
 	// RegisterCommand registers the passed Command interface.
+	//
+	// panics if one of its invokers is already registered.
 	RegisterCommand(cmd Command)
 
+	// UnregisterCommand removes the command registered under given invoker with all its
+	// invokers. Returns the removed command, false if no command was found.
+	UnregisterCommand(invoke string) (Command, bool)
+
+	// ReplaceCommand atomically removes all commands sharing an invoker with cmd and registers cmd.
+	// Returns the removed commands.
+	ReplaceCommand(cmd Command) []Command
+
 	// RegisterMiddleware registers Middleware interface.
 	RegisterMiddleware(m Middleware)
 
+	// RegisterMiddlewareFunc registers a MiddlewareFunc wrapping the execution of commands.
+	RegisterMiddlewareFunc(m MiddlewareFunc)
+
+	// RegisterEventHandler registers an EventHandler for the events it returns.
+	RegisterEventHandler(h EventHandler)
+
+	// RegisterGuildCommandSource registers a source of commands defined per guild.
+	RegisterGuildCommandSource(src GuildCommandSource)
+
 	// Setup registers given handlers to the passed discordgo.Session which are
 	// used to handle and parse command.
 	Setup(session *discordgo.Session)
 
+	// Trigger handles given discordgo event as if it was received by a session passed
+	// to Setup. This can be used to drive the router without a gateway connection.
+	Trigger(s *discordgo.Session, event interface{})
+
 	// GetConfig returns the specified config object which was specified on initialization.
 	GetConfig() *Config
 
-	// GetCommandMap returns internal command map.
+	// GetCommandMap returns a copy of the internal command map.
 	GetCommandMap() map[string]Command
 
-	// GetCommandInstances returns an array of all registered command instance.
+	// GetCommandInstances returns a copy of all registered command instances.
 	GetCommandInstances() []Command
 
 	// GetCommand returns a command instance from the registry by invoker. If command could
 	// not be found, false is returned.
 	GetCommand(invoke string) (Command, bool)
+
+	// GetSubCommand returns a direct child of given parent by invoker. If parent doesn't
+	// implement ParentCommand or no child matches, false is returned.
+	GetSubCommand(parent Command, invoke string) (Command, bool)
+
+	// GetGuildCommand returns a command of given guild from the registered GuildCommandSource
+	// by invoker. If command could not be found, false is returned.
+	GetGuildCommand(guildID, invoke string) (Command, bool, error)
+
+	// GetGuildCommands returns all commands of given guild from the registered GuildCommandSource.
+	GetGuildCommands(guildID string) ([]Command, error)
+
+	// CanExecute returns whether the invoking user of given context can execute cmd, i.e. cmd is
+	// executable in the channel, not disabled in the guild, passes the checks it declares, e.g.
+	// by PermissionsCommand, and is permitted by all registered middleware implementing
+	// PermissionChecker.
+	CanExecute(cmd Command, ctx Context) (bool, error)
+
+	// GetSuggestions returns invokers of registered commands which are similar to given invoke.
+	// If ctx is not nil, commands which aren't listed by help for its user are left out.
+	GetSuggestions(invoke string, ctx Context) []string
+
+	// ResolveCommand walks given arguments down the command tree and returns the deepest
+	// matching command with the amount of arguments consumed by the invokers path.
+	// If the root command could not be found, false is returned.
+	ResolveCommand(args []Argument) (cmd Command, depth int, ok bool)
+
+	// GetDispatcherStats returns queue depth and wait time metrics of the command dispatcher.
+	GetDispatcherStats() DispatcherStats
+
+	// Shutdown cancels the contexts of all running commands and stops the dispatcher
+	// workers. Commands are no longer dispatched afterwards.
+	Shutdown()
 }
 
 // router is our default implementation of Router.
@@ -92,24 +246,49 @@ type router struct {
 	config          *Config
 	cmdMap          map[string]Command
 	cmdInstances    []Command
+	cmdMu           sync.RWMutex
+	guildSources    []GuildCommandSource
 	middleware      []Middleware
+	middlewareFuncs []MiddlewareFunc
+	eventHandlers   map[reflect.Type][]EventHandler
+	eventMu         sync.RWMutex
 	objectContainer di.Container
 	ctxPool         *sync.Pool
 	objectMap       *sync.Map
+	dispatcher      *dispatcher
+
+	baseCtx    gocontext.Context
+	cancelBase gocontext.CancelFunc
+
+	// running holds cancel funcs of running commands by invoking message ID.
+	running   map[string]*gocontext.CancelFunc
+	runningMu sync.Mutex
+
+	// replies is nil if ReplyTTL is zero.
+	replies *replyTracker
 }
 
 func NewDefaultConfig() *Config {
 	return &Config{
-		GeneralPrefix:         "r!",
-		IgnoreCase:            true,
-		AllowDM:               true,
-		AllowBots:             false,
-		ExecuteOnEdit:         true,
-		UseDefaultHelpCommand: true,
-		DeleteMessageAfter:    false,
-		OnError: func(ctx Context, errType ErrorType, err error) {
-			log.Error(err).Msgf("[%d] %+v", errType, ctx)
-		},
+		GeneralPrefix:          "r!",
+		IgnoreCase:             true,
+		AllowDM:                true,
+		AllowBots:              false,
+		ExecuteOnEdit:          true,
+		UseDefaultHelpCommand:  true,
+		UseRecovery:            true,
+		DeleteMessageAfter:     false,
+		SuggestCommands:        true,
+		SuggestionMaxDistance:  2,
+		IgnorePrefixCollisions: true,
+		CommandTimeout:         30 * time.Second,
+		ReplyTTL:               5 * time.Minute,
+		Workers:                8,
+		QueueSize:              100,
+		QueueOverflow:          OverflowDrop,
+		Serialize:              SerializeChannel,
+		MaxChainLength:         5,
+		OnError:                DefaultOnError,
 	}
 }
 
@@ -121,6 +300,21 @@ func NewRouter(c *Config) Router {
 	if c.GuildPrefixGetter == nil {
 		c.GuildPrefixGetter = func(string) (string, error) { return "", nil }
 	}
+	if c.PrefixProvider == nil {
+		c.PrefixProvider = NewMemoryPrefixProvider()
+	}
+	if c.DisabledProvider == nil {
+		c.DisabledProvider = NewMemoryDisabledProvider()
+	}
+	if c.Translations == nil {
+		c.Translations = NewTranslations()
+	}
+	if c.LocaleProvider == nil {
+		c.LocaleProvider = NewMemoryLocaleProvider()
+	}
+	if c.AllowedMentions == nil {
+		c.AllowedMentions = DefaultAllowedMentions()
+	}
 	r := &router{
 		config:          c,
 		cmdMap:          make(map[string]Command),
@@ -128,15 +322,36 @@ func NewRouter(c *Config) Router {
 		objectContainer: c.ObjectContainer,
 		ctxPool:         &sync.Pool{New: func() interface{} { return &context{objectMap: &sync.Map{}} }},
 		objectMap:       &sync.Map{},
+		running:         make(map[string]*gocontext.CancelFunc),
+		eventHandlers:   make(map[reflect.Type][]EventHandler),
 	}
+	r.baseCtx, r.cancelBase = gocontext.WithCancel(gocontext.Background())
+	if c.ReplyTTL > 0 {
+		r.replies = newReplyTracker(c.ReplyTTL)
+	}
+	r.dispatcher = newDispatcher(c.Workers, c.QueueSize, c.QueueOverflow, r.baseCtx.Done())
 
 	if r.objectContainer == nil {
 		builder, _ := di.NewBuilder()
 		r.objectContainer = builder.Build()
 	}
 
+	if c.UseRecovery {
+		r.RegisterMiddlewareFunc(Recover())
+	}
 	if c.UseDefaultHelpCommand {
-		r.RegisterCommand(&DefaultHelpCommand{})
+		help := &DefaultHelpCommand{}
+		r.RegisterCommand(help)
+		r.RegisterEventHandler(help)
+	}
+	if c.UseDefaultCommandsCommand {
+		r.RegisterCommand(&DefaultCommandsCommand{})
+	}
+	if c.UseDefaultPrefixCommand {
+		r.RegisterCommand(&DefaultPrefixCommand{})
+	}
+	if c.UseDefaultLocaleCommand {
+		r.RegisterCommand(&DefaultLocaleCommand{})
 	}
 	return r
 }
@@ -159,21 +374,115 @@ func (r *router) Register(v interface{}) {
 		r.RegisterCommand(i)
 	case Middleware:
 		r.RegisterMiddleware(i)
+	case MiddlewareFunc:
+		r.RegisterMiddlewareFunc(i)
+	case func(next HandlerFunc) HandlerFunc:
+		r.RegisterMiddlewareFunc(i)
+	case EventHandler:
+		r.RegisterEventHandler(i)
+	case GuildCommandSource:
+		r.RegisterGuildCommandSource(i)
 	default:
-		panic("instance doesn't implements Command or Middleware")
+		panic("instance doesn't implements Command, Middleware, MiddlewareFunc, EventHandler or GuildCommandSource")
 	}
 }
 
 func (r *router) RegisterCommand(cmd Command) {
+	r.validateCommand(cmd)
+
+	r.cmdMu.Lock()
+	defer r.cmdMu.Unlock()
+	for _, i := range cmd.GetInvokers() {
+		if _, ok := r.cmdMap[r.normalizeInvoke(i)]; ok {
+			panic(fmt.Sprintf("invoke %s already registered, panicked!", i))
+		}
+	}
+	r.addCommand(cmd)
+}
+
+func (r *router) UnregisterCommand(invoke string) (Command, bool) {
+	r.cmdMu.Lock()
+	defer r.cmdMu.Unlock()
+	cmd, ok := r.cmdMap[r.normalizeInvoke(invoke)]
+	if ok {
+		r.removeCommand(cmd)
+	}
+	return cmd, ok
+}
+
+func (r *router) ReplaceCommand(cmd Command) []Command {
+	r.validateCommand(cmd)
+
+	r.cmdMu.Lock()
+	defer r.cmdMu.Unlock()
+	replaced := make([]Command, 0, 1)
+	for _, i := range cmd.GetInvokers() {
+		if old, ok := r.cmdMap[r.normalizeInvoke(i)]; ok {
+			r.removeCommand(old)
+			replaced = append(replaced, old)
+		}
+	}
+	r.addCommand(cmd)
+	return replaced
+}
+
+// addCommand adds given command to our registry. cmdMu must be held.
+func (r *router) addCommand(cmd Command) {
 	r.cmdInstances = append(r.cmdInstances, cmd)
 	for _, i := range cmd.GetInvokers() {
-		if r.config.IgnoreCase {
-			i = strings.ToLower(i)
+		r.cmdMap[r.normalizeInvoke(i)] = cmd
+	}
+}
+
+// removeCommand removes given command with all its invokers from our registry. cmdMu must be held.
+func (r *router) removeCommand(cmd Command) {
+	for _, i := range cmd.GetInvokers() {
+		if r.cmdMap[r.normalizeInvoke(i)] == cmd {
+			delete(r.cmdMap, r.normalizeInvoke(i))
 		}
-		if _, ok := r.cmdMap[i]; ok {
-			panic(fmt.Sprintf("invoke %s already registered, panicked!", i))
+	}
+	// instances are copied on write, so copies handed out stay untouched.
+	instances := make([]Command, 0, len(r.cmdInstances))
+	for _, c := range r.cmdInstances {
+		if c != cmd {
+			instances = append(instances, c)
+		}
+	}
+	r.cmdInstances = instances
+}
+
+func (r *router) normalizeInvoke(invoke string) string {
+	if r.config.IgnoreCase {
+		return strings.ToLower(invoke)
+	}
+	return invoke
+}
+
+// validateCommand ensures that siblings in a command tree don't share invokers and
+// parameters of their schemas can be bound.
+func (r *router) validateCommand(cmd Command) {
+	if sc, ok := cmd.(SchemaCommand); ok {
+		if err := sc.GetSchema().Validate(); err != nil {
+			panic(fmt.Sprintf("command %s has %s, panicked!", cmd.GetInvokers()[0], err))
+		}
+	}
+
+	p, ok := cmd.(ParentCommand)
+	if !ok {
+		return
+	}
+	seen := make(map[string]struct{})
+	for _, sub := range p.GetSubCommands() {
+		for _, i := range sub.GetInvokers() {
+			if r.config.IgnoreCase {
+				i = strings.ToLower(i)
+			}
+			if _, ok := seen[i]; ok {
+				panic(fmt.Sprintf("sub command invoke %s already registered under %s, panicked!", i, cmd.GetInvokers()[0]))
+			}
+			seen[i] = struct{}{}
 		}
-		r.cmdMap[i] = cmd
+		r.validateCommand(sub)
 	}
 }
 
@@ -181,111 +490,434 @@ func (r *router) RegisterMiddleware(m Middleware) {
 	r.middleware = append(r.middleware, m)
 }
 
+func (r *router) RegisterMiddlewareFunc(m MiddlewareFunc) {
+	r.middlewareFuncs = append(r.middlewareFuncs, m)
+}
+
+// getHandler returns command execution wrapped by our MiddlewareFunc.
+func (r *router) getHandler() HandlerFunc {
+	h := HandlerFunc(func(cmd Command, ctx Context) error { return cmd.Exec(ctx) })
+	for i := len(r.middlewareFuncs) - 1; i >= 0; i-- {
+		h = r.middlewareFuncs[i](h)
+	}
+	return h
+}
+
 func (r *router) Setup(session *discordgo.Session) {
-	session.AddHandler(func(s *discordgo.Session, e *discordgo.MessageCreate) { r.trigger(s, e.Message) })
-	if r.config.ExecuteOnEdit {
-		session.AddHandler(func(s *discordgo.Session, e *discordgo.MessageUpdate) { r.trigger(s, e.Message) })
+	session.AddHandler(r.Trigger)
+}
+
+func (r *router) Trigger(s *discordgo.Session, event interface{}) {
+	switch e := event.(type) {
+	case *discordgo.MessageCreate:
+		r.trigger(s, e.Message)
+	case *discordgo.MessageUpdate:
+		if r.config.ExecuteOnEdit {
+			r.triggerMessage(s, e.Message, true)
+		}
+	case *discordgo.MessageDelete:
+		r.cancelRunning(e.ID)
+		r.deleteReplies(s, e.ID)
+	case *discordgo.MessageDeleteBulk:
+		for _, id := range e.Messages {
+			r.cancelRunning(id)
+			r.deleteReplies(s, id)
+		}
+	case *discordgo.Ready:
+		if r.config.UseSlashCommands {
+			r.registerSlashCommands(s, e)
+		}
+	case *discordgo.Event:
+		if r.config.UseSlashCommands && e.Type == eventInteractionCreate {
+			r.triggerInteraction(s, e.RawData)
+		}
 	}
+	r.triggerEvent(s, event)
 }
 
 func (r *router) trigger(s *discordgo.Session, msg *discordgo.Message) {
-	var (
-		err    error
-		prefix = ""
-	)
+	r.triggerMessage(s, msg, false)
+}
 
+// triggerMessage handles given created or, if isEdit is true, edited message.
+func (r *router) triggerMessage(s *discordgo.Session, msg *discordgo.Message, isEdit bool) {
 	// check if given message author is a bot.
-	if !r.config.AllowBots || msg.Author == nil || msg.Author.Bot || msg.Author.ID == s.State.User.ID {
+	if msg.Author == nil || (msg.Author.Bot && !r.config.AllowBots) || (s.State.User != nil && msg.Author.ID == s.State.User.ID) {
 		return
 	}
 
-	ctx, _ := r.ctxPool.Get().(*context)
-	ctx.router = r
-	ctx.session = s
+	// mentions of our bot, the guild prefixes and the default prefix are matched, longest first.
+	prefixes, err := r.getPrefixes(s, msg.GuildID)
+	if err != nil {
+		r.onMessageError(s, msg, ErrTypeGuildPrefixGetter, err)
+	}
+	prefix, trimmed, ok := resolvePrefix(msg.Content, prefixes, r.config.IgnoreCase)
+
+	// if no prefix is received or message is empty after prefix then we don't do anything.
+	// An edited message which isn't a command anymore has its previous replies deleted.
+	if !ok || trimmed == "" {
+		if isEdit {
+			r.deleteReplies(s, msg.ID)
+		}
+		return
+	}
+	if arrayContains(getMentionPrefixes(s), prefix, false) {
+		prefix = "@" + s.State.User.Username + " "
+	}
+
+	// the command is cancelled by deleting its message while queued as well as while running.
+	base, release := r.trackMessage(msg.ID)
+	err = r.dispatcher.submit(r.getSerializeKey(msg.GuildID, msg.ChannelID), func() {
+		defer release()
+		r.handleMessage(s, msg, base, prefix, trimmed, isEdit)
+	})
+	if err != nil {
+		release()
+	}
+	if errors.Is(err, ErrQueueFull) {
+		r.onMessageError(s, msg, ErrTypeQueueFull, err)
+	}
+}
+
+// onError passes given error wrapped into an Error to OnError.
+func (r *router) onError(ctx Context, errType ErrorType, err error) {
+	var domain string
+	if c, ok := ctx.(*context); ok {
+		domain = c.domain()
+	}
+	r.config.OnError(ctx, errType, WrapError(errType, domain, err))
+}
+
+// onMessageError passes given error to OnError with a context of given message.
+func (r *router) onMessageError(s *discordgo.Session, msg *discordgo.Message, errType ErrorType, err error) {
+	ctx := r.acquireContext(s)
+	ctx.message = msg
+	r.onError(ctx, errType, err)
+	r.releaseContext(ctx)
+}
+
+// handleMessage resolves and executes the command of given message with its prefix trimmed.
+// Commands are cancelled with given base context.
+func (r *router) handleMessage(s *discordgo.Session, msg *discordgo.Message, base gocontext.Context, prefix, trimmed string, isEdit bool) {
+	ctx := r.acquireContext(s)
+	ctx.base = base
 	ctx.message = msg
 	ctx.member = msg.Member
-	ctx.isEdit = false
-	defer func() {
-		clearMap(ctx.objectMap)
-		r.ctxPool.Put(ctx)
-	}()
+	ctx.isEdit = isEdit
+	defer r.releaseContext(ctx)
 
-	var trimmed string
-	var contains bool
-	// We will first check if users setup guild prefix, otherwise we will use default prefix.
-	guildPrefix, _ := r.config.GuildPrefixGetter(msg.GuildID)
-	if trimmed, contains = hasPrefix(msg.Content, guildPrefix, r.config.IgnoreCase); contains {
-		prefix = guildPrefix
-	} else if trimmed, contains = hasPrefix(msg.Content, r.config.GeneralPrefix, r.config.IgnoreCase); contains {
-		prefix = r.config.GeneralPrefix
+	if r.replies != nil {
+		if isEdit {
+			ctx.prevReplies = r.replies.get(msg.ID)
+		}
+		defer r.finishReplies(ctx)
 	}
+	defer r.recoverPanic(ctx)
 
-	// if no prefix is received or message is empty after prefix then we don't do anything.
-	if prefix == "" || trimmed == "" {
+	if !r.fillEnvironment(ctx, msg.ChannelID, msg.GuildID) {
 		return
 	}
 
-	if ctx.channel, err = s.State.Channel(msg.ChannelID); err != nil {
-		if ctx.channel, err = s.Channel(msg.ChannelID); err != nil {
-			r.config.OnError(ctx, ErrTypeGetChannel, err)
+	var out *strings.Builder
+	segments := []Segment{{Content: trimmed}}
+	if r.config.AllowChaining {
+		segments = SplitChain(trimmed)
+		if max := r.config.MaxChainLength; max > 0 && len(segments) > max {
+			segments = segments[:max]
+		}
+	}
+
+	for i, seg := range segments {
+		content := seg.Content
+		if i > 0 {
+			// following segments may repeat the prefix, e.g. `r!pom 25; r!status`.
+			prefixes, _ := r.getPrefixes(s, msg.GuildID)
+			if _, t, ok := resolvePrefix(content, prefixes, r.config.IgnoreCase); ok {
+				content = t
+			}
+		}
+
+		ctx.args, ctx.params, ctx.root, ctx.cmd, ctx.invoke = nil, nil, nil, nil, ""
+		ctx.pipeIn, ctx.pipeOut = nil, nil
+		if seg.Piped && out != nil {
+			in := out.String()
+			ctx.pipeIn = &in
+		}
+		out = nil
+		if i+1 < len(segments) && segments[i+1].Piped {
+			out = &strings.Builder{}
+			ctx.pipeOut = out
+		}
+
+		if !r.handleSegment(ctx, prefix, content) {
+			return
+		}
+	}
+
+	if r.config.DeleteMessageAfter {
+		// replies shall outlive the deleted invoking message.
+		ctx.replies = nil
+		if err := s.ChannelMessageDelete(msg.ChannelID, msg.ID); err != nil {
+			r.onError(ctx, ErrTypeDeleteCommandMessage, err)
 			return
 		}
 	}
+}
+
+// handleSegment resolves and dispatches the command of given content. Returns false if
+// the command couldn't be executed, which stops a chain.
+func (r *router) handleSegment(ctx *context, prefix, content string) bool {
+	args := ParseArguments(content)
+	cmd, depth, ok := r.ResolveCommand(args.Args())
+	if !ok && args.Len() > 0 {
+		// commands of the guild are resolved after registered commands missed.
+		var err error
+		if cmd, ok, err = r.GetGuildCommand(ctx.message.GuildID, args.Get(0).String()); err != nil {
+			r.onError(ctx, ErrTypeGetGuildCommand, err)
+			return false
+		}
+		depth = 1
+	}
+	if !ok {
+		ctx.args = args
+		r.onError(ctx, ErrTypeCommandNotFound, ErrCommandNotFound)
+		if r.config.SuggestCommands && args.Len() > 0 {
+			ctx.pipeOut = nil
+			r.respondSuggestions(ctx, prefix, args.Get(0).String())
+		}
+		return false
+	}
+	if ctx.root, ok = r.GetCommand(args.Get(0).String()); !ok {
+		ctx.root = cmd
+	}
+	ctx.args = args.From(depth)
+	ctx.invoke = prefix + strings.Join(argsToStrings(args.Args()[:depth]), " ")
+
+	return r.dispatch(cmd, ctx)
+}
+
+// getSerializeKey returns the dispatcher key commands are ordered by.
+func (r *router) getSerializeKey(guildID, channelID string) string {
+	switch r.config.Serialize {
+	case SerializeGuild:
+		if guildID != "" {
+			return guildID
+		}
+		return channelID
+	case SerializeChannel:
+		return channelID
+	default:
+		return ""
+	}
+}
+
+// acquireContext returns a reset context from our pool.
+func (r *router) acquireContext(s *discordgo.Session) *context {
+	ctx, _ := r.ctxPool.Get().(*context)
+	ctx.router = r
+	ctx.session = s
+	ctx.ctx = nil
+	ctx.base = nil
+	ctx.isDM = false
+	ctx.isEdit = false
+	ctx.args = nil
+	ctx.params = nil
+	ctx.invoke = ""
+	ctx.root = nil
+	ctx.cmd = nil
+	ctx.event = nil
+	ctx.replies = nil
+	ctx.prevReplies = nil
+	ctx.guild = nil
+	ctx.channel = nil
+	ctx.message = nil
+	ctx.member = nil
+	ctx.interaction = nil
+	ctx.responded = false
+	ctx.locale = ""
+	ctx.pipeIn = nil
+	ctx.pipeOut = nil
+	return ctx
+}
+
+// releaseContext puts given context back to our pool.
+func (r *router) releaseContext(ctx *context) {
+	clearMap(ctx.objectMap)
+	r.ctxPool.Put(ctx)
+}
+
+// fillEnvironment sets channel, guild and DM state of given context.
+// Returns false when the command shall not be handled any further.
+func (r *router) fillEnvironment(ctx *context, channelID, guildID string) bool {
+	var err error
+	s := ctx.session
+
+	if ctx.channel, err = s.State.Channel(channelID); err != nil {
+		if ctx.channel, err = s.Channel(channelID); err != nil {
+			r.onError(ctx, ErrTypeGetChannel, err)
+			return false
+		}
+	}
 
 	ctx.isDM = ctx.channel.Type == discordgo.ChannelTypeDM || ctx.channel.Type == discordgo.ChannelTypeGroupDM
 	if !r.config.AllowDM && ctx.isDM {
-		return
+		return false
 	}
 
 	if !ctx.isDM {
-		if ctx.guild, err = s.State.Guild(msg.GuildID); err != nil {
-			if ctx.guild, err = s.Guild(msg.GuildID); err != nil {
-				r.config.OnError(ctx, ErrTypeGetGuild, err)
-				return
+		if ctx.guild, err = s.State.Guild(guildID); err != nil {
+			if ctx.guild, err = s.Guild(guildID); err != nil {
+				r.onError(ctx, ErrTypeGetGuild, err)
+				return false
 			}
 		}
 	}
+	return true
+}
 
-	args := ParseArguments(trimmed)
-	invoke, arg := args.Args()[0].String(), args.Args()[1:]
-	ctx.args = FromArguments(arg)
+// dispatch runs given command surrounded by our middlewares. This is shared between
+// message and interaction invocation. Returns true if everything executed successfully.
+func (r *router) dispatch(cmd Command, ctx *context) bool {
+	ctx.cmd = cmd
+	if ctx.isDM && !cmd.IsExecutableInDM() {
+		r.onError(ctx, ErrTypeNotExecutableInDM, ErrNotExecutableInDMs)
+		return false
+	}
 
-	cmd, ok := r.GetCommand(invoke)
-	if !ok {
-		r.config.OnError(ctx, ErrTypeCommandNotFound, ErrCommandNotFound)
-		return
+	if r.baseCtx.Err() != nil {
+		return false
+	}
+	if ctx.base != nil && ctx.base.Err() != nil {
+		// the invoking message was deleted while the command was queued.
+		r.onError(ctx, ErrTypeCommandCanceled, fmt.Errorf("%w: %s", ErrCommandCanceled, ctx.invoke))
+		return false
 	}
 
-	if ctx.isDM && !cmd.IsExecutableInDM() {
-		r.config.OnError(ctx, ErrTypeNotExecutableInDM, ErrNotExecutableInDMs)
-		return
+	if ctx.guild != nil && ctx.root != nil {
+		disabled, err := r.isDisabled(ctx.guild.ID, ctx.root, cmd)
+		if err != nil || disabled {
+			if err == nil {
+				err = ErrCommandDisabled
+			}
+			r.onError(ctx, ErrTypeCommandDisabled, err)
+			return false
+		}
+	}
+
+	if errType, err := r.checkCommand(cmd, ctx); err != nil {
+		ctx.pipeOut = nil
+		respondCheckFailed(ctx, err)
+		r.onError(ctx, errType, err)
+		return false
 	}
 
 	if ctx.GetObject(ObjectMapKeyRouter) != r {
 		ctx.SetObject(ObjectMapKeyRouter, r)
 	}
 
+	var cancel gocontext.CancelFunc
+	ctx.ctx, cancel = r.commandContext(ctx.base, cmd)
+	defer cancel()
+
+	// arguments are validated first, so invalid invocations don't pass rate limits or the like.
+	if sc, ok := cmd.(SchemaCommand); ok {
+		params, err := sc.GetSchema().BindArguments(ctx, ctx.args.Args())
+		if err != nil {
+			ctx.pipeOut = nil
+			_, _ = ctx.RespondEmbed(&discordgo.MessageEmbed{
+				Title:       ctx.T("rosetta.invalid_arguments.title"),
+				Description: fmt.Sprintf("%s\n\n**%s**\n%s", err.Error(), ctx.T("rosetta.invalid_arguments.usage"), sc.GetSchema().Usage(ctx.invoke)),
+				Color:       EmbedColorError,
+			})
+			r.onError(ctx, ErrTypeInvalidArguments, err)
+			return false
+		}
+		ctx.params = params
+	}
+
 	if !r.executeMiddlewares(cmd, ctx, LayerBeforeCommand) {
-		return
+		return false
 	}
 
-	if err = cmd.Exec(ctx); err != nil {
-		r.config.OnError(ctx, ErrTypeCommandExec, err)
-		return
+	err := r.getHandler()(cmd, ctx)
+	switch {
+	case errors.Is(err, ErrCommandPanic):
+		r.onError(ctx, ErrTypeCommandPanic, err)
+		return false
+	case errors.Is(ctx.ctx.Err(), gocontext.DeadlineExceeded):
+		r.onError(ctx, ErrTypeCommandTimeout, fmt.Errorf("%w: %s", ErrCommandTimeout, ctx.invoke))
+		return false
+	case errors.Is(ctx.ctx.Err(), gocontext.Canceled):
+		r.onError(ctx, ErrTypeCommandCanceled, fmt.Errorf("%w: %s", ErrCommandCanceled, ctx.invoke))
+		return false
+	case err != nil:
+		r.onError(ctx, ErrTypeCommandExec, err)
+		return false
 	}
 
-	if !r.executeMiddlewares(cmd, ctx, LayerAfterCommand) {
-		return
+	return r.executeMiddlewares(cmd, ctx, LayerAfterCommand)
+}
+
+// commandContext returns a context derived from given parent with the timeout of given command.
+// If parent is nil, our base context is used.
+func (r *router) commandContext(parent gocontext.Context, cmd Command) (gocontext.Context, gocontext.CancelFunc) {
+	if parent == nil {
+		parent = r.baseCtx
+	}
+	timeout := r.config.CommandTimeout
+	if tc, ok := cmd.(TimeoutCommand); ok {
+		timeout = tc.GetTimeout()
 	}
+	if timeout <= 0 {
+		return gocontext.WithCancel(parent)
+	}
+	return gocontext.WithTimeout(parent, timeout)
+}
 
-	if r.config.DeleteMessageAfter {
-		if err = s.ChannelMessageDelete(msg.ChannelID, msg.ID); err != nil {
-			r.config.OnError(ctx, ErrTypeDeleteCommandMessage, err)
-			return
+// trackMessage returns a context which is cancelled when the message with given ID is
+// deleted or our router shuts down, and a func to release it once its commands finished.
+func (r *router) trackMessage(msgID string) (gocontext.Context, func()) {
+	ctx, cancel := gocontext.WithCancel(r.baseCtx)
+	untrack := r.trackRunning(msgID, cancel)
+	return ctx, func() {
+		untrack()
+		cancel()
+	}
+}
+
+// trackRunning stores cancel under given message ID and returns a func to remove it again.
+// An edit re-triggering the same message replaces the entry, thus it's only removed by its owner.
+func (r *router) trackRunning(msgID string, cancel gocontext.CancelFunc) func() {
+	r.runningMu.Lock()
+	r.running[msgID] = &cancel
+	r.runningMu.Unlock()
+	return func() {
+		r.runningMu.Lock()
+		if r.running[msgID] == &cancel {
+			delete(r.running, msgID)
 		}
+		r.runningMu.Unlock()
 	}
 }
 
+// cancelRunning cancels the command invoked by given message ID, if any.
+func (r *router) cancelRunning(msgID string) {
+	r.runningMu.Lock()
+	cancel, ok := r.running[msgID]
+	r.runningMu.Unlock()
+	if ok {
+		(*cancel)()
+	}
+}
+
+func (r *router) GetDispatcherStats() DispatcherStats {
+	return r.dispatcher.stats()
+}
+
+func (r *router) Shutdown() {
+	r.cancelBase()
+}
+
 func (r *router) executeMiddlewares(cmd Command, ctx Context, layer MiddlewareLayer) bool {
 	for _, m := range r.middleware {
 		if m.GetLayer()&layer == 0 {
@@ -294,7 +926,7 @@ func (r *router) executeMiddlewares(cmd Command, ctx Context, layer MiddlewareLa
 
 		next, err := m.Handle(cmd, ctx, layer)
 		if err != nil {
-			r.config.OnError(ctx, ErrTypeMiddleware, err)
+			r.onError(ctx, ErrTypeMiddleware, err)
 			return false
 		}
 		if !next {
@@ -304,22 +936,86 @@ func (r *router) executeMiddlewares(cmd Command, ctx Context, layer MiddlewareLa
 	return true
 }
 
+func (r *router) CanExecute(cmd Command, ctx Context) (bool, error) {
+	if ctx.IsDM() && !cmd.IsExecutableInDM() {
+		return false, nil
+	}
+	if guild := ctx.GetGuild(); guild != nil && !ctx.IsDM() {
+		disabled, err := r.isDisabled(guild.ID, cmd, cmd)
+		if err != nil || disabled {
+			return false, err
+		}
+	}
+	if errType, err := r.checkCommand(cmd, ctx); err != nil {
+		// failed lookups are reported, failed checks just hide the command.
+		if errType == ErrTypeGetGuild {
+			return false, err
+		}
+		return false, nil
+	}
+	for _, m := range r.middleware {
+		if pc, ok := m.(PermissionChecker); ok {
+			if ok, err := pc.CanExecute(cmd, ctx); err != nil || !ok {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
 func (r *router) GetConfig() *Config {
 	return r.config
 }
 
 func (r *router) GetCommandMap() map[string]Command {
-	return r.cmdMap
+	r.cmdMu.RLock()
+	defer r.cmdMu.RUnlock()
+	cmdMap := make(map[string]Command, len(r.cmdMap))
+	for k, v := range r.cmdMap {
+		cmdMap[k] = v
+	}
+	return cmdMap
 }
 
 func (r *router) GetCommandInstances() []Command {
-	return r.cmdInstances
+	r.cmdMu.RLock()
+	defer r.cmdMu.RUnlock()
+	return append([]Command{}, r.cmdInstances...)
 }
 
 func (r *router) GetCommand(invoke string) (Command, bool) {
-	if r.config.IgnoreCase {
-		invoke = strings.ToLower(invoke)
-	}
-	cmd, ok := r.cmdMap[invoke]
+	r.cmdMu.RLock()
+	defer r.cmdMu.RUnlock()
+	cmd, ok := r.cmdMap[r.normalizeInvoke(invoke)]
 	return cmd, ok
 }
+
+func (r *router) GetSubCommand(parent Command, invoke string) (Command, bool) {
+	p, ok := parent.(ParentCommand)
+	if !ok {
+		return nil, false
+	}
+	for _, sub := range p.GetSubCommands() {
+		if arrayContains(sub.GetInvokers(), invoke, r.config.IgnoreCase) {
+			return sub, true
+		}
+	}
+	return nil, false
+}
+
+func (r *router) ResolveCommand(args []Argument) (cmd Command, depth int, ok bool) {
+	if len(args) == 0 {
+		return nil, 0, false
+	}
+	if cmd, ok = r.GetCommand(args[0].String()); !ok {
+		return nil, 0, false
+	}
+	for depth = 1; depth < len(args); depth++ {
+		sub, found := r.GetSubCommand(cmd, args[depth].String())
+		if !found {
+			break
+		}
+		cmd = sub
+	}
+	return cmd, depth, true
+}
@@ -3,7 +3,11 @@ package rosetta
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Iridaceae/iridaceae/pkg"
 
@@ -31,6 +35,8 @@ func TestRouter_Setup(t *testing.T) {
 func TestNewDefaultConfig(t *testing.T) {
 	ctx := makeTestCtx(false, true)
 	c := NewDefaultConfig()
+	// application commands are only overwritten if enabled explicitly.
+	assert.False(t, c.UseSlashCommands)
 	c.OnError(ctx, ErrTypeMiddleware, ErrMiddleware)
 }
 
@@ -325,3 +331,343 @@ func (t *TestCmd) Exec(_ Context) error {
 	}
 	return nil
 }
+
+func TestRouter_ResolveCommand(t *testing.T) {
+	r := NewRouter(makeTestConfig())
+	set := &TestSubCmd{invokers: []string{"set", "s"}}
+	prefix := &TestSubCmd{invokers: []string{"prefix"}, subs: []Command{set}}
+	config := &TestSubCmd{invokers: []string{"config", "cfg"}, subs: []Command{prefix}}
+	r.Register(config)
+
+	tests := []struct {
+		name     string
+		args     []Argument
+		expected Command
+		depth    int
+		ok       bool
+	}{
+		{"empty arguments", nil, nil, 0, false},
+		{"unknown root", []Argument{"abc"}, nil, 0, false},
+		{"root only", []Argument{"config"}, config, 1, true},
+		{"root with arguments", []Argument{"cfg", "abc"}, config, 1, true},
+		{"leaf", []Argument{"config", "prefix", "set", "r!!"}, set, 3, true},
+		{"leaf with alias and case", []Argument{"CFG", "Prefix", "S"}, set, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, depth, ok := r.ResolveCommand(tt.args)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.depth, depth)
+			assert.Equal(t, tt.expected, cmd)
+		})
+	}
+	t.Run("sub command of non parent", func(t *testing.T) {
+		_, ok := r.GetSubCommand(&TestCmd{}, "set")
+		assert.False(t, ok)
+	})
+	t.Run("panic duplicated sub command", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("register duplicated sub command invokers didn't panic")
+			}
+		}()
+		NewRouter(makeTestConfig()).Register(&TestSubCmd{
+			invokers: []string{"config"},
+			subs:     []Command{&TestSubCmd{invokers: []string{"a"}}, &TestSubCmd{invokers: []string{"b", "A"}}},
+		})
+	})
+}
+
+func TestGetSubCommandTree(t *testing.T) {
+	set := &TestSubCmd{invokers: []string{"set"}}
+	config := &TestSubCmd{invokers: []string{"config"}, subs: []Command{&TestSubCmd{invokers: []string{"prefix"}, subs: []Command{set}}}}
+	r := NewRouter(makeTestConfig())
+	ctx := makeTestCtx(false, false)
+	ctx.isDM = false
+
+	tree, err := getSubCommandTree(r, ctx, config, "config", 1)
+	assert.NoError(t, err)
+	assert.Contains(t, tree, "`config prefix`")
+	assert.Contains(t, tree, "`config prefix set`")
+	tree, err = getSubCommandTree(r, ctx, set, "set", 1)
+	assert.NoError(t, err)
+	assert.Empty(t, tree)
+}
+
+type TestSubCmd struct {
+	TestCmd
+	invokers []string
+	subs     []Command
+}
+
+func (t *TestSubCmd) GetInvokers() []string {
+	return t.invokers
+}
+
+func (t *TestSubCmd) GetDomain() string {
+	return "test.etc." + t.invokers[0]
+}
+
+func (t *TestSubCmd) GetSubCommands() []Command {
+	return t.subs
+}
+
+type TestWaitCmd struct {
+	TestCmd
+	timeout time.Duration
+	started chan struct{}
+}
+
+func (t *TestWaitCmd) GetTimeout() time.Duration {
+	return t.timeout
+}
+
+func (t *TestWaitCmd) Exec(ctx Context) error {
+	t.executed = true
+	close(t.started)
+	<-ctx.GetContext().Done()
+	return ctx.GetContext().Err()
+}
+
+func TestRouter_CommandContext(t *testing.T) {
+	makeRouter := func() (*router, chan ErrorType) {
+		errTypes := make(chan ErrorType, 1)
+		cfg := makeTestConfig()
+		cfg.OnError = func(_ Context, errType ErrorType, _ error) { errTypes <- errType }
+		r, _ := NewRouter(cfg).(*router)
+		return r, errTypes
+	}
+	makeCtx := func(r *router) *context {
+		ctx := r.acquireContext(nil)
+		ctx.message = &discordgo.Message{ID: "rosetta_testMessage"}
+		ctx.args = ParseArguments("")
+		return ctx
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		r, errTypes := makeRouter()
+		cmd := &TestWaitCmd{timeout: 10 * time.Millisecond, started: make(chan struct{})}
+		assert.False(t, r.dispatch(cmd, makeCtx(r)))
+		assert.Equal(t, ErrTypeCommandTimeout, <-errTypes)
+	})
+
+	t.Run("cancel on message delete", func(t *testing.T) {
+		r, errTypes := makeRouter()
+		cmd := &TestWaitCmd{started: make(chan struct{})}
+		ctx := makeCtx(r)
+		base, release := r.trackMessage("rosetta_testMessage")
+		ctx.base = base
+		done := make(chan bool)
+		go func() {
+			defer release()
+			done <- r.dispatch(cmd, ctx)
+		}()
+		<-cmd.started
+		r.cancelRunning("rosetta_testMessage")
+		assert.False(t, <-done)
+		assert.Equal(t, ErrTypeCommandCanceled, <-errTypes)
+
+		r.runningMu.Lock()
+		defer r.runningMu.Unlock()
+		assert.Empty(t, r.running)
+	})
+
+	t.Run("cancel while queued", func(t *testing.T) {
+		r, errTypes := makeRouter()
+		cmd := &TestWaitCmd{started: make(chan struct{})}
+		ctx := makeCtx(r)
+		base, release := r.trackMessage("rosetta_testMessage")
+		defer release()
+		ctx.base = base
+
+		// the message is deleted before a worker picked up its command.
+		r.cancelRunning("rosetta_testMessage")
+		assert.False(t, r.dispatch(cmd, ctx))
+		assert.False(t, cmd.executed)
+		assert.Equal(t, ErrTypeCommandCanceled, <-errTypes)
+	})
+
+	t.Run("cancel on shutdown", func(t *testing.T) {
+		r, errTypes := makeRouter()
+		cmd := &TestWaitCmd{started: make(chan struct{})}
+		go r.dispatch(cmd, makeCtx(r))
+		<-cmd.started
+		r.Shutdown()
+		assert.Equal(t, ErrTypeCommandCanceled, <-errTypes)
+
+		cmd = &TestWaitCmd{started: make(chan struct{})}
+		assert.False(t, r.dispatch(cmd, makeCtx(r)))
+		assert.False(t, cmd.executed)
+	})
+}
+
+func TestRouter_UnregisterAndReplace(t *testing.T) {
+	r := NewRouter(makeTestConfig())
+	ping := &TestCmd{}
+	r.Register(ping)
+
+	cmd, ok := r.UnregisterCommand("P")
+	assert.True(t, ok)
+	assert.Equal(t, ping, cmd)
+	assert.Empty(t, r.GetCommandMap())
+	assert.Empty(t, r.GetCommandInstances())
+	_, ok = r.UnregisterCommand("ping")
+	assert.False(t, ok)
+
+	pong := &TestSubCmd{invokers: []string{"pong", "p"}}
+	r.Register(ping)
+	r.Register(&TestSubCmd{invokers: []string{"pom"}})
+	assert.Equal(t, []Command{ping}, r.ReplaceCommand(pong))
+
+	cmd, _ = r.GetCommand("p")
+	assert.Equal(t, pong, cmd)
+	_, ok = r.GetCommand("ping")
+	assert.False(t, ok)
+	assert.Len(t, r.GetCommandInstances(), 2)
+}
+
+func TestRouter_ConcurrentRegistry(t *testing.T) {
+	r := NewRouter(makeTestConfig())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			r.ReplaceCommand(&TestCmd{})
+			r.UnregisterCommand("ping")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		r.GetCommand("ping")
+		_ = r.GetCommandInstances()
+		_ = r.GetSuggestions("pin", nil)
+	}
+	<-done
+}
+
+type TestPanicCmd struct {
+	TestCmd
+}
+
+func (t *TestPanicCmd) Exec(_ Context) error {
+	panic("test panic")
+}
+
+func TestRouter_MiddlewareFunc(t *testing.T) {
+	var (
+		errType ErrorType = -1
+		errRes  error
+		order   []string
+	)
+	cfg := makeTestConfig()
+	cfg.UseRecovery = true
+	cfg.OnError = func(_ Context, t ErrorType, err error) { errType, errRes = t, err }
+	r, _ := NewRouter(cfg).(*router)
+
+	trace := func(name string) func(next HandlerFunc) HandlerFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(cmd Command, ctx Context) error {
+				order = append(order, name+" before")
+				err := next(cmd, ctx)
+				order = append(order, name+" after")
+				return err
+			}
+		}
+	}
+	r.Register(trace("a"))
+	r.Register(MiddlewareFunc(trace("b")))
+
+	makeCtx := func() *context {
+		ctx := r.acquireContext(nil)
+		ctx.args = ParseArguments("")
+		return ctx
+	}
+
+	cmd := &TestCmd{}
+	assert.True(t, r.dispatch(cmd, makeCtx()))
+	assert.True(t, cmd.executed)
+	assert.Equal(t, []string{"a before", "b before", "b after", "a after"}, order)
+
+	assert.False(t, r.dispatch(&TestPanicCmd{}, makeCtx()))
+	assert.Equal(t, ErrTypeCommandPanic, errType)
+	var panicErr *PanicError
+	if assert.True(t, errors.As(errRes, &panicErr)) {
+		assert.Equal(t, "test panic", panicErr.Value)
+		assert.Contains(t, string(panicErr.Stack), "TestPanicCmd")
+	}
+}
+
+type TestSchemaCmd struct {
+	TestCmd
+	schema *Schema
+}
+
+func (t *TestSchemaCmd) GetSchema() *Schema {
+	return t.schema
+}
+
+func TestRouter_BindArguments(t *testing.T) {
+	var errType ErrorType = -1
+	cfg := makeTestConfig()
+	cfg.OnError = func(_ Context, t ErrorType, _ error) { errType = t }
+	r, _ := NewRouter(cfg).(*router)
+	mw := &TestMiddleware{layer: LayerBeforeCommand}
+	r.Register(mw)
+
+	s, _ := discordgo.New()
+	s.Client = &http.Client{Transport: TestTransport(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"id": "1"}`)), Header: http.Header{}}
+	})}
+	makeCtx := func(args string) *context {
+		ctx := r.acquireContext(s)
+		ctx.channel = &discordgo.Channel{ID: "1"}
+		ctx.args = ParseArguments(args)
+		return ctx
+	}
+
+	// invalid arguments are rejected before middlewares, e.g. rate limits, are passed.
+	cmd := &TestSchemaCmd{schema: NewSchema().Int("amount", "")}
+	assert.False(t, r.dispatch(cmd, makeCtx("three")))
+	assert.Equal(t, ErrTypeInvalidArguments, errType)
+	assert.False(t, mw.executed)
+	assert.False(t, cmd.executed)
+
+	assert.True(t, r.dispatch(cmd, makeCtx("3")))
+	assert.True(t, mw.executed)
+	assert.True(t, cmd.executed)
+}
+
+type TestPanicMiddleware struct{}
+
+func (t *TestPanicMiddleware) Handle(_ Command, _ Context, _ MiddlewareLayer) (bool, error) {
+	panic("test middleware panic")
+}
+
+func (t *TestPanicMiddleware) GetLayer() MiddlewareLayer {
+	return LayerBeforeCommand
+}
+
+func TestRouter_RecoverWorker(t *testing.T) {
+	errs := make(chan error, 1)
+	cfg := makeTestConfig()
+	cfg.Workers = 1
+	cfg.OnError = func(_ Context, t ErrorType, err error) {
+		if t == ErrTypeCommandPanic {
+			errs <- err
+		}
+	}
+	r, _ := NewRouter(cfg).(*router)
+	defer r.Shutdown()
+	r.Register(&TestCmd{})
+	r.Register(&TestPanicMiddleware{})
+
+	// a panic outside of the command is reported instead of killing the worker.
+	s := makeTestStateSession(t)
+	msg := &discordgo.Message{ID: "100", ChannelID: "10", GuildID: "1", Author: &discordgo.User{ID: "2"}, Content: "!ping"}
+	r.trigger(s, msg)
+	var panicErr *PanicError
+	if assert.True(t, errors.As(<-errs, &panicErr)) {
+		assert.Equal(t, "test middleware panic", panicErr.Value)
+	}
+	r.trigger(s, msg)
+	assert.Error(t, <-errs)
+}
@@ -0,0 +1,129 @@
+package rosetta
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxSuggestions defines how many commands are suggested at most.
+const maxSuggestions = 3
+
+// GetSuggestions returns invokers of registered commands similar to given invoke, closest first.
+// A command is similar when its invoker is within Config.SuggestionMaxDistance edits or
+// starts with given invoke. Each command is only suggested once. If ctx is not nil, only
+// commands listed by help for its user are suggested.
+func (r *router) GetSuggestions(invoke string, ctx Context) []string {
+	invoke = strings.ToLower(invoke)
+	if invoke == "" {
+		return nil
+	}
+
+	type candidate struct {
+		invoke string
+		cmd    Command
+		dist   int
+	}
+	candidates := make([]candidate, 0)
+	for i, cmd := range r.GetCommandMap() {
+		i = strings.ToLower(i)
+		dist := levenshtein(invoke, i)
+		switch {
+		case len(invoke) > 1 && strings.HasPrefix(i, invoke):
+			// prefix matches are ranked before typos of the same distance.
+			dist = 0
+		case dist > r.config.SuggestionMaxDistance || dist >= len(invoke):
+			continue
+		}
+		candidates = append(candidates, candidate{i, cmd, dist})
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].dist != candidates[b].dist {
+			return candidates[a].dist < candidates[b].dist
+		}
+		return candidates[a].invoke < candidates[b].invoke
+	})
+
+	res := make([]string, 0, maxSuggestions)
+	seen := make(map[Command]struct{})
+	for _, c := range candidates {
+		if _, ok := seen[c.cmd]; ok {
+			continue
+		}
+		seen[c.cmd] = struct{}{}
+		if ctx != nil {
+			if ok, err := isListed(r, ctx, c.cmd); err != nil || !ok {
+				continue
+			}
+		}
+		res = append(res, c.invoke)
+		if len(res) == maxSuggestions {
+			break
+		}
+	}
+	return res
+}
+
+// respondSuggestions replies to an unknown command with the closest registered commands.
+func (r *router) respondSuggestions(ctx Context, prefix, invoke string) {
+	if r.config.IgnorePrefixCollisions && !isLetter(invoke) {
+		return
+	}
+
+	suggestions := r.GetSuggestions(invoke, ctx)
+	if len(suggestions) == 0 && r.config.IgnorePrefixCollisions {
+		return
+	}
+
+	desc := ctx.T("rosetta.unknown_command.help", prefix)
+	if len(suggestions) > 0 {
+		desc = ctx.T("rosetta.unknown_command.suggestion", prefix+strings.Join(suggestions, "`, `"+prefix)) + "\n\n" + desc
+	}
+	_, _ = ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title:       ctx.T("rosetta.unknown_command.title", invoke),
+		Description: desc,
+		Color:       EmbedColorError,
+	})
+}
+
+// isLetter returns true if given string starts with a letter. Invokers like `!!` or `?!`
+// are most likely not meant for us.
+func isLetter(s string) bool {
+	c, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLetter(c)
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,63 @@
+package rosetta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"help", "help", 0},
+		{"hlep", "help", 2},
+		{"hepl", "help", 2},
+		{"pin", "ping", 1},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"-"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, levenshtein(tt.a, tt.b))
+			assert.Equal(t, tt.expected, levenshtein(tt.b, tt.a))
+		})
+	}
+}
+
+func TestRouter_GetSuggestions(t *testing.T) {
+	cfg := makeTestConfig()
+	cfg.UseDefaultHelpCommand = true
+	cfg.SuggestionMaxDistance = 2
+	r := NewRouter(cfg)
+	r.Register(&TestCmd{})
+	r.Register(&TestSubCmd{invokers: []string{"pomodoro", "pom"}})
+
+	tests := []struct {
+		name     string
+		invoke   string
+		expected []string
+	}{
+		{"typo", "hlep", []string{"help"}},
+		{"one suggestion per command", "pin", []string{"ping", "man", "pom"}},
+		{"prefix of invoker", "pomo", []string{"pomodoro"}},
+		{"closest first", "PONG", []string{"ping", "pom"}},
+		{"nothing close", "abcdefgh", []string{}},
+		{"too short", "x", []string{}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, r.GetSuggestions(tt.invoke, nil))
+		})
+	}
+}
+
+func TestIsLetter(t *testing.T) {
+	assert.True(t, isLetter("help"))
+	assert.False(t, isLetter("!!"))
+	assert.False(t, isLetter("1"))
+	assert.False(t, isLetter(""))
+}
@@ -2,18 +2,35 @@ package rosetta
 
 import (
 	"log"
+	"strconv"
 	"strings"
 	"sync"
+	"unicode/utf8"
 )
 
 func hasPrefix(msg string, prefix string, ignoreCase bool) (string, bool) {
-	strToCheck := msg
-	if ignoreCase {
-		strToCheck = strings.ToLower(strToCheck)
-		prefix = strings.ToLower(prefix)
+	if !ignoreCase {
+		if strings.HasPrefix(msg, prefix) {
+			return msg[len(prefix):], true
+		}
+		return msg, false
+	}
+
+	// case folding can change the byte length of a rune, thus as many runes as prefix
+	// has are compared instead of bytes.
+	end, runes := len(msg), utf8.RuneCountInString(prefix)
+	for i := range msg {
+		if runes == 0 {
+			end = i
+			break
+		}
+		runes--
+	}
+	if runes > 0 {
+		return msg, false
 	}
-	if strings.HasPrefix(strToCheck, prefix) {
-		return msg[len(prefix):], true
+	if strings.EqualFold(msg[:end], prefix) {
+		return msg[end:], true
 	}
 	return msg, false
 }
@@ -47,25 +64,26 @@ func clearMap(m *sync.Map) {
 	})
 }
 
-// TODO: better way to handle error.
+// String returns a readable name of given error type.
+func (e ErrorType) String() string {
+	if name := getErrorTypeName(e); name != "" {
+		return name
+	}
+	return "error type " + strconv.Itoa(int(e))
+}
+
+// getErrorTypeName returns the message of the error represented by given type, empty if unknown.
 func getErrorTypeName(e ErrorType) string {
-	switch e {
-	case ErrTypeCommandExec:
-		return ErrCommandExec.Error()
-	case ErrTypeMiddleware:
-		return ErrMiddleware.Error()
-	case ErrTypeCommandNotFound:
-		return ErrCommandNotFound.Error()
-	case ErrTypeGetChannel:
-		return ErrGetChannel.Error()
-	case ErrTypeDeleteCommandMessage:
-		return ErrDeleteCommandMessage.Error()
-	case ErrTypeGetGuild:
-		return ErrGetGuild.Error()
-	case ErrTypeGuildPrefixGetter:
-		return ErrGuildPrefixGetter.Error()
-	case ErrTypeNotExecutableInDM:
-		return ErrNotExecutableInDMs.Error()
+	if err := e.Err(); err != nil {
+		return err.Error()
 	}
 	return ""
 }
+
+func argsToStrings(args []Argument) []string {
+	s := make([]string, len(args))
+	for i, a := range args {
+		s[i] = a.String()
+	}
+	return s
+}
@@ -31,6 +31,18 @@ func TestHasPrefix(t *testing.T) {
 			tt.prefixFunc(tt.msg, tt.prefix, true, tt.expected)
 		})
 	}
+
+	// runes changing their length when folded are matched and cut as a whole.
+	rest, ok := hasPrefix("ȺȺping", "ⱥⱥ", true)
+	assert.True(t, ok)
+	assert.Equal(t, "ping", rest)
+	rest, ok = hasPrefix("ⱥⱥping", "ȺȺ", true)
+	assert.True(t, ok)
+	assert.Equal(t, "ping", rest)
+	_, ok = hasPrefix("Ⱥ", "ⱥⱥ", true)
+	assert.False(t, ok)
+	_, ok = hasPrefix("ȺȺping", "ⱥⱥ", false)
+	assert.False(t, ok)
 }
 
 func TestTrimPreSuffix(t *testing.T) {
@@ -75,10 +87,22 @@ func TestGetErrorType(t *testing.T) {
 		{"error middleware", ErrMiddleware, getErrorTypeName(5)},
 		{"error command exec", ErrCommandExec, getErrorTypeName(6)},
 		{"error delete command message", ErrDeleteCommandMessage, getErrorTypeName(7)},
+		{"error register slash commands", ErrRegisterSlashCommands, getErrorTypeName(8)},
+		{"error interaction respond", ErrInteractionRespond, getErrorTypeName(9)},
+		{"error invalid arguments", ErrInvalidArgument, getErrorTypeName(10)},
+		{"error command timeout", ErrCommandTimeout, getErrorTypeName(11)},
+		{"error command canceled", ErrCommandCanceled, getErrorTypeName(12)},
+		{"error queue full", ErrQueueFull, getErrorTypeName(13)},
+		{"error command disabled", ErrCommandDisabled, getErrorTypeName(14)},
+		{"error command panic", ErrCommandPanic, getErrorTypeName(15)},
+		{"error event handler", ErrEventHandler, getErrorTypeName(16)},
+		{"error get guild command", ErrGetGuildCommand, getErrorTypeName(17)},
 	}
 	for i, tt := range tests {
 		t.Run(fmt.Sprintf("%s-%d", tt.name, i), func(t *testing.T) {
 			assert.Equal(t, tt.e.Error(), tt.output)
 		})
 	}
+	assert.Equal(t, ErrCommandExec.Error(), ErrTypeCommandExec.String())
+	assert.Equal(t, "error type 99", ErrorType(99).String())
 }
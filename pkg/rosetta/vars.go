@@ -20,6 +20,22 @@ const (
 	ErrTypeMiddleware
 	ErrTypeCommandExec
 	ErrTypeDeleteCommandMessage
+	ErrTypeRegisterSlashCommands
+	ErrTypeInteractionRespond
+	ErrTypeInvalidArguments
+	ErrTypeCommandTimeout
+	ErrTypeCommandCanceled
+	ErrTypeQueueFull
+	ErrTypeCommandDisabled
+	ErrTypeCommandPanic
+	ErrTypeEventHandler
+	ErrTypeGetGuildCommand
+	ErrTypeGetLocale
+	ErrTypeGuildOnly
+	ErrTypeOwnerOnly
+	ErrTypeNSFWOnly
+	ErrTypeMissingPermissions
+	ErrTypeBotMissingPermissions
 )
 
 var (
@@ -53,6 +69,69 @@ var (
 	// ErrDeleteCommandMessage is thrown when error occurred when deleting message.
 	ErrDeleteCommandMessage = errors.New("failed while deleting command message")
 
+	// ErrRegisterSlashCommands is thrown when application commands couldn't be registered.
+	ErrRegisterSlashCommands = errors.New("failed while registering slash commands")
+
+	// ErrInteractionRespond is thrown when responding to an interaction failed.
+	ErrInteractionRespond = errors.New("failed while responding to interaction")
+
+	// ErrMissingArgument is thrown when a required parameter has no argument.
+	ErrMissingArgument = errors.New("missing argument")
+
+	// ErrInvalidArgument is thrown when an argument can't be parsed into its parameter type.
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrInvalidSchema is thrown when parameters of a Schema can't be bound in their order.
+	ErrInvalidSchema = errors.New("invalid parameter schema")
+
+	// ErrTooManyArguments is thrown when more arguments are passed than parameters are declared.
+	ErrTooManyArguments = errors.New("too many arguments")
+
+	// ErrCommandTimeout is thrown when command exceeded its timeout.
+	ErrCommandTimeout = errors.New("command timed out")
+
+	// ErrCommandCanceled is thrown when command was cancelled by deleting its message or shutting down.
+	ErrCommandCanceled = errors.New("command was cancelled")
+
+	// ErrQueueFull is thrown when the dispatcher queue is full and the command was dropped.
+	ErrQueueFull = errors.New("command queue is full")
+
+	// ErrRouterShutdown is thrown when a command is received after the router was shut down.
+	ErrRouterShutdown = errors.New("router is shut down")
+
+	// ErrCommandDisabled is thrown when command or its group is disabled in the guild.
+	ErrCommandDisabled = errors.New("command is disabled in this guild")
+
+	// ErrCommandPanic is thrown when command panicked during execution.
+	ErrCommandPanic = errors.New("command panicked")
+
+	// ErrEventHandler is thrown when an EventHandler failed.
+	ErrEventHandler = errors.New("event handler failed")
+
+	// ErrGetGuildCommand is thrown when a GuildCommandSource failed.
+	ErrGetGuildCommand = errors.New("error while getting guild command")
+
+	// ErrGetLocale is thrown when a LocaleProvider failed.
+	ErrGetLocale = errors.New("error while getting locale")
+
+	// ErrGuildOnly is thrown when a GuildOnlyCommand is executed outside of a guild.
+	ErrGuildOnly = errors.New("command is only executable in guilds")
+
+	// ErrOwnerOnly is thrown when an OwnerOnlyCommand is executed by someone else than a bot owner.
+	ErrOwnerOnly = errors.New("command is only executable by bot owners")
+
+	// ErrNSFWOnly is thrown when a NSFWCommand is executed outside of a NSFW channel.
+	ErrNSFWOnly = errors.New("command is only executable in NSFW channels")
+
+	// ErrMissingPermissions is wrapped by MissingPermissionsError when the invoking member lacks permissions.
+	ErrMissingPermissions = errors.New("member is missing permissions")
+
+	// ErrBotMissingPermissions is wrapped by MissingPermissionsError when the bot lacks permissions.
+	ErrBotMissingPermissions = errors.New("bot is missing permissions")
+
+	// ErrLimitExceeded is wrapped by LimitError when a message exceeds a limit of Discord.
+	ErrLimitExceeded = errors.New("message exceeds discord limits")
+
 	EmbedColorDefault = 0x6A5ACD
 	EmbedColorError   = 0xE53935
 )